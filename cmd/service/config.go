@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// config - параметры сервиса из переменных окружения
+type config struct {
+	// Строка подключения к БД, собирается из пароля DB_pass
+	connString string
+	// Адрес HTTP-сервера (HTTP_ADDR), пустая строка - сервер не запускается
+	httpAddr string
+	// Адрес gRPC-сервера (GRPC_ADDR), пустая строка - сервер не запускается
+	grpcAddr string
+	// Порог медленного запроса (SLOW_QUERY_MS), 0 - значение по умолчанию хранилища
+	slowQueryThreshold time.Duration
+	// Время на корректную остановку (SHUTDOWN_TIMEOUT_S), 0 - значение по умолчанию
+	shutdownTimeout time.Duration
+	// Ключ подписи токенов (AUTH_KEY в base64), пустой - генерируется при запуске
+	authKey []byte
+	// Срок действия токена входа (SESSION_TTL_S), 0 - значение по умолчанию
+	sessionTTL time.Duration
+	// Построчная защита таблиц задач и меток по проектам (ROW_LEVEL_SECURITY)
+	rowLevelSecurity bool
+	// Интервал проверки повторяющихся задач (SCHEDULER_INTERVAL_S), 0 - значение по умолчанию
+	schedulerInterval time.Duration
+	// Часовой пояс для вывода времени (TIME_ZONE, например Europe/Moscow), по умолчанию - местный
+	location *time.Location
+	// Каталог содержимого вложений (ATTACHMENTS_DIR), пустая строка - содержимое хранится в БД
+	attachmentsDir string
+	// Наибольший размер вложения (MAX_ATTACHMENT_MB), 0 - значение по умолчанию хранилища
+	maxAttachmentSize int64
+}
+
+// configFromEnv читает параметры сервиса из переменных окружения
+func configFromEnv() (config, error) {
+	var cfg config
+
+	// Получаем пароль из переменной окружения
+	pwd := os.Getenv("DB_pass")
+	if pwd == "" {
+		return cfg, errors.New("Переменная окружения DB_pass не задана")
+	}
+	// Строка подключения
+	cfg.connString = fmt.Sprintf("postgres://postgres:%s@localhost:5432/tasks", pwd)
+	cfg.httpAddr = os.Getenv("HTTP_ADDR")
+	cfg.grpcAddr = os.Getenv("GRPC_ADDR")
+	cfg.attachmentsDir = os.Getenv("ATTACHMENTS_DIR")
+
+	var err error
+	if cfg.slowQueryThreshold, err = envDuration("SLOW_QUERY_MS", time.Millisecond); err != nil {
+		return cfg, err
+	}
+	if cfg.shutdownTimeout, err = envDuration("SHUTDOWN_TIMEOUT_S", time.Second); err != nil {
+		return cfg, err
+	}
+	if cfg.sessionTTL, err = envDuration("SESSION_TTL_S", time.Second); err != nil {
+		return cfg, err
+	}
+	if cfg.schedulerInterval, err = envDuration("SCHEDULER_INTERVAL_S", time.Second); err != nil {
+		return cfg, err
+	}
+	if v := os.Getenv("MAX_ATTACHMENT_MB"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 || n > 1<<20 {
+			return cfg, fmt.Errorf("Некорректное значение MAX_ATTACHMENT_MB: %q", v)
+		}
+		cfg.maxAttachmentSize = n << 20
+	}
+	if v := os.Getenv("AUTH_KEY"); v != "" {
+		if cfg.authKey, err = base64.StdEncoding.DecodeString(v); err != nil {
+			return cfg, fmt.Errorf("Некорректное значение AUTH_KEY: %w", err)
+		}
+	}
+	cfg.location = time.Local
+	if v := os.Getenv("TIME_ZONE"); v != "" {
+		if cfg.location, err = time.LoadLocation(v); err != nil {
+			return cfg, fmt.Errorf("Некорректное значение TIME_ZONE: %w", err)
+		}
+	}
+	if v := os.Getenv("ROW_LEVEL_SECURITY"); v != "" {
+		if cfg.rowLevelSecurity, err = strconv.ParseBool(v); err != nil {
+			return cfg, fmt.Errorf("Некорректное значение ROW_LEVEL_SECURITY: %q", v)
+		}
+	}
+	return cfg, nil
+}
+
+// envDuration читает целое число из переменной окружения name в единицах unit
+// Если переменная не задана, то возвращает 0
+func envDuration(name string, unit time.Duration) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("Некорректное значение %s: %q", name, v)
+	}
+	return time.Duration(n) * unit, nil
+}
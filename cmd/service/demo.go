@@ -0,0 +1,773 @@
+package main
+
+import (
+	"DB_Apps/pkg/access"
+	"DB_Apps/pkg/auth"
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/names"
+	"DB_Apps/pkg/reports"
+	"DB_Apps/pkg/scheduler"
+	"DB_Apps/pkg/storage"
+	"DB_Apps/pkg/storage/postgresql"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Хранилище и сервис аутентификации, с которыми работает демонстрация
+var db storage.Interface
+var authService *auth.Service
+
+// Часовой пояс, в котором выводится время задач
+var location *time.Location
+
+// runDemo заполняет таблицы и демонстрирует операции хранилища store
+// Все операции попадают в одну трассировку, отмена ctx прерывает выполняемый запрос
+func runDemo(ctx context.Context, tp trace.TracerProvider, store storage.Interface, a *auth.Service, loc *time.Location) error {
+	ctx, span := tp.Tracer(serviceName).Start(ctx, "demo")
+	defer span.End()
+	db = store.WithContext(ctx)
+	authService = a
+	location = loc
+
+	steps := []func() error{
+		fillUsers,  // Заполнение таблицы Users
+		fillLabels, // Заполнение таблицы Labels
+		workWithTasks,
+		workWithTx,
+		func() error { return workWithAuth(ctx) },
+		workWithAccess,
+		workWithProjects,
+		workWithTemplates,
+		workWithViews,
+		workWithBoards,
+		workWithWorklogs,
+		workWithAttachments,
+		func() error { return workWithNotifications(ctx) },
+		func() error { return workWithRecurring(ctx) },
+		deleteUserWithTasks,
+		showReports,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fillUsers() error {
+	users := []model.User{
+		{Name: "Иван Иванов", Login: "ivanov", Email: "ivanov@example.com"},
+		{Name: "Мария Петрова", Login: "petrova", Email: "Petrova@Example.com", DisplayName: "Маша", Role: model.RoleViewer},
+		{Name: "Алексей   сидОРов "}, // Проверка форматирования имени: Полсе форматирования в БД должно быть Алексей Сидоров
+		{Name: ""},         // Ошибка: Пустая строка не проходит
+		{Name: "John Doe"}, // Ошибка: В имени допускается только Кириллица
+		{Name: "кузьма петров-водкин"}, // Двойная фамилия: в БД должно быть Кузьма Петров-Водкин
+		{Name: "Пётр Ёлкин"},           // Буквы ё/Ё допускаются
+	}
+
+	for _, u := range users {
+		id, err := db.NewUser(u)
+		if err != nil {
+			switch {
+			case names.IsInvalid(err), errors.Is(err, postgresql.DuplicateLoginErr), errors.Is(err, postgresql.DuplicateEmailErr):
+				logger.Warn("Пользователь не добавлен", slog.String("name", u.Name), slog.Any("error", err))
+				continue
+			default:
+				return fmt.Errorf("Ошибка при добавлении пользователя %s: %w", u.Name, err)
+			}
+		}
+
+		logger.Info("Добавлен пользователь", slog.String("name", u.Name), slog.Int("id", id))
+	}
+
+	// Поиск пользователя по логину и email (без учета регистра)
+	u, err := db.SelectUserByLogin("Petrova")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя по логину: %w", err)
+	}
+	logger.Info("Найден пользователь по логину", slog.Int("id", u.ID), slog.String("login", u.Login),
+		slog.String("email", u.Email), slog.String("display_name", u.DisplayName))
+
+	u, err = db.SelectUserByEmail("IVANOV@example.com")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя по email: %w", err)
+	}
+	logger.Info("Найден пользователь по email", slog.Int("id", u.ID), slog.String("name", u.Name))
+	return nil
+}
+
+func fillLabels() error {
+	labels := []model.Label{
+		{Name: "Ошибка"},
+		{Name: "Новая"},
+		{Name: "Срочно"},
+		{Name: "Переделать"},
+		{Name: "Идея"},
+	}
+
+	for _, l := range labels {
+		id, err := db.NewLabel(l)
+		if err != nil {
+			if errors.Is(err, postgresql.LabelNameErr) {
+				logger.Warn("Метка не добавлена", slog.String("name", l.Name), slog.Any("error", err))
+				continue
+			}
+			return fmt.Errorf("Ошибка при добавлении метки %s: %w", l.Name, err)
+		}
+		logger.Info("Добавлена метка", slog.String("name", l.Name), slog.Int("id", id))
+	}
+	return nil
+}
+
+// createTask создает задачу и записывает результат в журнал
+// Ошибки из-за отсутствующих меток не прерывают демонстрацию
+func createTask(t model.Task) error {
+	id, err := db.NewTask(t)
+	if err != nil {
+		var partialErr myerrors.TaskPartialErr
+		if errors.As(err, &partialErr) {
+			logger.Warn("Задача не создана", slog.String("title", t.Title), slog.Any("error", err))
+			return nil
+		}
+		return fmt.Errorf("Ошибка при создании задачи: %w", err)
+	}
+	logger.Info("Создана задача", slog.Int("id", id), slog.String("title", t.Title))
+	return nil
+}
+
+// logTasks записывает в журнал список задач с заголовком title
+func logTasks(title string, tasks []model.Task) {
+	logger.Info(title, slog.Int("count", len(tasks)))
+	for _, task := range tasks {
+		logTask(task)
+	}
+}
+
+func logTask(task model.Task) {
+	logger.Info("Задача",
+		slog.Int("id", task.ID),
+		slog.String("title", task.Title),
+		slog.Int("author_id", task.AuthorID),
+		slog.Int("assigned_id", task.AssignedID),
+		slog.String("content", task.Content),
+		slog.String("opened", formatTime(&task.Opened)),
+		slog.String("closed", formatTime(task.Closed)),
+	)
+}
+
+// formatTime выводит время в часовом поясе location, nil - прочерк
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.In(location).Format("2006-01-02 15:04:05 MST")
+}
+
+func workWithTasks() error {
+	// Создание задачи без автора и меток
+	tasksToCreate := []model.Task{
+		{Title: "Ошибка при авторизации", Content: "При нажатии на кнопку \"Войти\" ничего не происходит"},
+
+		// Создание задачи без автора и одной меткой
+		{
+			Title:    "Баг в форме регистрации",
+			Content:  "Кнопка \"Отправить\" не активна после заполнения всех полей",
+			LabelsID: []int{5},
+		},
+		// Создание задачи с автором и одной меткой
+		{
+			Title:      "Система уведомлений",
+			Content:    "Реализовать уведомления при появлении новой новости",
+			AuthorID:   1,
+			AssignedID: 2,
+			LabelsID:   []int{5},
+		},
+		// Создание задачи с несколькими метками
+		{
+			Title:    "Добавить новый курс",
+			Content:  "Добавить возможность пользователю приобрести новый курс",
+			LabelsID: []int{2, 10, 3, 20},
+		},
+	}
+	for _, t := range tasksToCreate {
+		if err := createTask(t); err != nil {
+			return err
+		}
+	}
+
+	// Получение всех задач
+	tasks, err := db.SelectTasks()
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении задач: %w", err)
+	}
+	logTasks("Список всех задач", tasks)
+
+	// Получение задач по автору
+	tasks, err = db.SelectTasksByAuthorID(1)
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении задач автора: %w", err)
+	}
+	logTasks("Задачи по автору с ID 1", tasks)
+
+	// Получение задач по метке
+	tasks, err = db.SelectTasksByLabelID(5)
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении задач по метке: %w", err)
+	}
+	logTasks("Задачи с меткой ID 5", tasks)
+
+	// Обновление задачи
+	updateTask := model.Task{
+		ID:         1,
+		AuthorID:   1,
+		AssignedID: 3,
+		Title:      "Ошибка при авторизации",
+		Content:    "Проверить обработчик кнопки и запрос",
+		LabelsID:   []int{2, 3},
+	}
+	if err := db.UpdateTaskByID(updateTask); err != nil {
+		logger.Warn("Задача не обновлена", slog.Int("id", updateTask.ID), slog.Any("error", err))
+	} else {
+		logger.Info("Задача обновлена", slog.Int("id", updateTask.ID))
+	}
+
+	// Получение всех задач
+	tasks, err = db.SelectTasks()
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении задач: %w", err)
+	}
+	logTasks("Список всех задач", tasks)
+
+	// Удаление задачи
+	if err := db.DeleteTask(2); err != nil {
+		logger.Warn("Задача не удалена", slog.Int("id", 2), slog.Any("error", err))
+	} else {
+		logger.Info("Задача удалена", slog.Int("id", 2))
+	}
+
+	// Проверка всех задач после удаления (построчное чтение через итератор)
+	logger.Info("Список задач после удаления")
+	for task, err := range db.IterTasks() {
+		if err != nil {
+			return fmt.Errorf("Ошибка при получении задач: %w", err)
+		}
+		logTask(task)
+	}
+	return nil
+}
+
+// workWithTx создает пользователя, метку и задачу с этой меткой в одной транзакции
+func workWithTx() error {
+	var taskID int
+	err := db.WithTxOptions(storage.TxOptions{IsoLevel: storage.Serializable}, func(tx storage.Interface) error {
+		userID, err := tx.NewUser(model.User{Name: "Ольга Смирнова"})
+		if err != nil {
+			return err
+		}
+		labelID, err := tx.NewLabel(model.Label{Name: "Документация"})
+		if err != nil {
+			return err
+		}
+		taskID, err = tx.NewTask(model.Task{
+			Title:      "Описать API",
+			Content:    "Подготовить описание методов хранилища",
+			AuthorID:   userID,
+			AssignedID: userID,
+			LabelsID:   []int{labelID},
+		})
+		return err
+	})
+	if err != nil {
+		logger.Warn("Транзакция не выполнена", slog.Any("error", err))
+		return nil
+	}
+	logger.Info("Создана задача в транзакции", slog.Int("id", taskID))
+	return nil
+}
+
+// workWithAuth задает пароль пользователю ivanov, выполняет вход, проверяет и отзывает токен
+func workWithAuth(ctx context.Context) error {
+	user, err := db.SelectUserByLogin("ivanov")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя: %w", err)
+	}
+	if err := authService.SetPassword(ctx, user.ID, "секретный-пароль"); err != nil {
+		return fmt.Errorf("Ошибка при установке пароля: %w", err)
+	}
+
+	if _, _, _, err := authService.Login(ctx, "ivanov", "неверный-пароль"); err != nil {
+		logger.Info("Вход с неверным паролем отклонен", slog.Any("error", err))
+	}
+
+	token, expires, _, err := authService.Login(ctx, "ivanov", "секретный-пароль")
+	if err != nil {
+		return fmt.Errorf("Ошибка при входе: %w", err)
+	}
+	logger.Info("Выполнен вход", slog.String("login", user.Login), slog.Time("expires_at", expires))
+
+	if u, _, err := authService.Authenticate(ctx, token); err == nil {
+		logger.Info("Токен действителен", slog.Int("user_id", u.ID))
+	}
+	if err := authService.Revoke(ctx, token); err != nil {
+		return fmt.Errorf("Ошибка при отзыве токена: %w", err)
+	}
+	if _, _, err := authService.Authenticate(ctx, token); err != nil {
+		logger.Info("Отозванный токен отклонен", slog.Any("error", err))
+	}
+	return nil
+}
+
+// workWithAccess выполняет операции от имени участника ivanov и наблюдателя petrova
+// Запрещенные политикой действия не доходят до БД и возвращают access.ForbiddenErr
+func workWithAccess() error {
+	member, err := db.SelectUserByLogin("ivanov")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя: %w", err)
+	}
+	viewer, err := db.SelectUserByLogin("petrova")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя: %w", err)
+	}
+	asMember := access.New(db, member, nil)
+	asViewer := access.New(db, viewer, nil)
+
+	id, err := asMember.NewTask(model.Task{AuthorID: member.ID, AssignedID: member.ID, Title: "Проверить права доступа"})
+	if err != nil {
+		return fmt.Errorf("Ошибка при создании задачи: %w", err)
+	}
+	logger.Info("Участник создал задачу", slog.Int("task_id", id))
+
+	checks := []struct {
+		name string
+		run  func() error
+	}{
+		{"Наблюдатель создает задачу", func() error {
+			_, err := asViewer.NewTask(model.Task{AuthorID: viewer.ID, Title: "Задача наблюдателя"})
+			return err
+		}},
+		{"Участник создает задачу от чужого имени", func() error {
+			_, err := asMember.NewTask(model.Task{AuthorID: viewer.ID, Title: "Чужая задача"})
+			return err
+		}},
+		{"Участник удаляет метку", func() error {
+			return asMember.DeleteLabel(1)
+		}},
+		{"Наблюдатель изменяет задачу участника", func() error {
+			return asViewer.UpdateTaskByID(model.Task{ID: id, AuthorID: member.ID, AssignedID: viewer.ID, Title: "Изменено"})
+		}},
+	}
+	for _, c := range checks {
+		err := c.run()
+		switch {
+		case errors.Is(err, access.ForbiddenErr):
+			logger.Info("Действие запрещено", slog.String("check", c.name), slog.Any("error", err))
+		case err != nil:
+			return fmt.Errorf("%s: %w", c.name, err)
+		default:
+			return fmt.Errorf("%s: действие не было запрещено", c.name)
+		}
+	}
+
+	if err := asMember.DeleteTask(id); err != nil {
+		return fmt.Errorf("Ошибка при удалении задачи: %w", err)
+	}
+	logger.Info("Участник удалил свою задачу", slog.Int("task_id", id))
+	return nil
+}
+
+// workWithProjects создает проект с участником ivanov и показывает, что его задачи и метки
+// не видны из проекта по умолчанию и недоступны пользователям вне проекта
+func workWithProjects() error {
+	projectID, err := db.NewProject(model.Project{Name: "Сайт", Description: "Задачи по сайту"})
+	if err != nil {
+		return fmt.Errorf("Ошибка при создании проекта: %w", err)
+	}
+	defer func() {
+		if err := db.DeleteProject(projectID); err != nil {
+			logger.Warn("Проект не удален", slog.Int("project_id", projectID), slog.Any("error", err))
+		}
+	}()
+
+	member, err := db.SelectUserByLogin("ivanov")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя: %w", err)
+	}
+	outsider, err := db.SelectUserByLogin("petrova")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя: %w", err)
+	}
+	if err := db.AddProjectMember(projectID, member.ID); err != nil {
+		return fmt.Errorf("Ошибка при добавлении участника: %w", err)
+	}
+
+	project := access.New(db, member, nil).WithProject(projectID)
+	labelID, err := project.NewLabel(model.Label{Name: "Верстка"})
+	if err != nil {
+		return fmt.Errorf("Ошибка при создании метки проекта: %w", err)
+	}
+	taskID, err := project.NewTask(model.Task{AuthorID: member.ID, AssignedID: member.ID,
+		Title: "Сверстать главную страницу", LabelsID: []int{labelID}})
+	if err != nil {
+		return fmt.Errorf("Ошибка при создании задачи проекта: %w", err)
+	}
+	logger.Info("Создана задача проекта", slog.Int("project_id", projectID), slog.Int("task_id", taskID))
+
+	if _, err := db.SelectTaskByID(taskID); errors.Is(err, myerrors.NotFoundErr) {
+		logger.Info("Задача проекта не видна в проекте по умолчанию", slog.Int("task_id", taskID))
+	}
+	if _, err := access.New(db, outsider, nil).WithProject(projectID).SelectTasks(); errors.Is(err, access.ForbiddenErr) {
+		logger.Info("Пользователь вне проекта не видит его задачи", slog.Any("error", err))
+	}
+
+	tasks, err := project.SelectTasks()
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении задач проекта: %w", err)
+	}
+	logTasks("Задачи проекта", tasks)
+	return nil
+}
+
+// workWithTemplates создает задачу по шаблону с подстановкой переменных
+// Без значения переменной задача не создается
+func workWithTemplates() error {
+	author, err := db.SelectUserByLogin("ivanov")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя: %w", err)
+	}
+	labels, err := db.SelectLabels()
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении меток: %w", err)
+	}
+	t := model.TaskTemplate{Name: "Ошибка", TitlePattern: "Ошибка: {{summary}}",
+		Content: "Обнаружил: {{author}}, {{date}}\nШаги воспроизведения: {{steps}}", AssignedID: author.ID}
+	if len(labels) > 0 {
+		t.LabelsID = []int{labels[0].ID}
+	}
+	templateID, err := db.NewTaskTemplate(t)
+	if err != nil {
+		return fmt.Errorf("Ошибка при создании шаблона задачи: %w", err)
+	}
+	defer func() {
+		if err := db.DeleteTaskTemplate(templateID); err != nil {
+			logger.Warn("Шаблон задачи не удален", slog.Int("template_id", templateID), slog.Any("error", err))
+		}
+	}()
+
+	_, err = db.NewTaskFromTemplate(templateID, author.ID, map[string]string{"summary": "не открывается профиль"})
+	if errors.Is(err, postgresql.TemplateVarErr) {
+		logger.Info("Задача по шаблону не создана", slog.Any("error", err))
+	}
+	taskID, err := db.NewTaskFromTemplate(templateID, author.ID, map[string]string{
+		"summary": "не открывается профиль", "steps": "открыть профиль пользователя"})
+	if err != nil {
+		return fmt.Errorf("Ошибка при создании задачи по шаблону: %w", err)
+	}
+	task, err := db.SelectTaskByID(taskID)
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении задачи: %w", err)
+	}
+	logTasks("Задача по шаблону", []model.Task{task})
+	return nil
+}
+
+// workWithViews сохраняет общее представление с открытыми задачами пользователя и выполняет его
+func workWithViews() error {
+	user, err := db.SelectUserByLogin("ivanov")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя: %w", err)
+	}
+	viewID, err := db.NewSavedView(model.SavedView{OwnerID: user.ID, Name: "Мои открытые задачи", Shared: true,
+		Filter: model.TaskFilter{AssignedID: &user.ID, State: model.StateOpen, Sort: model.SortOpenedDesc}})
+	if err != nil {
+		return fmt.Errorf("Ошибка при создании представления: %w", err)
+	}
+	defer func() {
+		if err := db.DeleteSavedView(viewID); err != nil {
+			logger.Warn("Представление не удалено", slog.Int("view_id", viewID), slog.Any("error", err))
+		}
+	}()
+
+	tasks, err := db.ExecuteSavedView(viewID)
+	if err != nil {
+		return fmt.Errorf("Ошибка при выполнении представления: %w", err)
+	}
+	logTasks("Открытые задачи пользователя "+user.Name, tasks)
+	return nil
+}
+
+// workWithBoards создает доску по состояниям задач и перемещает на ней задачу в колонку "В работе"
+func workWithBoards() error {
+	tasks, err := db.SelectTasks()
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении задач: %w", err)
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	boardID, err := db.NewBoard(model.Board{Name: "Разработка", Kind: model.BoardByStatus, Columns: []model.BoardColumn{
+		{Name: "К выполнению", Status: model.StateOpen},
+		{Name: "В работе", Status: model.StateOpen},
+		{Name: "Готово", Status: model.StateClosed},
+	}})
+	if err != nil {
+		return fmt.Errorf("Ошибка при создании доски: %w", err)
+	}
+	defer func() {
+		if err := db.DeleteBoard(boardID); err != nil {
+			logger.Warn("Доска не удалена", slog.Int("board_id", boardID), slog.Any("error", err))
+		}
+	}()
+
+	board, err := db.SelectBoardByID(boardID)
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении доски: %w", err)
+	}
+	err = db.MoveBoardTask(model.BoardMove{BoardID: boardID, TaskID: tasks[0].ID, ColumnID: board.Columns[1].ID})
+	if err != nil {
+		return fmt.Errorf("Ошибка при перемещении задачи: %w", err)
+	}
+	cards, err := db.SelectBoardCards(boardID)
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении задач доски: %w", err)
+	}
+	for _, column := range board.Columns {
+		var columnTasks []model.Task
+		for _, card := range cards {
+			if card.ColumnID == column.ID {
+				columnTasks = append(columnTasks, card.Task)
+			}
+		}
+		logTasks("Колонка "+column.Name, columnTasks)
+	}
+	return nil
+}
+
+// workWithWorklogs задает оценку задачи и записывает затраченное на нее время
+func workWithWorklogs() error {
+	user, err := db.SelectUserByLogin("ivanov")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя: %w", err)
+	}
+	tasks, err := db.SelectTasks()
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении задач: %w", err)
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	task := tasks[0]
+	if err := db.SetTaskEstimate(task.ID, 4*time.Hour); err != nil {
+		return fmt.Errorf("Ошибка при оценке задачи: %w", err)
+	}
+	started := time.Now().Add(-3 * time.Hour)
+	for _, w := range []model.Worklog{
+		{TaskID: task.ID, UserID: user.ID, Started: started, Duration: 90 * time.Minute, Comment: "Анализ"},
+		{TaskID: task.ID, UserID: user.ID, Duration: time.Hour, Comment: "Исправление"},
+	} {
+		if _, err := db.NewWorklog(w); err != nil {
+			return fmt.Errorf("Ошибка при записи времени: %w", err)
+		}
+	}
+	_, err = db.NewWorklog(model.Worklog{TaskID: task.ID, UserID: user.ID, Duration: 25 * time.Hour})
+	if errors.Is(err, postgresql.WorklogDurationErr) {
+		logger.Info("Время не записано", slog.Any("error", err))
+	}
+	return nil
+}
+
+// workWithAttachments прикладывает к задаче журнал, читает его обратно и проверяет хеш содержимого
+func workWithAttachments() error {
+	user, err := db.SelectUserByLogin("ivanov")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя: %w", err)
+	}
+	tasks, err := db.SelectTasks()
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении задач: %w", err)
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	content := "2024-01-15 10:00:00 ERROR подключение к БД разорвано\n"
+	id, err := db.NewAttachment(model.Attachment{TaskID: tasks[0].ID, UploaderID: user.ID, Name: "logs/service.log"},
+		strings.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("Ошибка при загрузке вложения: %w", err)
+	}
+
+	a, r, err := db.OpenAttachment(id)
+	if err != nil {
+		return fmt.Errorf("Ошибка при открытии вложения: %w", err)
+	}
+	defer r.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return fmt.Errorf("Ошибка при чтении вложения: %w", err)
+	}
+	logger.Info("Вложение загружено", slog.Int("attachment_id", a.ID), slog.String("name", a.Name),
+		slog.Int64("size", a.Size), slog.String("mime_type", a.MIMEType),
+		slog.Bool("hash_ok", bytes.Equal(hash.Sum(nil), a.SHA256)))
+	return nil
+}
+
+// workWithNotifications назначает задачу с упоминанием, закрывает ее от имени автора
+// и читает входящие уведомления исполнителя
+func workWithNotifications(ctx context.Context) error {
+	author, err := db.SelectUserByLogin("ivanov")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя: %w", err)
+	}
+	users, err := db.SelectUsers()
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении пользователей: %w", err)
+	}
+	i := slices.IndexFunc(users, func(u model.User) bool { return u.Name == "Алексей Сидоров" })
+	if i < 0 {
+		return nil
+	}
+	assignee := users[i]
+
+	id, err := db.NewTask(model.Task{AuthorID: author.ID, AssignedID: assignee.ID, Title: "Проверить отчет по времени",
+		Content: "@Маша, посмотри, пожалуйста, итоги за неделю"})
+	if err != nil {
+		return fmt.Errorf("Ошибка при создании задачи: %w", err)
+	}
+	// Изменения от имени автора не попадают в его входящие
+	if err := db.WithContext(storage.WithActor(ctx, author.ID)).CloseTask(id); err != nil {
+		return fmt.Errorf("Ошибка при закрытии задачи: %w", err)
+	}
+
+	inbox, err := db.SelectNotifications(model.NotificationQuery{UserID: assignee.ID, UnreadOnly: true})
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении уведомлений: %w", err)
+	}
+	for _, n := range inbox {
+		logger.Info("Уведомление", slog.String("user", assignee.Name), slog.String("kind", string(n.Kind)),
+			slog.String("task", n.TaskTitle), slog.String("text", n.Text))
+	}
+	read, err := db.MarkAllNotificationsRead(assignee.ID)
+	if err != nil {
+		return fmt.Errorf("Ошибка при отметке уведомлений: %w", err)
+	}
+	logger.Info("Уведомления прочитаны", slog.String("user", assignee.Name), slog.Int("count", read))
+	return nil
+}
+
+// workWithRecurring создает еженедельную задачу и запускает планировщик на время ее первого запуска
+// Повторный запуск на то же время задачу не создает
+func workWithRecurring(ctx context.Context) error {
+	author, err := db.SelectUserByLogin("ivanov")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя: %w", err)
+	}
+	id, err := scheduler.NewRecurringTask(db, model.RecurringTask{Schedule: "0 10 * * 1", AuthorID: author.ID,
+		AssignedID: author.ID, Title: "Еженедельная проверка резервных копий"}, location)
+	if err != nil {
+		return fmt.Errorf("Ошибка при создании повторяющейся задачи: %w", err)
+	}
+	defer func() {
+		if err := db.DeleteRecurringTask(id); err != nil {
+			logger.Warn("Повторяющаяся задача не удалена", slog.Int("recurring_task_id", id), slog.Any("error", err))
+		}
+	}()
+	rt, err := db.SelectRecurringTaskByID(id)
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении повторяющейся задачи: %w", err)
+	}
+	logger.Info("Создана повторяющаяся задача", slog.Int("recurring_task_id", id),
+		slog.String("next_run", rt.NextRun.In(location).Format(time.DateTime)))
+
+	sched := scheduler.New(db, logger, location)
+	for range 2 {
+		created, err := sched.RunDue(ctx, rt.NextRun)
+		if err != nil {
+			return fmt.Errorf("Ошибка запуска планировщика: %w", err)
+		}
+		logger.Info("Планировщик выполнен", slog.Int("created", created))
+	}
+	return nil
+}
+
+// deleteUserWithTasks удаляет пользователя с задачами: сначала удаление отклоняется,
+// затем задачи передаются пользователю ivanov
+func deleteUserWithTasks() error {
+	id, err := db.NewUser(model.User{Name: "Временный Сотрудник"})
+	if err != nil {
+		return fmt.Errorf("Ошибка при добавлении пользователя: %w", err)
+	}
+	if _, err := db.NewTask(model.Task{AuthorID: id, AssignedID: id, Title: "Передать дела"}); err != nil {
+		return fmt.Errorf("Ошибка при создании задачи: %w", err)
+	}
+
+	report, err := db.DeleteUser(id, storage.DeleteUserOptions{Strategy: storage.DeleteReject})
+	if !errors.Is(err, postgresql.UserHasTasksErr) {
+		return fmt.Errorf("Удаление пользователя с задачами не отклонено: %w", err)
+	}
+	logger.Info("Удаление пользователя отклонено", slog.Int("authored", report.Authored),
+		slog.Int("assigned", report.Assigned))
+
+	heir, err := db.SelectUserByLogin("ivanov")
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске пользователя: %w", err)
+	}
+	report, err = db.DeleteUser(id, storage.DeleteUserOptions{Strategy: storage.DeleteReassign, ReassignTo: heir.ID})
+	if err != nil {
+		return fmt.Errorf("Ошибка при удалении пользователя: %w", err)
+	}
+	logger.Info("Пользователь удален, задачи переданы", slog.Int("to", heir.ID),
+		slog.Int("authored", report.Authored), slog.Int("assigned", report.Assigned))
+	return nil
+}
+
+// showReports строит отчеты по проекту по умолчанию и выводит их в формате CSV
+func showReports() error {
+	r := reports.New(db, location)
+	summary, err := r.Summary()
+	if err != nil {
+		return fmt.Errorf("Ошибка при построении отчета: %w", err)
+	}
+	now := time.Now()
+	flow, err := r.Flow(model.PeriodDay, now.AddDate(0, 0, -7), now)
+	if err != nil {
+		return fmt.Errorf("Ошибка при построении отчета по дням: %w", err)
+	}
+
+	byTask, err := r.TimeByTask()
+	if err != nil {
+		return fmt.Errorf("Ошибка при построении отчета по времени: %w", err)
+	}
+	timesheet, err := r.Timesheet(now.AddDate(0, 0, -1), now.Add(time.Hour), 0)
+	if err != nil {
+		return fmt.Errorf("Ошибка при построении табеля: %w", err)
+	}
+
+	var b strings.Builder
+	for _, write := range []func() error{
+		func() error { return reports.AssigneeCSV(&b, summary.ByAssignee) },
+		func() error { return reports.LabelCSV(&b, summary.ByLabel) },
+		func() error { return reports.TimeToCloseCSV(&b, summary.TimeToClose) },
+		func() error { return reports.FlowCSV(&b, flow) },
+		func() error { return reports.TasksCSV(&b, summary.OldestOpen, location) },
+		func() error { return reports.TimeByTaskCSV(&b, byTask) },
+		func() error { return reports.TimesheetCSV(&b, timesheet) },
+	} {
+		b.Reset()
+		if err := write(); err != nil {
+			return fmt.Errorf("Ошибка при выводе отчета: %w", err)
+		}
+		logger.Info("Отчет", slog.String("csv", b.String()))
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// newLogger создает журнал по переменным окружения:
+// - LOG_LEVEL - debug, info (по умолчанию), warn, error
+// - LOG_FORMAT - text (по умолчанию) или json
+func newLogger() *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(os.Getenv("LOG_LEVEL"))); err != nil {
+		level = slog.LevelInfo
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	var h slog.Handler
+	if strings.EqualFold(os.Getenv("LOG_FORMAT"), "json") {
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	} else {
+		h = slog.NewTextHandler(os.Stderr, opts)
+	}
+	return slog.New(h).With(slog.String("service", serviceName))
+}
@@ -1,234 +1,170 @@
 package main
 
 import (
-	"DB_Apps/pkg/model"
-	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/api"
+	"DB_Apps/pkg/app"
+	"DB_Apps/pkg/auth"
+	"DB_Apps/pkg/gqlapi"
+	"DB_Apps/pkg/grpcapi"
+	"DB_Apps/pkg/scheduler"
 	"DB_Apps/pkg/storage"
+	"DB_Apps/pkg/storage/blobfs"
+	"DB_Apps/pkg/storage/metrics"
 	"DB_Apps/pkg/storage/postgresql"
-	"errors"
-	"fmt"
-	"log"
+	"DB_Apps/pkg/storage/tracing"
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
 )
 
-var db storage.Interface
+var logger *slog.Logger
 
 func main() {
-	// Получаем пароль из переменной окружения
-	pwd := os.Getenv("DB_pass")
-	if pwd == "" {
-		log.Fatal("Переменная окружения DB_pass не задана")
-	}
-
-	// Строка подключения
-	connStr := fmt.Sprintf("postgres://postgres:%s@localhost:5432/tasks", pwd)
+	logger = newLogger()
+	slog.SetDefault(logger)
 
-	var err error
-	db, err = postgresql.New(connStr)
-	if err != nil {
-		log.Fatalf("Ошибка подключения к БД: %v", err)
+	if err := run(); err != nil {
+		logger.Error("Сервис завершился с ошибкой", slog.Any("error", err))
+		os.Exit(1)
 	}
-	defer db.Close()
-	fillUsers()  // Заполнение таблицы Users
-	fillLabels() // Заполнение таблицы Labels
-	workWithTasks()
-
 }
 
-func fillUsers() {
-	users := []model.User{
-		{Name: "Иван Иванов"},
-		{Name: "Мария Петрова"},
-		{Name: "Алексей   сидОРов "}, // Проверка форматирования имени: Полсе форматирования в БД должно быть Алексей Сидоров
-		{Name: ""},         // Ошибка: Пустая строка не проходит
-		{Name: "John Doe"}, // Ошибка: В имени допускается только Кириллица
+// run собирает компоненты сервиса и выполняет их до сигнала SIGINT/SIGTERM
+// Компоненты останавливаются в обратном порядке: HTTP-сервер дожидается обрабатываемых запросов,
+// фоновые задачи - завершения, а пул соединений с БД закрывается последним
+func run() error {
+	cfg, err := configFromEnv()
+	if err != nil {
+		return err
 	}
 
-	for _, u := range users {
-		id, err := db.NewUser(u)
-		if err != nil {
-			switch {
-			case errors.Is(err, postgresql.UserNameEmptyErr):
-				fmt.Printf("Ошибка при добавлении пользователя %s: %v\n", u.Name, err)
-				continue
-			case errors.Is(err, postgresql.UserNameLangErr):
-				fmt.Printf("Ошибка при добавлении пользователя %s: %v\n", u.Name, err)
-				continue
-			default:
-				log.Fatalf("Ошибка при добавлении пользователя %s: %v\n", u.Name, err)
-			}
-		}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-		fmt.Printf("Добавлен пользователь %s (ID %d)\n", u.Name, id)
-	}
-}
+	a := app.New(logger.With(slog.String("component", "app")), cfg.shutdownTimeout)
 
-func fillLabels() {
-	labels := []model.Label{
-		{Name: "Ошибка"},
-		{Name: "Новая"},
-		{Name: "Срочно"},
-		{Name: "Переделать"},
-		{Name: "Идея"},
+	// Трассировка OpenTelemetry
+	tp, shutdownTracing, err := newTracerProvider(ctx)
+	if err != nil {
+		return err
 	}
+	a.Append(app.Hook{Name: "tracing", Stop: shutdownTracing})
 
-	for _, l := range labels {
-		id, err := db.NewLabel(l)
-		if err != nil {
-			if errors.Is(err, postgresql.LabelNameErr) {
-				fmt.Printf("Ошибка при добавлении метки %s: %v\n", l.Name, err)
-				continue
-			}
-			log.Fatalf("Ошибка при добавлении метки %s: %v\n", l.Name, err)
+	// Содержимое вложений хранится в каталоге ATTACHMENTS_DIR, если он задан, иначе - в БД
+	var blobs storage.BlobStore
+	if cfg.attachmentsDir != "" {
+		if blobs, err = blobfs.New(cfg.attachmentsDir); err != nil {
+			_ = shutdownTracing(context.Background())
+			return err
 		}
-		fmt.Printf("Добавлена метка %s (ID %d)\n", l.Name, id)
 	}
-}
-
-func workWithTasks() {
-	var t model.Task
-	var err error
-	var id int
-
-	// Создание задачи без автора и меток
-	t = model.Task{Title: "Ошибка при авторизации", Content: "При нажатии на кнопку \"Войти\" ничего не происходит"}
-	id, err = db.NewTask(t)
+	pg, err := postgresql.NewWithOptions(cfg.connString, postgresql.Options{
+		QueryHooks:         []pgx.Logger{tracing.NewQueryTracer(tp)},
+		Logger:             logger.With(slog.String("component", "storage")),
+		SlowQueryThreshold: cfg.slowQueryThreshold,
+		RowLevelSecurity:   cfg.rowLevelSecurity,
+		Blobs:              blobs,
+		MaxAttachmentSize:  cfg.maxAttachmentSize,
+		Location:           cfg.location,
+	})
 	if err != nil {
-		if e, ok := err.(myerrors.TaskPartialErr); ok {
-			fmt.Printf("Ошибка создания задачи. %v\n", id, e)
-		} else {
-			log.Fatal(err)
-		}
-	} else {
-		fmt.Printf("Создана задача с ID %d\n", id)
-	}
-
-	// Создание задачи без автора и одной меткой
-	t = model.Task{
-		Title:    "Баг в форме регистрации",
-		Content:  "Кнопка \"Отправить\" не активна после заполнения всех полей",
-		LabelsID: []int{5},
-	}
-	id, err = db.NewTask(t)
+		_ = shutdownTracing(context.Background())
+		return err
+	}
+	a.Append(app.Hook{
+		Name: "storage",
+		// Перед началом работы схема БД обновляется до последней версии,
+		// а построчная защита включается или выключается по ROW_LEVEL_SECURITY
+		Start: func(context.Context) error {
+			if err := pg.Migrate(); err != nil {
+				return err
+			}
+			return pg.SetRowLevelSecurity(cfg.rowLevelSecurity)
+		},
+		Stop: func(context.Context) error {
+			pg.Close()
+			return nil
+		},
+	})
+
+	// Обертка для сбора метрик Prometheus
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics.NewPoolCollector(pg))
+	withMetrics, err := metrics.New(pg, reg, postgresql.ErrorCategory)
 	if err != nil {
-		if e, ok := err.(myerrors.TaskPartialErr); ok {
-			fmt.Printf("Создана задача с ID:%d. %v\n", id, e)
-		} else {
-			log.Fatal(err)
+		pg.Close()
+		_ = shutdownTracing(context.Background())
+		return err
+	}
+	store := tracing.New(withMetrics, tp)
+
+	// Без AUTH_KEY ключ подписи генерируется при запуске: выданные токены действуют до перезапуска
+	if cfg.authKey == nil {
+		logger.Warn("AUTH_KEY не задан, ключ подписи токенов сгенерирован случайно")
+		cfg.authKey = make([]byte, auth.MinKeyLength)
+		if _, err := rand.Read(cfg.authKey); err != nil {
+			pg.Close()
+			_ = shutdownTracing(context.Background())
+			return err
 		}
-	} else {
-		fmt.Printf("Создана задача с ID %d\n", id)
 	}
-
-	// Создание задачи с автором и одной меткой
-	t = model.Task{
-		Title:      "Система уведомлений",
-		Content:    "Реализовать уведомления при появлении новой новости",
-		AuthorID:   1,
-		AssignedID: 2,
-		LabelsID:   []int{5},
-	}
-	id, err = db.NewTask(t)
+	authService, err := auth.New(store, cfg.authKey, cfg.sessionTTL)
 	if err != nil {
-		if e, ok := err.(myerrors.TaskPartialErr); ok {
-			fmt.Printf("Создана задача с ID:%d. %v\n", id, e)
-		} else {
-			log.Fatal(err)
+		pg.Close()
+		_ = shutdownTracing(context.Background())
+		return err
+	}
+
+	// Планировщик создает задачи по шаблонам повторяющихся задач
+	sched := scheduler.New(store, logger.With(slog.String("component", "scheduler")), cfg.location)
+	if cfg.schedulerInterval > 0 {
+		sched.Interval = cfg.schedulerInterval
+	}
+	a.AddWorker("scheduler", sched.Run)
+
+	// Если задан адрес, то запускается HTTP-сервер:
+	// /metrics - метрики Prometheus, /healthz и /readyz - проверки живости и готовности, /graphql - GraphQL API
+	if cfg.httpAddr != "" {
+		handler := api.New(store, authService, logger.With(slog.String("component", "api")))
+		handler.Location = cfg.location
+		handler.Router().Handle("GET /metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+		graphQL := gqlapi.New(store, authService, logger.With(slog.String("component", "graphql")))
+		handler.Router().Handle("POST /graphql", graphQL.Handler())
+		a.Append(a.HTTPServer("http", &http.Server{
+			Addr:              cfg.httpAddr,
+			Handler:           handler.Router(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}))
+	}
+
+	// Если задан адрес, то запускается gRPC-сервер с сервисами Users, Labels и Tasks
+	if cfg.grpcAddr != "" {
+		grpcAPI := grpcapi.New(store, authService, logger.With(slog.String("component", "grpc")))
+		srv := grpc.NewServer(grpcAPI.ServerOptions()...)
+		grpcAPI.Register(srv)
+		a.Append(a.GRPCServer("grpc", cfg.grpcAddr, srv))
+	}
+
+	// Демонстрация работы с хранилищем. Без HTTP- и gRPC-серверов после нее сервис завершается
+	a.AddWorker("demo", func(ctx context.Context) error {
+		if err := runDemo(ctx, tp, store, authService, cfg.location); err != nil {
+			return err
 		}
-	} else {
-		fmt.Printf("Создана задача с ID %d\n", id)
-	}
-
-	// Создание задачи с несколькими метками
-	t = model.Task{
-		Title:    "Добавить новый курс",
-		Content:  "Добавить возможность пользователю приобрести новый курс",
-		LabelsID: []int{2, 10, 3, 20},
-	}
-	id, err = db.NewTask(t)
-	if err != nil {
-		if e, ok := err.(myerrors.TaskPartialErr); ok {
-			fmt.Printf("Создана задача с ID:%d. %v\n", id, e)
-		} else {
-			log.Fatal(err)
+		if cfg.httpAddr == "" && cfg.grpcAddr == "" {
+			a.Shutdown()
 		}
-	} else {
-		fmt.Printf("Создана задача с ID %d\n", id)
-	}
-
-	// Получение всех задач
-	fmt.Println("\nСписок всех задач:")
-	tasks, err := db.SelectTasks()
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, task := range tasks {
-		fmt.Printf("ID:%d | Title: %s | AuthorID: %v | AssignedID: %v | Content: %s\n",
-			task.ID, task.Title, task.AuthorID, task.AssignedID, task.Content)
-	}
-
-	// Получение задач по автору
-	fmt.Println("\nЗадачи по автору с ID 1:")
-	tasks, err = db.SelectTasksByAuthorID(1)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, task := range tasks {
-		fmt.Printf("ID:%d | Title: %s | Content: %s\n", task.ID, task.Title, task.Content)
-	}
-
-	// Получение задач по метке
-	fmt.Println("\nЗадачи с меткой ID 5:")
-	tasks, err = db.SelectTasksByLabelID(5)
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, task := range tasks {
-		fmt.Printf("ID:%d | Title: %s\n", task.ID, task.Title)
-	}
+		return nil
+	})
 
-	// Обновление задачи
-	fmt.Println("\nОбновление задачи ID 1...")
-	updateTask := model.Task{
-		ID:         1,
-		AuthorID:   1,
-		AssignedID: 3,
-		Title:      "Ошибка при авторизации",
-		Content:    "Проверить обработчик кнопки и запрос",
-		LabelsID:   []int{2, 3},
-	}
-	if err := db.UpdateTaskByID(updateTask); err != nil {
-		fmt.Println("Ошибка при обновлении задачи:", err)
-	} else {
-		fmt.Println("Задача успешно обновлена")
-	}
-
-	// Получение всех задач
-	fmt.Println("\nСписок всех задач:")
-	tasks, err = db.SelectTasks()
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, task := range tasks {
-		fmt.Printf("ID:%d | Title: %s | AuthorID: %v | AssignedID: %v | Content: %s\n",
-			task.ID, task.Title, task.AuthorID, task.AssignedID, task.Content)
-	}
-
-	// Удаление задачи
-	fmt.Println("\nУдаление задачи ID 2...")
-	if err := db.DeleteTask(2); err != nil {
-		fmt.Println("Ошибка при удалении:", err)
-	} else {
-		fmt.Println("Задача успешно удалена")
-	}
-
-	// Проверка всех задач после удаления
-	fmt.Println("\nСписок задач после удаления:")
-	tasks, err = db.SelectTasks()
-	if err != nil {
-		log.Fatal(err)
-	}
-	for _, task := range tasks {
-		fmt.Printf("ID:%d | Title: %s\n", task.ID, task.Title)
-	}
+	return a.Run(ctx)
 }
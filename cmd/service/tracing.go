@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const serviceName = "db_apps"
+
+// newTracerProvider создает поставщика трассировки по переменной окружения TRACES_EXPORTER:
+// - stdout - span выводятся в стандартный вывод
+// - otlp - span отправляются по OTLP/HTTP (адрес задается OTEL_EXPORTER_OTLP_ENDPOINT)
+// - пустое значение - трассировка отключена
+// Возвращает функцию, которая отправляет накопленные span и останавливает поставщика
+func newTracerProvider(ctx context.Context) (trace.TracerProvider, func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch kind := os.Getenv("TRACES_EXPORTER"); kind {
+	case "":
+		return noop.NewTracerProvider(), func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, nil, fmt.Errorf("Неизвестный экспортер трассировки: %s", kind)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return tp, tp.Shutdown, nil
+}
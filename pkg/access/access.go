@@ -0,0 +1,192 @@
+// Пакет access разграничивает доступ к хранилищу по ролям пользователей:
+// политика решает, разрешено ли действие, а Storage проверяет ее перед каждым методом storage.Interface
+package access
+
+import (
+	"DB_Apps/pkg/model"
+	"errors"
+	"fmt"
+)
+
+// ForbiddenErr - общая ошибка запрета действия
+// Ошибки политик (*ForbiddenError) проверяются с помощью errors.Is(err, ForbiddenErr)
+var ForbiddenErr = errors.New("Действие запрещено")
+
+// ForbiddenError - запрет действия Action пользователю UserID с ролью Role
+type ForbiddenError struct {
+	UserID int
+	Role   model.Role
+	Action Action
+	// Причина запрета
+	Reason string
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("Действие %s запрещено пользователю с ID %d (%s): %s", e.Action, e.UserID, e.Role, e.Reason)
+}
+
+func (e *ForbiddenError) Is(target error) bool {
+	return target == ForbiddenErr
+}
+
+// Action - действие, разрешение на которое проверяет политика
+type Action string
+
+const (
+	// Read - чтение задач и меток проекта
+	Read Action = "read"
+	// ReadShared - чтение общих данных: пользователей, проектов и токенов
+	ReadShared Action = "shared.read"
+	// CreateTask, EditTask, DeleteTask - действия над задачей Resource.Task
+	// EditTask включает изменение полей задачи, ее меток и закрытие
+	CreateTask Action = "task.create"
+	EditTask   Action = "task.edit"
+	DeleteTask Action = "task.delete"
+	// CreateLabel, EditLabel, DeleteLabel - действия над метками
+	CreateLabel Action = "label.create"
+	EditLabel   Action = "label.edit"
+	DeleteLabel Action = "label.delete"
+	// ManageTemplates - создание, изменение и удаление шаблонов задач
+	ManageTemplates Action = "template.manage"
+	// ManageBoards - создание, изменение и удаление досок и их колонок
+	// Перемещение задачи по доске меняет ее состояние или метки и проверяется как EditTask
+	ManageBoards Action = "board.manage"
+	// UseViews - создание, изменение, удаление, просмотр и выполнение представлений пользователя Resource.UserID
+	// Просмотр и выполнение общих представлений проверяются как Read
+	UseViews Action = "view.use"
+	// LogTime - добавление и удаление записей о времени пользователя Resource.UserID
+	// Оценка задачи меняется как EditTask
+	LogTime Action = "worklog.write"
+	// WatchTask - подписка пользователя Resource.UserID на изменения задачи и ее отмена
+	WatchTask Action = "task.watch"
+	// UseInbox - просмотр и отметка прочитанными уведомлений пользователя Resource.UserID
+	UseInbox Action = "inbox.use"
+	// EditProfile - изменение имени, профиля, пароля и токенов пользователя Resource.UserID
+	EditProfile Action = "user.edit"
+	// ManageUsers - создание и удаление пользователей, смена ролей и активности
+	ManageUsers Action = "user.manage"
+	// ManageProjects - создание и удаление проектов, изменение состава участников
+	ManageProjects Action = "project.manage"
+	// Diagnostics - просмотр диагностики подключения к БД
+	Diagnostics Action = "diagnostics"
+	// RunScheduler - выборка шаблонов всех проектов и создание задач по ним (планировщик)
+	RunScheduler Action = "scheduler.run"
+)
+
+// Resource - объект действия
+type Resource struct {
+	// Задача для действий CreateTask, EditTask и DeleteTask
+	Task *model.Task
+	// Пользователь для действий EditProfile, UseViews, LogTime, WatchTask и UseInbox
+	UserID int
+	// Проект и участие в нем пользователя для действий над задачами и метками (Read, *Task, *Label)
+	ProjectID int
+	Member    bool
+}
+
+// projectAction сообщает, относится ли действие к задачам и меткам проекта
+func projectAction(action Action) bool {
+	switch action {
+	case Read, CreateTask, EditTask, DeleteTask, CreateLabel, EditLabel, DeleteLabel, ManageTemplates, ManageBoards, UseViews, LogTime, WatchTask:
+		return true
+	}
+	return false
+}
+
+// Policy решает, может ли пользователь actor выполнить действие action над res
+type Policy interface {
+	// Authorize возвращает nil, если действие разрешено, иначе *ForbiddenError
+	Authorize(actor model.User, action Action, res Resource) error
+}
+
+// PolicyFunc позволяет использовать функцию как Policy
+type PolicyFunc func(actor model.User, action Action, res Resource) error
+
+func (f PolicyFunc) Authorize(actor model.User, action Action, res Resource) error {
+	return f(actor, action, res)
+}
+
+// DefaultPolicy - политика по умолчанию:
+// 1. Заблокированному пользователю запрещены все действия
+// 2. Администратору разрешены все действия
+// 3. С задачами и метками проекта работают только его участники
+// 4. Чтение разрешено всем ролям, наблюдателю (viewer) доступно только оно
+// 5. Участник (member) создает задачи только от своего имени, изменяют задачу автор или исполнитель,
+// удаляет - только автор
+// 6. Участник создает и переименовывает метки, удаляет метки только администратор; шаблонами задач и досками управляет участник
+// 7. Участник изменяет только свой профиль, пароль и токены, записывает и удаляет только свое время по задачам
+// Представлениями пользуются все роли, но только своими; общие представления доступны как чтение
+// Подписываются на задачи и читают уведомления все роли, но только за себя
+// Шаблоны повторяющихся задач проверяются как задачи: создание - CreateTask, изменение - EditTask, удаление - DeleteTask
+// 8. Управление пользователями, проектами, диагностика и запуск планировщика доступны только администратору
+func DefaultPolicy() Policy {
+	return PolicyFunc(defaultPolicy)
+}
+
+func defaultPolicy(actor model.User, action Action, res Resource) error {
+	deny := func(reason string) error {
+		return &ForbiddenError{UserID: actor.ID, Role: actor.Role, Action: action, Reason: reason}
+	}
+
+	if !actor.Active {
+		return deny("пользователь заблокирован")
+	}
+	if actor.Role == model.RoleAdmin {
+		return nil
+	}
+	if projectAction(action) && !res.Member {
+		return deny("пользователь не состоит в проекте")
+	}
+	if action == Read || action == ReadShared {
+		return nil
+	}
+	switch action {
+	case UseViews:
+		if res.UserID != actor.ID {
+			return deny("пользоваться можно только своими представлениями")
+		}
+		return nil
+	case WatchTask:
+		if res.UserID != actor.ID {
+			return deny("подписывать на задачи можно только себя")
+		}
+		return nil
+	case UseInbox:
+		if res.UserID != actor.ID {
+			return deny("просматривать можно только свои уведомления")
+		}
+		return nil
+	}
+	if actor.Role != model.RoleMember {
+		return deny("доступно только чтение")
+	}
+
+	switch action {
+	case CreateTask:
+		if res.Task == nil || res.Task.AuthorID != actor.ID {
+			return deny("задачу можно создать только от своего имени")
+		}
+	case EditTask:
+		if res.Task == nil || (res.Task.AuthorID != actor.ID && res.Task.AssignedID != actor.ID) {
+			return deny("изменять задачу могут только автор или исполнитель")
+		}
+	case DeleteTask:
+		if res.Task == nil || res.Task.AuthorID != actor.ID {
+			return deny("удалить задачу может только автор")
+		}
+	case CreateLabel, EditLabel, ManageTemplates, ManageBoards:
+	case DeleteLabel:
+		return deny("удалять метки может только администратор")
+	case EditProfile:
+		if res.UserID != actor.ID {
+			return deny("изменять можно только свой профиль")
+		}
+	case LogTime:
+		if res.UserID != actor.ID {
+			return deny("записывать и удалять можно только свое время")
+		}
+	default:
+		return deny("действие доступно только администратору")
+	}
+	return nil
+}
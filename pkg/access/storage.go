@@ -0,0 +1,901 @@
+package access
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage"
+	"context"
+	"io"
+	"iter"
+	"time"
+)
+
+// Storage - обертка над storage.Interface, выполняющая методы от имени пользователя actor
+// Перед каждым методом действие проверяется политикой, при запрете возвращается *ForbiddenError,
+// а метод хранилища не вызывается
+// Для действий над существующей задачей она загружается и проверяется в той же транзакции, что и изменение
+type Storage struct {
+	next   storage.Interface
+	actor  model.User
+	policy Policy
+}
+
+// New создает обертку над next для пользователя actor
+// Если policy равна nil, то используется DefaultPolicy
+func New(next storage.Interface, actor model.User, policy Policy) *Storage {
+	if policy == nil {
+		policy = DefaultPolicy()
+	}
+	return &Storage{next: next, actor: actor, policy: policy}
+}
+
+// Actor возвращает пользователя, от имени которого выполняются методы
+func (s *Storage) Actor() model.User {
+	return s.actor
+}
+
+// with возвращает обертку с тем же пользователем и политикой над next
+func (s *Storage) with(next storage.Interface) *Storage {
+	c := *s
+	c.next = next
+	return &c
+}
+
+func (s *Storage) authorize(action Action, res Resource) error {
+	return s.policy.Authorize(s.actor, action, res)
+}
+
+// authorizeIn проверяет действие над задачами и метками проекта хранилища db,
+// дополняя res проектом и участием в нем пользователя
+func (s *Storage) authorizeIn(db storage.Interface, action Action, res Resource) error {
+	res.ProjectID = db.ProjectID()
+	member, err := db.IsProjectMember(res.ProjectID, s.actor.ID)
+	if err != nil {
+		return err
+	}
+	res.Member = member
+	return s.authorize(action, res)
+}
+
+// withTask загружает задачу id, проверяет действие над ней и выполняет fn в одной транзакции
+func (s *Storage) withTask(id int, action Action, fn func(storage.Interface) error) error {
+	return s.next.WithTx(func(tx storage.Interface) error {
+		task, err := tx.SelectTaskByID(id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorizeIn(tx, action, Resource{Task: &task}); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// recurringTask возвращает задачу, которую создает шаблон, для проверки прав политикой
+func recurringTask(rt model.RecurringTask) model.Task {
+	return model.Task{ProjectID: rt.ProjectID, AuthorID: rt.AuthorID, AssignedID: rt.AssignedID,
+		Title: rt.Title, Content: rt.Content, LabelsID: rt.LabelsID}
+}
+
+// withView проверяет доступ к представлению id по его текущему состоянию и выполняет fn в той же транзакции
+// Изменять (edit) можно только свои представления, читать и выполнять - также общие
+func (s *Storage) withView(id int, edit bool, fn func(storage.Interface) error) error {
+	return s.next.WithTx(func(tx storage.Interface) error {
+		v, err := tx.SelectSavedViewByID(id)
+		if err != nil {
+			return err
+		}
+		action, res := UseViews, Resource{UserID: v.OwnerID}
+		if v.Shared && !edit {
+			action, res = Read, Resource{}
+		}
+		if err := s.authorizeIn(tx, action, res); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// withRecurring проверяет действие над шаблоном по его текущему состоянию и выполняет fn в той же транзакции
+func (s *Storage) withRecurring(id int, action Action, fn func(storage.Interface) error) error {
+	return s.next.WithTx(func(tx storage.Interface) error {
+		rt, err := tx.SelectRecurringTaskByID(id)
+		if err != nil {
+			return err
+		}
+		task := recurringTask(rt)
+		if err := s.authorizeIn(tx, action, Resource{Task: &task}); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// forbiddenIter возвращает итератор, который сразу отдает ошибку err
+func forbiddenIter[T any](err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		yield(zero, err)
+	}
+}
+
+// readIter проверяет право на чтение проекта и возвращает итератор seq или итератор с ошибкой
+func readIter[T any](s *Storage, seq func() iter.Seq2[T, error]) iter.Seq2[T, error] {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return forbiddenIter[T](err)
+	}
+	return seq()
+}
+
+func (s *Storage) NewUser(user model.User) (int, error) {
+	if err := s.authorize(ManageUsers, Resource{}); err != nil {
+		return 0, err
+	}
+	return s.next.NewUser(user)
+}
+
+func (s *Storage) DeleteUser(id int, opts storage.DeleteUserOptions) (storage.DeleteUserReport, error) {
+	if err := s.authorize(ManageUsers, Resource{}); err != nil {
+		return storage.DeleteUserReport{}, err
+	}
+	return s.next.DeleteUser(id, opts)
+}
+
+func (s *Storage) UpdateUserName(id int, name string) error {
+	if err := s.authorize(EditProfile, Resource{UserID: id}); err != nil {
+		return err
+	}
+	return s.next.UpdateUserName(id, name)
+}
+
+func (s *Storage) UpdateUserProfile(user model.User) error {
+	if err := s.authorize(EditProfile, Resource{UserID: user.ID}); err != nil {
+		return err
+	}
+	return s.next.UpdateUserProfile(user)
+}
+
+func (s *Storage) SetUserRole(id int, role model.Role) error {
+	if err := s.authorize(ManageUsers, Resource{}); err != nil {
+		return err
+	}
+	return s.next.SetUserRole(id, role)
+}
+
+func (s *Storage) SetUserActive(id int, active bool) error {
+	if err := s.authorize(ManageUsers, Resource{}); err != nil {
+		return err
+	}
+	return s.next.SetUserActive(id, active)
+}
+
+func (s *Storage) SelectUsers() ([]model.User, error) {
+	if err := s.authorize(ReadShared, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectUsers()
+}
+
+func (s *Storage) SelectUserByID(id int) (model.User, error) {
+	if err := s.authorize(ReadShared, Resource{}); err != nil {
+		return model.User{}, err
+	}
+	return s.next.SelectUserByID(id)
+}
+
+func (s *Storage) SelectUserByLogin(login string) (model.User, error) {
+	if err := s.authorize(ReadShared, Resource{}); err != nil {
+		return model.User{}, err
+	}
+	return s.next.SelectUserByLogin(login)
+}
+
+func (s *Storage) SelectUserByEmail(email string) (model.User, error) {
+	if err := s.authorize(ReadShared, Resource{}); err != nil {
+		return model.User{}, err
+	}
+	return s.next.SelectUserByEmail(email)
+}
+
+func (s *Storage) SelectUsersByIDs(ids []int) ([]model.User, error) {
+	if err := s.authorize(ReadShared, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectUsersByIDs(ids)
+}
+
+func (s *Storage) IterUsers() iter.Seq2[model.User, error] {
+	if err := s.authorize(ReadShared, Resource{}); err != nil {
+		return forbiddenIter[model.User](err)
+	}
+	return s.next.IterUsers()
+}
+
+func (s *Storage) SetUserPassword(id int, hash string) error {
+	if err := s.authorize(EditProfile, Resource{UserID: id}); err != nil {
+		return err
+	}
+	return s.next.SetUserPassword(id, hash)
+}
+
+// SelectUserPassword требует ManageUsers: хеши паролей читает только администратор
+func (s *Storage) SelectUserPassword(id int) (string, error) {
+	if err := s.authorize(ManageUsers, Resource{}); err != nil {
+		return "", err
+	}
+	return s.next.SelectUserPassword(id)
+}
+
+func (s *Storage) NewToken(token model.Token) (int, error) {
+	if err := s.authorize(EditProfile, Resource{UserID: token.UserID}); err != nil {
+		return 0, err
+	}
+	return s.next.NewToken(token)
+}
+
+func (s *Storage) SelectTokenByHash(hash []byte) (model.Token, error) {
+	if err := s.authorize(ReadShared, Resource{}); err != nil {
+		return model.Token{}, err
+	}
+	return s.next.SelectTokenByHash(hash)
+}
+
+// RevokeToken требует ManageUsers: по ID токена нельзя проверить владельца,
+// свои токены пользователь отзывает через RevokeUserTokens или auth.Service
+func (s *Storage) RevokeToken(id int) error {
+	if err := s.authorize(ManageUsers, Resource{}); err != nil {
+		return err
+	}
+	return s.next.RevokeToken(id)
+}
+
+func (s *Storage) RevokeUserTokens(userID int) (int, error) {
+	if err := s.authorize(EditProfile, Resource{UserID: userID}); err != nil {
+		return 0, err
+	}
+	return s.next.RevokeUserTokens(userID)
+}
+
+func (s *Storage) NewProject(p model.Project) (int, error) {
+	if err := s.authorize(ManageProjects, Resource{}); err != nil {
+		return 0, err
+	}
+	return s.next.NewProject(p)
+}
+
+func (s *Storage) DeleteProject(id int) error {
+	if err := s.authorize(ManageProjects, Resource{}); err != nil {
+		return err
+	}
+	return s.next.DeleteProject(id)
+}
+
+func (s *Storage) SelectProjects() ([]model.Project, error) {
+	if err := s.authorize(ReadShared, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectProjects()
+}
+
+func (s *Storage) SelectProjectByID(id int) (model.Project, error) {
+	if err := s.authorize(ReadShared, Resource{}); err != nil {
+		return model.Project{}, err
+	}
+	return s.next.SelectProjectByID(id)
+}
+
+func (s *Storage) SelectUserProjects(userID int) ([]model.Project, error) {
+	if err := s.authorize(ReadShared, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectUserProjects(userID)
+}
+
+func (s *Storage) AddProjectMember(projectID, userID int) error {
+	if err := s.authorize(ManageProjects, Resource{}); err != nil {
+		return err
+	}
+	return s.next.AddProjectMember(projectID, userID)
+}
+
+func (s *Storage) DeleteProjectMember(projectID, userID int) error {
+	if err := s.authorize(ManageProjects, Resource{}); err != nil {
+		return err
+	}
+	return s.next.DeleteProjectMember(projectID, userID)
+}
+
+func (s *Storage) SelectProjectMembers(projectID int) ([]model.User, error) {
+	if err := s.authorize(ReadShared, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectProjectMembers(projectID)
+}
+
+func (s *Storage) IsProjectMember(projectID, userID int) (bool, error) {
+	if err := s.authorize(ReadShared, Resource{}); err != nil {
+		return false, err
+	}
+	return s.next.IsProjectMember(projectID, userID)
+}
+
+func (s *Storage) NewLabel(label model.Label) (int, error) {
+	if err := s.authorizeIn(s.next, CreateLabel, Resource{}); err != nil {
+		return 0, err
+	}
+	return s.next.NewLabel(label)
+}
+
+func (s *Storage) DeleteLabel(id int) error {
+	if err := s.authorizeIn(s.next, DeleteLabel, Resource{}); err != nil {
+		return err
+	}
+	return s.next.DeleteLabel(id)
+}
+
+func (s *Storage) UpdateLabelName(id int, name string) error {
+	if err := s.authorizeIn(s.next, EditLabel, Resource{}); err != nil {
+		return err
+	}
+	return s.next.UpdateLabelName(id, name)
+}
+
+func (s *Storage) SelectLabels() ([]model.Label, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectLabels()
+}
+
+func (s *Storage) SelectLabelByID(id int) (model.Label, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return model.Label{}, err
+	}
+	return s.next.SelectLabelByID(id)
+}
+
+func (s *Storage) SelectLabelsByIDs(ids []int) ([]model.Label, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectLabelsByIDs(ids)
+}
+
+func (s *Storage) IterLabels() iter.Seq2[model.Label, error] {
+	return readIter(s, s.next.IterLabels)
+}
+
+func (s *Storage) NewTask(task model.Task) (int, error) {
+	if err := s.authorizeIn(s.next, CreateTask, Resource{Task: &task}); err != nil {
+		return 0, err
+	}
+	return s.next.NewTask(task)
+}
+
+func (s *Storage) SelectTasks() ([]model.Task, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectTasks()
+}
+
+func (s *Storage) SelectTaskByID(id int) (model.Task, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return model.Task{}, err
+	}
+	return s.next.SelectTaskByID(id)
+}
+
+func (s *Storage) SelectTasksByAuthorID(authorID int) ([]model.Task, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectTasksByAuthorID(authorID)
+}
+
+func (s *Storage) SelectTasksByLabelID(labelID int) ([]model.Task, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectTasksByLabelID(labelID)
+}
+
+func (s *Storage) SelectTasksPage(q model.TaskPageQuery) ([]model.Task, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectTasksPage(q)
+}
+
+func (s *Storage) SelectTaskLabelIDs(taskIDs []int) (map[int][]int, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectTaskLabelIDs(taskIDs)
+}
+
+func (s *Storage) IterTasks() iter.Seq2[model.Task, error] {
+	return readIter(s, s.next.IterTasks)
+}
+
+func (s *Storage) IterTasksByAuthorID(authorID int) iter.Seq2[model.Task, error] {
+	return readIter(s, func() iter.Seq2[model.Task, error] {
+		return s.next.IterTasksByAuthorID(authorID)
+	})
+}
+
+func (s *Storage) IterTasksByLabelID(labelID int) iter.Seq2[model.Task, error] {
+	return readIter(s, func() iter.Seq2[model.Task, error] {
+		return s.next.IterTasksByLabelID(labelID)
+	})
+}
+
+func (s *Storage) DeleteTask(id int) error {
+	return s.withTask(id, DeleteTask, func(tx storage.Interface) error {
+		return tx.DeleteTask(id)
+	})
+}
+
+// UpdateTaskByID проверяет право на изменение по текущему состоянию задачи, а не по переданному
+func (s *Storage) UpdateTaskByID(task model.Task) error {
+	return s.withTask(task.ID, EditTask, func(tx storage.Interface) error {
+		return tx.UpdateTaskByID(task)
+	})
+}
+
+func (s *Storage) CloseTask(id int) error {
+	return s.withTask(id, EditTask, func(tx storage.Interface) error {
+		return tx.CloseTask(id)
+	})
+}
+
+func (s *Storage) AddLabelToTask(labelID, taskID int) error {
+	return s.withTask(taskID, EditTask, func(tx storage.Interface) error {
+		return tx.AddLabelToTask(labelID, taskID)
+	})
+}
+
+func (s *Storage) DeleteLabelToTask(labelID, taskID int) error {
+	return s.withTask(taskID, EditTask, func(tx storage.Interface) error {
+		return tx.DeleteLabelToTask(labelID, taskID)
+	})
+}
+
+func (s *Storage) NewBoard(b model.Board) (int, error) {
+	if err := s.authorizeIn(s.next, ManageBoards, Resource{}); err != nil {
+		return 0, err
+	}
+	return s.next.NewBoard(b)
+}
+
+func (s *Storage) UpdateBoard(b model.Board) error {
+	if err := s.authorizeIn(s.next, ManageBoards, Resource{}); err != nil {
+		return err
+	}
+	return s.next.UpdateBoard(b)
+}
+
+func (s *Storage) DeleteBoard(id int) error {
+	if err := s.authorizeIn(s.next, ManageBoards, Resource{}); err != nil {
+		return err
+	}
+	return s.next.DeleteBoard(id)
+}
+
+func (s *Storage) SelectBoards() ([]model.Board, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectBoards()
+}
+
+func (s *Storage) SelectBoardByID(id int) (model.Board, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return model.Board{}, err
+	}
+	return s.next.SelectBoardByID(id)
+}
+
+func (s *Storage) SelectBoardCards(boardID int) ([]model.BoardCard, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectBoardCards(boardID)
+}
+
+// MoveBoardTask проверяется как изменение задачи: колонка меняет ее состояние или метки
+func (s *Storage) MoveBoardTask(m model.BoardMove) error {
+	return s.withTask(m.TaskID, EditTask, func(tx storage.Interface) error {
+		return tx.MoveBoardTask(m)
+	})
+}
+
+func (s *Storage) NewWorklog(w model.Worklog) (int, error) {
+	if err := s.authorizeIn(s.next, LogTime, Resource{UserID: w.UserID}); err != nil {
+		return 0, err
+	}
+	return s.next.NewWorklog(w)
+}
+
+// DeleteWorklog проверяет право по пользователю записи в той же транзакции, что и удаление
+func (s *Storage) DeleteWorklog(id int) error {
+	return s.next.WithTx(func(tx storage.Interface) error {
+		w, err := tx.SelectWorklogByID(id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorizeIn(tx, LogTime, Resource{UserID: w.UserID}); err != nil {
+			return err
+		}
+		return tx.DeleteWorklog(id)
+	})
+}
+
+func (s *Storage) SelectWorklogByID(id int) (model.Worklog, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return model.Worklog{}, err
+	}
+	return s.next.SelectWorklogByID(id)
+}
+
+func (s *Storage) SelectWorklogs(taskID int) ([]model.Worklog, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectWorklogs(taskID)
+}
+
+func (s *Storage) SetTaskEstimate(taskID int, estimate time.Duration) error {
+	return s.withTask(taskID, EditTask, func(tx storage.Interface) error {
+		return tx.SetTaskEstimate(taskID, estimate)
+	})
+}
+
+// NewAttachment загружает файл от имени пользователя обертки, если он может изменять задачу
+// Задача проверяется до загрузки вне транзакции, чтобы не держать ее открытой во время передачи файла
+func (s *Storage) NewAttachment(a model.Attachment, content io.Reader) (int, error) {
+	task, err := s.next.SelectTaskByID(a.TaskID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.authorizeIn(s.next, EditTask, Resource{Task: &task}); err != nil {
+		return 0, err
+	}
+	a.UploaderID = s.actor.ID
+	return s.next.NewAttachment(a, content)
+}
+
+// DeleteAttachment проверяет право изменять задачу вложения в той же транзакции, что и удаление
+func (s *Storage) DeleteAttachment(id int) error {
+	return s.next.WithTx(func(tx storage.Interface) error {
+		a, err := tx.SelectAttachmentByID(id)
+		if err != nil {
+			return err
+		}
+		task, err := tx.SelectTaskByID(a.TaskID)
+		if err != nil {
+			return err
+		}
+		if err := s.authorizeIn(tx, EditTask, Resource{Task: &task}); err != nil {
+			return err
+		}
+		return tx.DeleteAttachment(id)
+	})
+}
+
+func (s *Storage) SelectAttachmentByID(id int) (model.Attachment, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return model.Attachment{}, err
+	}
+	return s.next.SelectAttachmentByID(id)
+}
+
+func (s *Storage) SelectAttachments(taskID int) ([]model.Attachment, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectAttachments(taskID)
+}
+
+func (s *Storage) OpenAttachment(id int) (model.Attachment, io.ReadCloser, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return model.Attachment{}, nil, err
+	}
+	return s.next.OpenAttachment(id)
+}
+
+func (s *Storage) WatchTask(taskID, userID int) error {
+	if err := s.authorizeIn(s.next, WatchTask, Resource{UserID: userID}); err != nil {
+		return err
+	}
+	return s.next.WatchTask(taskID, userID)
+}
+
+func (s *Storage) UnwatchTask(taskID, userID int) error {
+	if err := s.authorizeIn(s.next, WatchTask, Resource{UserID: userID}); err != nil {
+		return err
+	}
+	return s.next.UnwatchTask(taskID, userID)
+}
+
+func (s *Storage) SelectTaskWatchers(taskID int) ([]model.User, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectTaskWatchers(taskID)
+}
+
+// Входящие общие для всех проектов, поэтому участие в проекте хранилища не проверяется
+func (s *Storage) SelectNotifications(q model.NotificationQuery) ([]model.Notification, error) {
+	if err := s.authorize(UseInbox, Resource{UserID: q.UserID}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectNotifications(q)
+}
+
+func (s *Storage) MarkNotificationRead(userID, id int) error {
+	if err := s.authorize(UseInbox, Resource{UserID: userID}); err != nil {
+		return err
+	}
+	return s.next.MarkNotificationRead(userID, id)
+}
+
+func (s *Storage) MarkAllNotificationsRead(userID int) (int, error) {
+	if err := s.authorize(UseInbox, Resource{UserID: userID}); err != nil {
+		return 0, err
+	}
+	return s.next.MarkAllNotificationsRead(userID)
+}
+
+func (s *Storage) NewTaskTemplate(t model.TaskTemplate) (int, error) {
+	if err := s.authorizeIn(s.next, ManageTemplates, Resource{}); err != nil {
+		return 0, err
+	}
+	return s.next.NewTaskTemplate(t)
+}
+
+func (s *Storage) UpdateTaskTemplate(t model.TaskTemplate) error {
+	if err := s.authorizeIn(s.next, ManageTemplates, Resource{}); err != nil {
+		return err
+	}
+	return s.next.UpdateTaskTemplate(t)
+}
+
+func (s *Storage) DeleteTaskTemplate(id int) error {
+	if err := s.authorizeIn(s.next, ManageTemplates, Resource{}); err != nil {
+		return err
+	}
+	return s.next.DeleteTaskTemplate(id)
+}
+
+func (s *Storage) SelectTaskTemplates() ([]model.TaskTemplate, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectTaskTemplates()
+}
+
+func (s *Storage) SelectTaskTemplateByID(id int) (model.TaskTemplate, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return model.TaskTemplate{}, err
+	}
+	return s.next.SelectTaskTemplateByID(id)
+}
+
+// NewTaskFromTemplate проверяет право на создание задачи с автором authorID и исполнителем шаблона
+func (s *Storage) NewTaskFromTemplate(templateID, authorID int, vars map[string]string) (int, error) {
+	var id int
+	err := s.next.WithTx(func(tx storage.Interface) error {
+		t, err := tx.SelectTaskTemplateByID(templateID)
+		if err != nil {
+			return err
+		}
+		task := model.Task{AuthorID: authorID, AssignedID: t.AssignedID, LabelsID: t.LabelsID}
+		if err := s.authorizeIn(tx, CreateTask, Resource{Task: &task}); err != nil {
+			return err
+		}
+		id, err = tx.NewTaskFromTemplate(templateID, authorID, vars)
+		return err
+	})
+	return id, err
+}
+
+func (s *Storage) NewSavedView(v model.SavedView) (int, error) {
+	if err := s.authorizeIn(s.next, UseViews, Resource{UserID: v.OwnerID}); err != nil {
+		return 0, err
+	}
+	return s.next.NewSavedView(v)
+}
+
+// UpdateSavedView проверяет право по текущему владельцу представления, а не по переданному
+func (s *Storage) UpdateSavedView(v model.SavedView) error {
+	return s.withView(v.ID, true, func(tx storage.Interface) error {
+		return tx.UpdateSavedView(v)
+	})
+}
+
+func (s *Storage) DeleteSavedView(id int) error {
+	return s.withView(id, true, func(tx storage.Interface) error {
+		return tx.DeleteSavedView(id)
+	})
+}
+
+// SelectSavedViews возвращает в том числе личные представления ownerID, поэтому проверяет UseViews
+func (s *Storage) SelectSavedViews(ownerID int) ([]model.SavedView, error) {
+	if err := s.authorizeIn(s.next, UseViews, Resource{UserID: ownerID}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectSavedViews(ownerID)
+}
+
+func (s *Storage) SelectSavedViewByID(id int) (model.SavedView, error) {
+	var v model.SavedView
+	err := s.withView(id, false, func(tx storage.Interface) error {
+		var err error
+		v, err = tx.SelectSavedViewByID(id)
+		return err
+	})
+	return v, err
+}
+
+func (s *Storage) ExecuteSavedView(id int) ([]model.Task, error) {
+	var tasks []model.Task
+	err := s.withView(id, false, func(tx storage.Interface) error {
+		var err error
+		tasks, err = tx.ExecuteSavedView(id)
+		return err
+	})
+	return tasks, err
+}
+
+func (s *Storage) SelectTasksByFilter(f model.TaskFilter) ([]model.Task, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectTasksByFilter(f)
+}
+
+func (s *Storage) NewRecurringTask(rt model.RecurringTask) (int, error) {
+	task := recurringTask(rt)
+	if err := s.authorizeIn(s.next, CreateTask, Resource{Task: &task}); err != nil {
+		return 0, err
+	}
+	return s.next.NewRecurringTask(rt)
+}
+
+func (s *Storage) SelectRecurringTasks() ([]model.RecurringTask, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectRecurringTasks()
+}
+
+func (s *Storage) SelectRecurringTaskByID(id int) (model.RecurringTask, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return model.RecurringTask{}, err
+	}
+	return s.next.SelectRecurringTaskByID(id)
+}
+
+func (s *Storage) SetRecurringTaskActive(id int, active bool) error {
+	return s.withRecurring(id, EditTask, func(tx storage.Interface) error {
+		return tx.SetRecurringTaskActive(id, active)
+	})
+}
+
+func (s *Storage) DeleteRecurringTask(id int) error {
+	return s.withRecurring(id, DeleteTask, func(tx storage.Interface) error {
+		return tx.DeleteRecurringTask(id)
+	})
+}
+
+func (s *Storage) SelectDueRecurringTasks(now time.Time, limit int) ([]model.RecurringTask, error) {
+	if err := s.authorize(RunScheduler, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.SelectDueRecurringTasks(now, limit)
+}
+
+func (s *Storage) RunRecurringTask(rt model.RecurringTask, next time.Time) (int, error) {
+	if err := s.authorize(RunScheduler, Resource{}); err != nil {
+		return 0, err
+	}
+	return s.next.RunRecurringTask(rt, next)
+}
+
+func (s *Storage) ReportByAssignee() ([]model.AssigneeStats, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.ReportByAssignee()
+}
+
+func (s *Storage) ReportByLabel() ([]model.LabelStats, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.ReportByLabel()
+}
+
+func (s *Storage) ReportTimeToClose() (model.CloseTimeStats, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return model.CloseTimeStats{}, err
+	}
+	return s.next.ReportTimeToClose()
+}
+
+func (s *Storage) ReportFlow(q model.FlowQuery) ([]model.FlowPoint, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.ReportFlow(q)
+}
+
+func (s *Storage) ReportTimeByTask() ([]model.TaskTimeStats, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.ReportTimeByTask()
+}
+
+func (s *Storage) ReportTimeByUser() ([]model.UserTimeStats, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.ReportTimeByUser()
+}
+
+func (s *Storage) ReportTimesheet(q model.TimesheetQuery) ([]model.TimesheetEntry, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.ReportTimesheet(q)
+}
+
+func (s *Storage) ReportOldestOpen(limit int) ([]model.Task, error) {
+	if err := s.authorizeIn(s.next, Read, Resource{}); err != nil {
+		return nil, err
+	}
+	return s.next.ReportOldestOpen(limit)
+}
+
+// WithTx выполняет fn в транзакции, методы хранилища внутри fn проверяются той же политикой
+func (s *Storage) WithTx(fn func(storage.Interface) error) error {
+	return s.next.WithTx(func(tx storage.Interface) error {
+		return fn(s.with(tx))
+	})
+}
+
+func (s *Storage) WithTxOptions(opts storage.TxOptions, fn func(storage.Interface) error) error {
+	return s.next.WithTxOptions(opts, func(tx storage.Interface) error {
+		return fn(s.with(tx))
+	})
+}
+
+func (s *Storage) Ping() error {
+	return s.next.Ping()
+}
+
+func (s *Storage) Diagnostics() (storage.Diagnostics, error) {
+	if err := s.authorize(Diagnostics, Resource{}); err != nil {
+		return storage.Diagnostics{}, err
+	}
+	return s.next.Diagnostics()
+}
+
+// WithProject возвращает обертку с тем же пользователем над хранилищем проекта projectID
+// Работать с задачами и метками проекта по политике по умолчанию могут только его участники
+func (s *Storage) WithProject(projectID int) storage.Interface {
+	return s.with(s.next.WithProject(projectID))
+}
+
+func (s *Storage) ProjectID() int {
+	return s.next.ProjectID()
+}
+
+func (s *Storage) WithContext(ctx context.Context) storage.Interface {
+	return s.with(s.next.WithContext(ctx))
+}
+
+// Close ничего не делает: обертка создается на время запроса и не владеет соединением,
+// общее хранилище закрывает его владелец
+func (s *Storage) Close() {}
@@ -0,0 +1,135 @@
+// Пакет api содержит HTTP API сервиса
+package api
+
+import (
+	"DB_Apps/pkg/access"
+	"DB_Apps/pkg/auth"
+	"DB_Apps/pkg/storage"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Заголовок с ID проекта, в котором выполняется запрос к данным проекта (доски, задачи)
+// Без него используется проект хранилища db
+const ProjectHeader = "X-Project-ID"
+
+// API - HTTP API поверх storage.Interface
+type API struct {
+	db     storage.Interface
+	auth   *auth.Service
+	router *http.ServeMux
+	logger *slog.Logger
+
+	// Параметры проверки готовности (/readyz)
+	Readiness ReadinessOptions
+	// Часовой пояс дат в параметрах запросов и отчетах (табель), по умолчанию - UTC
+	Location *time.Location
+}
+
+// New создает API и регистрирует его обработчики
+// Если authService равен nil, то обработчики /auth/* не регистрируются
+func New(db storage.Interface, authService *auth.Service, logger *slog.Logger) *API {
+	api := &API{
+		db:        db,
+		auth:      authService,
+		router:    http.NewServeMux(),
+		logger:    logger,
+		Readiness: DefaultReadinessOptions,
+		Location:  time.UTC,
+	}
+	api.endpoints()
+	return api
+}
+
+// Router возвращает маршрутизатор запросов API
+// Через него же можно зарегистрировать дополнительные обработчики (например /metrics)
+func (api *API) Router() *http.ServeMux {
+	return api.router
+}
+
+// endpoints регистрирует обработчики API
+func (api *API) endpoints() {
+	api.router.HandleFunc("GET /healthz", api.healthz)
+	api.router.HandleFunc("GET /readyz", api.readyz)
+	if api.auth != nil {
+		api.authEndpoints()
+	}
+	api.boardEndpoints()
+	api.worklogEndpoints()
+	api.attachmentEndpoints()
+	api.notificationEndpoints()
+}
+
+// protected оборачивает обработчик данных проекта проверкой токена, если API создано с аутентификацией
+func (api *API) protected(h http.HandlerFunc) http.Handler {
+	if api.auth == nil {
+		return h
+	}
+	return api.auth.Middleware(h)
+}
+
+// storage возвращает хранилище запроса: контекст запроса, проект из заголовка ProjectHeader
+// и проверку прав текущего пользователя (access.DefaultPolicy), если API создано с аутентификацией
+func (api *API) storage(r *http.Request) (storage.Interface, error) {
+	db := api.db.WithContext(r.Context())
+	if value := r.Header.Get(ProjectHeader); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New("Некорректный ID проекта в заголовке " + ProjectHeader)
+		}
+		db = db.WithProject(id)
+	}
+	if api.auth != nil {
+		user, _ := auth.UserFromContext(r.Context())
+		db = access.New(db, user, nil)
+	}
+	return db, nil
+}
+
+// storageWithID возвращает хранилище запроса и ID объекта из параметра {id} пути
+// При ошибке отправляет ответ 400 и возвращает ok = false
+func (api *API) storageWithID(w http.ResponseWriter, r *http.Request) (db storage.Interface, id int, ok bool) {
+	db, err := api.storage(r)
+	if err == nil {
+		id, err = pathID(r, "id")
+	}
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, err)
+		return nil, 0, false
+	}
+	return db, id, true
+}
+
+// pathID возвращает целочисленный параметр name пути запроса
+func pathID(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil {
+		return 0, errors.New("Некорректный ID в пути запроса: " + r.PathValue(name))
+	}
+	return id, nil
+}
+
+// writeJSON отправляет v в формате JSON с кодом status
+func (api *API) writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		api.logger.WarnContext(r.Context(), "Ошибка при отправке ответа", slog.Any("error", err))
+	}
+}
+
+// writeError отправляет ошибку в формате JSON с кодом status
+// Текст внутренних ошибок (код 500) клиенту не передается, а записывается в журнал
+func (api *API) writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	msg := err.Error()
+	if status >= http.StatusInternalServerError {
+		api.logger.ErrorContext(r.Context(), "Ошибка обработки запроса",
+			slog.String("path", r.URL.Path), slog.Any("error", err))
+		msg = http.StatusText(status)
+	}
+	api.writeJSON(w, r, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"DB_Apps/pkg/auth"
+	"DB_Apps/pkg/model"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type attachmentResponse struct {
+	ID         int       `json:"id"`
+	TaskID     int       `json:"task_id"`
+	UploaderID int       `json:"uploader_id"`
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	MIMEType   string    `json:"mime_type"`
+	SHA256     string    `json:"sha256"`
+	Created    time.Time `json:"created"`
+}
+
+func newAttachmentResponse(a model.Attachment) attachmentResponse {
+	return attachmentResponse{
+		ID:         a.ID,
+		TaskID:     a.TaskID,
+		UploaderID: a.UploaderID,
+		Name:       a.Name,
+		Size:       a.Size,
+		MIMEType:   a.MIMEType,
+		SHA256:     hex.EncodeToString(a.SHA256),
+		Created:    a.Created,
+	}
+}
+
+// attachmentEndpoints регистрирует обработчики вложений задач
+func (api *API) attachmentEndpoints() {
+	api.router.Handle("GET /tasks/{id}/attachments", api.protected(api.listAttachments))
+	api.router.Handle("POST /tasks/{id}/attachments", api.protected(api.uploadAttachment))
+	api.router.Handle("GET /attachments/{id}", api.protected(api.getAttachment))
+	api.router.Handle("GET /attachments/{id}/content", api.protected(api.downloadAttachment))
+	api.router.Handle("DELETE /attachments/{id}", api.protected(api.deleteAttachment))
+}
+
+// listAttachments возвращает метаданные вложений задачи
+func (api *API) listAttachments(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	attachments, err := db.SelectAttachments(id)
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	resp := []attachmentResponse{}
+	for _, a := range attachments {
+		resp = append(resp, newAttachmentResponse(a))
+	}
+	api.writeJSON(w, r, http.StatusOK, resp)
+}
+
+// uploadAttachment сохраняет тело запроса как вложение задачи и возвращает его метаданные
+// Имя файла берется из параметра name или из filename заголовка Content-Disposition,
+// MIME-тип - из Content-Type (без него тип определяется по содержимому)
+// Тело передается в хранилище потоком, не накапливаясь в памяти
+func (api *API) uploadAttachment(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	a := model.Attachment{TaskID: id, Name: r.URL.Query().Get("name"), MIMEType: r.Header.Get("Content-Type")}
+	if a.Name == "" {
+		if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Disposition")); err == nil {
+			a.Name = params["filename"]
+		}
+	}
+	if user, ok := auth.UserFromContext(r.Context()); ok {
+		a.UploaderID = user.ID
+	}
+
+	var err error
+	if a.ID, err = db.NewAttachment(a, r.Body); err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	if a, err = db.SelectAttachmentByID(a.ID); err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusCreated, newAttachmentResponse(a))
+}
+
+// getAttachment возвращает метаданные вложения
+func (api *API) getAttachment(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	a, err := db.SelectAttachmentByID(id)
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, newAttachmentResponse(a))
+}
+
+// downloadAttachment отправляет содержимое вложения потоком
+// Файл всегда отдается для скачивания (Content-Disposition: attachment), чтобы загруженные страницы
+// не открывались в браузере от имени сервиса; ETag - хеш SHA-256 содержимого
+func (api *API) downloadAttachment(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	a, content, err := db.OpenAttachment(id)
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	defer content.Close()
+
+	etag := `"` + hex.EncodeToString(a.SHA256) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", a.MIMEType)
+	w.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if _, err := io.Copy(w, content); err != nil {
+		api.logger.WarnContext(r.Context(), "Ошибка при отправке вложения",
+			slog.Int("attachment_id", id), slog.Any("error", err))
+	}
+}
+
+// deleteAttachment удаляет вложение и его содержимое
+func (api *API) deleteAttachment(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	if err := db.DeleteAttachment(id); err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
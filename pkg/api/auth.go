@@ -0,0 +1,115 @@
+package api
+
+import (
+	"DB_Apps/pkg/auth"
+	"DB_Apps/pkg/model"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type loginRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+type tokenResponse struct {
+	Token     string     `json:"token"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type apiTokenRequest struct {
+	Name string `json:"name"`
+	// Срок действия в секундах, 0 - без ограничения
+	TTLSeconds int `json:"ttl_seconds"`
+}
+
+type userResponse struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Login       string `json:"login,omitempty"`
+	Email       string `json:"email,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Active      bool   `json:"active"`
+	Role        string `json:"role"`
+}
+
+func newUserResponse(u model.User) userResponse {
+	return userResponse{
+		ID:          u.ID,
+		Name:        u.Name,
+		Login:       u.Login,
+		Email:       u.Email,
+		DisplayName: u.DisplayName,
+		Active:      u.Active,
+		Role:        string(u.Role),
+	}
+}
+
+// authEndpoints регистрирует обработчики аутентификации
+func (api *API) authEndpoints() {
+	api.router.HandleFunc("POST /auth/login", api.login)
+	api.router.Handle("POST /auth/logout", api.auth.Middleware(http.HandlerFunc(api.logout)))
+	api.router.Handle("GET /auth/me", api.auth.Middleware(http.HandlerFunc(api.me)))
+	api.router.Handle("POST /auth/tokens", api.auth.Middleware(http.HandlerFunc(api.newAPIToken)))
+}
+
+// login - вход по логину и паролю, возвращает токен входа
+func (api *API) login(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.writeError(w, r, http.StatusBadRequest, errors.New("Некорректное тело запроса"))
+		return
+	}
+	token, expires, user, err := api.auth.Login(r.Context(), req.Login, req.Password)
+	if err != nil {
+		if errors.Is(err, auth.InvalidCredentialsErr) || errors.Is(err, auth.UserInactiveErr) {
+			api.writeError(w, r, http.StatusUnauthorized, err)
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	api.logger.InfoContext(r.Context(), "Вход пользователя", slog.Int("user_id", user.ID))
+	api.writeJSON(w, r, http.StatusOK, tokenResponse{Token: token, ExpiresAt: &expires})
+}
+
+// logout отзывает токен, с которым выполнен запрос
+func (api *API) logout(w http.ResponseWriter, r *http.Request) {
+	token, _ := auth.BearerToken(r)
+	if err := api.auth.Revoke(r.Context(), token); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// me возвращает текущего пользователя
+func (api *API) me(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+	api.writeJSON(w, r, http.StatusOK, newUserResponse(user))
+}
+
+// newAPIToken выдает текущему пользователю токен для программного доступа
+func (api *API) newAPIToken(w http.ResponseWriter, r *http.Request) {
+	var req apiTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TTLSeconds < 0 {
+		api.writeError(w, r, http.StatusBadRequest, errors.New("Некорректное тело запроса"))
+		return
+	}
+	user, _ := auth.UserFromContext(r.Context())
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	token, err := api.auth.IssueAPIToken(r.Context(), user.ID, req.Name, ttl)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	resp := tokenResponse{Token: token}
+	if ttl > 0 {
+		expires := time.Now().Add(ttl)
+		resp.ExpiresAt = &expires
+	}
+	api.writeJSON(w, r, http.StatusCreated, resp)
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"DB_Apps/pkg/model"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+type columnBody struct {
+	ID      int    `json:"id,omitempty"`
+	Name    string `json:"name"`
+	Status  string `json:"status,omitempty"`
+	LabelID int    `json:"label_id,omitempty"`
+}
+
+type boardBody struct {
+	ID      int          `json:"id,omitempty"`
+	Name    string       `json:"name"`
+	Kind    string       `json:"kind"`
+	Columns []columnBody `json:"columns"`
+}
+
+type moveRequest struct {
+	TaskID   int `json:"task_id"`
+	ColumnID int `json:"column_id"`
+	AfterID  int `json:"after_id"`
+}
+
+type taskResponse struct {
+	ID         int        `json:"id"`
+	Title      string     `json:"title"`
+	Content    string     `json:"content,omitempty"`
+	AuthorID   int        `json:"author_id"`
+	AssignedID int        `json:"assigned_id"`
+	Opened     time.Time  `json:"opened"`
+	Closed     *time.Time `json:"closed,omitempty"`
+	// Оценка в минутах, 0 - не задана
+	EstimateMinutes float64 `json:"estimate_minutes,omitempty"`
+}
+
+type cardResponse struct {
+	taskResponse
+	Rank string `json:"rank,omitempty"`
+}
+
+// Колонка доски с задачами в порядке рангов
+type columnResponse struct {
+	columnBody
+	Tasks []cardResponse `json:"tasks"`
+}
+
+type boardResponse struct {
+	ID      int              `json:"id"`
+	Name    string           `json:"name"`
+	Kind    string           `json:"kind"`
+	Columns []columnResponse `json:"columns"`
+}
+
+func newTaskResponse(t model.Task) taskResponse {
+	return taskResponse{
+		ID:         t.ID,
+		Title:      t.Title,
+		Content:    t.Content,
+		AuthorID:   t.AuthorID,
+		AssignedID: t.AssignedID,
+		Opened:     t.Opened,
+		Closed:     t.Closed,
+
+		EstimateMinutes: t.Estimate.Minutes(),
+	}
+}
+
+func newBoardBody(b model.Board) boardBody {
+	body := boardBody{ID: b.ID, Name: b.Name, Kind: string(b.Kind), Columns: []columnBody{}}
+	for _, c := range b.Columns {
+		body.Columns = append(body.Columns, columnBody{ID: c.ID, Name: c.Name, Status: string(c.Status), LabelID: c.LabelID})
+	}
+	return body
+}
+
+func (b boardBody) board() model.Board {
+	board := model.Board{ID: b.ID, Name: b.Name, Kind: model.BoardKind(b.Kind)}
+	for _, c := range b.Columns {
+		board.Columns = append(board.Columns,
+			model.BoardColumn{ID: c.ID, Name: c.Name, Status: model.TaskState(c.Status), LabelID: c.LabelID})
+	}
+	return board
+}
+
+// boardEndpoints регистрирует обработчики досок задач проекта
+func (api *API) boardEndpoints() {
+	api.router.Handle("GET /boards", api.protected(api.listBoards))
+	api.router.Handle("POST /boards", api.protected(api.createBoard))
+	api.router.Handle("GET /boards/{id}", api.protected(api.getBoard))
+	api.router.Handle("PUT /boards/{id}", api.protected(api.updateBoard))
+	api.router.Handle("DELETE /boards/{id}", api.protected(api.deleteBoard))
+	api.router.Handle("POST /boards/{id}/moves", api.protected(api.moveTask))
+}
+
+// listBoards возвращает доски проекта с колонками без задач
+func (api *API) listBoards(w http.ResponseWriter, r *http.Request) {
+	db, err := api.storage(r)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	boards, err := db.SelectBoards()
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	resp := []boardBody{}
+	for _, b := range boards {
+		resp = append(resp, newBoardBody(b))
+	}
+	api.writeJSON(w, r, http.StatusOK, resp)
+}
+
+// createBoard создает доску с колонками и возвращает ее
+func (api *API) createBoard(w http.ResponseWriter, r *http.Request) {
+	db, err := api.storage(r)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	var req boardBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.writeError(w, r, http.StatusBadRequest, errors.New("Некорректное тело запроса"))
+		return
+	}
+	id, err := db.NewBoard(req.board())
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	board, err := db.SelectBoardByID(id)
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusCreated, newBoardBody(board))
+}
+
+// getBoard возвращает доску с задачами по колонкам
+func (api *API) getBoard(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	board, err := db.SelectBoardByID(id)
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	cards, err := db.SelectBoardCards(id)
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+
+	resp := boardResponse{ID: board.ID, Name: board.Name, Kind: string(board.Kind), Columns: []columnResponse{}}
+	for _, c := range newBoardBody(board).Columns {
+		column := columnResponse{columnBody: c, Tasks: []cardResponse{}}
+		for _, card := range cards {
+			if card.ColumnID == c.ID {
+				column.Tasks = append(column.Tasks, cardResponse{taskResponse: newTaskResponse(card.Task), Rank: card.Rank})
+			}
+		}
+		resp.Columns = append(resp.Columns, column)
+	}
+	api.writeJSON(w, r, http.StatusOK, resp)
+}
+
+// updateBoard изменяет название и колонки доски и возвращает ее
+func (api *API) updateBoard(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	var req boardBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.writeError(w, r, http.StatusBadRequest, errors.New("Некорректное тело запроса"))
+		return
+	}
+	req.ID = id
+	if err := db.UpdateBoard(req.board()); err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	board, err := db.SelectBoardByID(id)
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, newBoardBody(board))
+}
+
+// deleteBoard удаляет доску, задачи не меняются
+func (api *API) deleteBoard(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	if err := db.DeleteBoard(id); err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// moveTask перемещает задачу в колонку доски после задачи after_id (0 - в начало колонки)
+func (api *API) moveTask(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	var req moveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.writeError(w, r, http.StatusBadRequest, errors.New("Некорректное тело запроса"))
+		return
+	}
+	err := db.MoveBoardTask(model.BoardMove{BoardID: id, TaskID: req.TaskID, ColumnID: req.ColumnID, AfterID: req.AfterID})
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
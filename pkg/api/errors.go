@@ -0,0 +1,37 @@
+package api
+
+import (
+	"DB_Apps/pkg/access"
+	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/storage/postgresql"
+	"context"
+	"errors"
+	"net/http"
+)
+
+// statusCode возвращает код ответа HTTP для ошибки хранилища
+func statusCode(err error) int {
+	var partial myerrors.TaskPartialErr
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, myerrors.NotFoundErr), errors.Is(err, postgresql.LabelOrTaskNotExistErr):
+		return http.StatusNotFound
+	case errors.Is(err, access.ForbiddenErr):
+		return http.StatusForbidden
+	case errors.Is(err, postgresql.DuplicateLabelIDErr):
+		return http.StatusConflict
+	case errors.Is(err, postgresql.AttachmentSizeErr):
+		return http.StatusRequestEntityTooLarge
+	case postgresql.ErrorCategory(err) == postgresql.CategoryValidation, errors.As(err, &partial):
+		return http.StatusBadRequest
+	case errors.Is(err, postgresql.NotProjectMemberErr):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// writeStorageError отправляет ошибку хранилища с кодом statusCode(err)
+func (api *API) writeStorageError(w http.ResponseWriter, r *http.Request, err error) {
+	api.writeError(w, r, statusCode(err), err)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"DB_Apps/pkg/storage"
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// ReadinessOptions - параметры проверки готовности
+type ReadinessOptions struct {
+	// Время ожидания ответа БД
+	Timeout time.Duration
+	// Максимально допустимое отставание реплики, 0 - не проверяется
+	MaxReplicationLag time.Duration
+}
+
+var DefaultReadinessOptions = ReadinessOptions{
+	Timeout: 2 * time.Second,
+}
+
+// Ответ /readyz
+// FailedCheck - название непройденной проверки, подробности ошибки записываются только в журнал
+type readinessResponse struct {
+	Status           string       `json:"status"`
+	FailedCheck      string       `json:"failed_check,omitempty"`
+	ServerVersion    string       `json:"server_version,omitempty"`
+	MigrationVersion int          `json:"migration_version"`
+	InRecovery       bool         `json:"in_recovery"`
+	ReplicationLag   float64      `json:"replication_lag_seconds"`
+	Pool             poolResponse `json:"pool"`
+}
+
+type poolResponse struct {
+	TotalConns             int32   `json:"total_conns"`
+	IdleConns              int32   `json:"idle_conns"`
+	AcquiredConns          int32   `json:"acquired_conns"`
+	MaxConns               int32   `json:"max_conns"`
+	AcquireCount           int64   `json:"acquire_count"`
+	AcquireDurationSeconds float64 `json:"acquire_duration_seconds"`
+	EmptyAcquireCount      int64   `json:"empty_acquire_count"`
+}
+
+// healthz - проверка живости: процесс запущен и обрабатывает запросы
+// Состояние БД не проверяется, чтобы недоступность БД не приводила к перезапуску сервиса
+func (api *API) healthz(w http.ResponseWriter, r *http.Request) {
+	api.writeJSON(w, r, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyz - проверка готовности: БД отвечает на запросы, а отставание реплики в допустимых пределах
+// В ответе возвращаются диагностические сведения о подключении
+// Текст ошибок БД в ответ не попадает: проверка доступна без аутентификации
+// Если сервис не готов, то возвращается код 503
+func (api *API) readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), api.Readiness.Timeout)
+	defer cancel()
+	db := api.db.WithContext(ctx)
+
+	if err := db.Ping(); err != nil {
+		api.logger.WarnContext(ctx, "БД недоступна", slog.Any("error", err))
+		api.writeJSON(w, r, http.StatusServiceUnavailable, readinessResponse{Status: "unavailable", FailedCheck: "database"})
+		return
+	}
+
+	d, err := db.Diagnostics()
+	resp := newReadinessResponse(d)
+	status := http.StatusOK
+	switch {
+	case err != nil:
+		// БД отвечает, но диагностику получить не удалось: сервис готов, ошибка записывается в журнал
+		api.logger.WarnContext(ctx, "Ошибка при получении диагностики БД", slog.Any("error", err))
+		resp.FailedCheck = "diagnostics"
+	case api.Readiness.MaxReplicationLag > 0 && d.ReplicationLag > api.Readiness.MaxReplicationLag:
+		resp.Status = "lagging"
+		status = http.StatusServiceUnavailable
+	}
+	api.writeJSON(w, r, status, resp)
+}
+
+func newReadinessResponse(d storage.Diagnostics) readinessResponse {
+	return readinessResponse{
+		Status:           "ok",
+		ServerVersion:    d.ServerVersion,
+		MigrationVersion: d.MigrationVersion,
+		InRecovery:       d.InRecovery,
+		ReplicationLag:   d.ReplicationLag.Seconds(),
+		Pool: poolResponse{
+			TotalConns:             d.Pool.TotalConns,
+			IdleConns:              d.Pool.IdleConns,
+			AcquiredConns:          d.Pool.AcquiredConns,
+			MaxConns:               d.Pool.MaxConns,
+			AcquireCount:           d.Pool.AcquireCount,
+			AcquireDurationSeconds: d.Pool.AcquireDuration.Seconds(),
+			EmptyAcquireCount:      d.Pool.EmptyAcquireCount,
+		},
+	}
+}
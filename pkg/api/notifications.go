@@ -0,0 +1,190 @@
+package api
+
+import (
+	"DB_Apps/pkg/auth"
+	"DB_Apps/pkg/model"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type notificationResponse struct {
+	ID        int        `json:"id"`
+	ProjectID int        `json:"project_id"`
+	TaskID    int        `json:"task_id"`
+	ActorID   int        `json:"actor_id"`
+	Kind      string     `json:"kind"`
+	TaskTitle string     `json:"task_title"`
+	Text      string     `json:"text"`
+	Created   time.Time  `json:"created"`
+	Read      *time.Time `json:"read,omitempty"`
+}
+
+func newNotificationResponse(n model.Notification) notificationResponse {
+	return notificationResponse{
+		ID:        n.ID,
+		ProjectID: n.ProjectID,
+		TaskID:    n.TaskID,
+		ActorID:   n.ActorID,
+		Kind:      string(n.Kind),
+		TaskTitle: n.TaskTitle,
+		Text:      n.Text,
+		Created:   n.Created,
+		Read:      n.Read,
+	}
+}
+
+// notificationEndpoints регистрирует обработчики входящих уведомлений и подписок на задачи
+func (api *API) notificationEndpoints() {
+	api.router.Handle("GET /notifications", api.protected(api.listNotifications))
+	api.router.Handle("POST /notifications/{id}/read", api.protected(api.readNotification))
+	api.router.Handle("POST /notifications/read-all", api.protected(api.readAllNotifications))
+	api.router.Handle("GET /tasks/{id}/watchers", api.protected(api.listWatchers))
+	api.router.Handle("PUT /tasks/{id}/watchers/me", api.protected(api.watchTask))
+	api.router.Handle("DELETE /tasks/{id}/watchers/me", api.protected(api.unwatchTask))
+}
+
+// currentUserID возвращает ID текущего пользователя
+// Без аутентификации пользователь задается параметром user_id
+func currentUserID(r *http.Request) (int, error) {
+	if user, ok := auth.UserFromContext(r.Context()); ok {
+		return user.ID, nil
+	}
+	id, err := strconv.Atoi(r.URL.Query().Get("user_id"))
+	if err != nil {
+		return 0, errors.New("Не задан пользователь: параметр user_id")
+	}
+	return id, nil
+}
+
+// listNotifications возвращает уведомления текущего пользователя, начиная с новых
+// Параметры: unread=true - только непрочитанные, limit - наибольшее количество
+func (api *API) listNotifications(w http.ResponseWriter, r *http.Request) {
+	db, err := api.storage(r)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	q := model.NotificationQuery{}
+	if q.UserID, err = currentUserID(r); err != nil {
+		api.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	query := r.URL.Query()
+	if value := query.Get("unread"); value != "" {
+		if q.UnreadOnly, err = strconv.ParseBool(value); err != nil {
+			api.writeError(w, r, http.StatusBadRequest, errors.New("Некорректный параметр unread"))
+			return
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if q.Limit, err = strconv.Atoi(value); err != nil || q.Limit < 0 {
+			api.writeError(w, r, http.StatusBadRequest, errors.New("Некорректный параметр limit"))
+			return
+		}
+	}
+
+	notifications, err := db.SelectNotifications(q)
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	resp := []notificationResponse{}
+	for _, n := range notifications {
+		resp = append(resp, newNotificationResponse(n))
+	}
+	api.writeJSON(w, r, http.StatusOK, resp)
+}
+
+// readNotification отмечает уведомление текущего пользователя прочитанным
+func (api *API) readNotification(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	userID, err := currentUserID(r)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := db.MarkNotificationRead(userID, id); err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readAllNotifications отмечает все уведомления текущего пользователя прочитанными и возвращает их количество
+func (api *API) readAllNotifications(w http.ResponseWriter, r *http.Request) {
+	db, err := api.storage(r)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	userID, err := currentUserID(r)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	n, err := db.MarkAllNotificationsRead(userID)
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusOK, map[string]int{"marked": n})
+}
+
+// listWatchers возвращает пользователей, подписанных на задачу
+func (api *API) listWatchers(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	users, err := db.SelectTaskWatchers(id)
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	resp := []userResponse{}
+	for _, u := range users {
+		resp = append(resp, newUserResponse(u))
+	}
+	api.writeJSON(w, r, http.StatusOK, resp)
+}
+
+// watchTask подписывает текущего пользователя на изменения задачи
+func (api *API) watchTask(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	userID, err := currentUserID(r)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := db.WatchTask(id, userID); err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unwatchTask отменяет подписку текущего пользователя на изменения задачи
+func (api *API) unwatchTask(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	userID, err := currentUserID(r)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := db.UnwatchTask(id, userID); err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
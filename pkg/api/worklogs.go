@@ -0,0 +1,194 @@
+package api
+
+import (
+	"DB_Apps/pkg/auth"
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/reports"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Тело запроса на запись времени: начало и конец работы либо продолжительность
+type worklogRequest struct {
+	// Пользователь, по умолчанию - текущий
+	UserID          int        `json:"user_id"`
+	Started         *time.Time `json:"started"`
+	Ended           *time.Time `json:"ended"`
+	DurationMinutes int        `json:"duration_minutes"`
+	Comment         string     `json:"comment"`
+}
+
+type worklogResponse struct {
+	ID              int       `json:"id"`
+	TaskID          int       `json:"task_id"`
+	UserID          int       `json:"user_id"`
+	Started         time.Time `json:"started"`
+	DurationMinutes float64   `json:"duration_minutes"`
+	Comment         string    `json:"comment,omitempty"`
+}
+
+type estimateRequest struct {
+	// Оценка в минутах, 0 - снять оценку
+	EstimateMinutes int `json:"estimate_minutes"`
+}
+
+func newWorklogResponse(w model.Worklog) worklogResponse {
+	return worklogResponse{
+		ID:              w.ID,
+		TaskID:          w.TaskID,
+		UserID:          w.UserID,
+		Started:         w.Started,
+		DurationMinutes: w.Duration.Minutes(),
+		Comment:         w.Comment,
+	}
+}
+
+// worklog возвращает запись о времени по задаче taskID
+// Продолжительность задается либо концом работы, либо в минутах
+func (req worklogRequest) worklog(r *http.Request, taskID int) (model.Worklog, error) {
+	w := model.Worklog{TaskID: taskID, UserID: req.UserID, Comment: req.Comment,
+		Duration: time.Duration(req.DurationMinutes) * time.Minute}
+	if req.Started != nil {
+		w.Started = *req.Started
+	}
+	if req.Ended != nil {
+		if req.Started == nil || req.DurationMinutes != 0 {
+			return w, errors.New("Конец работы задается вместе с началом и без продолжительности")
+		}
+		w.Duration = req.Ended.Sub(*req.Started)
+	}
+	if w.UserID == 0 {
+		if user, ok := auth.UserFromContext(r.Context()); ok {
+			w.UserID = user.ID
+		}
+	}
+	return w, nil
+}
+
+// worklogEndpoints регистрирует обработчики учета времени
+func (api *API) worklogEndpoints() {
+	api.router.Handle("GET /tasks/{id}/worklogs", api.protected(api.listWorklogs))
+	api.router.Handle("POST /tasks/{id}/worklogs", api.protected(api.createWorklog))
+	api.router.Handle("DELETE /worklogs/{id}", api.protected(api.deleteWorklog))
+	api.router.Handle("PUT /tasks/{id}/estimate", api.protected(api.setEstimate))
+	api.router.Handle("GET /timesheet", api.protected(api.timesheet))
+}
+
+// listWorklogs возвращает записи о времени по задаче
+func (api *API) listWorklogs(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	worklogs, err := db.SelectWorklogs(id)
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	resp := []worklogResponse{}
+	for _, wl := range worklogs {
+		resp = append(resp, newWorklogResponse(wl))
+	}
+	api.writeJSON(w, r, http.StatusOK, resp)
+}
+
+// createWorklog добавляет запись о времени по задаче и возвращает ее
+func (api *API) createWorklog(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	var req worklogRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.writeError(w, r, http.StatusBadRequest, errors.New("Некорректное тело запроса"))
+		return
+	}
+	wl, err := req.worklog(r, id)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if wl.ID, err = db.NewWorklog(wl); err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	if wl, err = db.SelectWorklogByID(wl.ID); err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	api.writeJSON(w, r, http.StatusCreated, newWorklogResponse(wl))
+}
+
+// deleteWorklog удаляет запись о времени
+func (api *API) deleteWorklog(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	if err := db.DeleteWorklog(id); err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setEstimate задает оценку задачи
+func (api *API) setEstimate(w http.ResponseWriter, r *http.Request) {
+	db, id, ok := api.storageWithID(w, r)
+	if !ok {
+		return
+	}
+	var req estimateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.writeError(w, r, http.StatusBadRequest, errors.New("Некорректное тело запроса"))
+		return
+	}
+	if err := db.SetTaskEstimate(id, time.Duration(req.EstimateMinutes)*time.Minute); err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// timesheet выгружает табель за дни [from, to] (ГГГГ-ММ-ДД в часовом поясе API) в формате CSV
+// Параметр user_id ограничивает табель одним пользователем
+func (api *API) timesheet(w http.ResponseWriter, r *http.Request) {
+	db, err := api.storage(r)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	query := r.URL.Query()
+	from, errFrom := time.ParseInLocation(time.DateOnly, query.Get("from"), api.Location)
+	to, errTo := time.ParseInLocation(time.DateOnly, query.Get("to"), api.Location)
+	if errFrom != nil || errTo != nil {
+		api.writeError(w, r, http.StatusBadRequest, errors.New("Параметры from и to задаются в формате ГГГГ-ММ-ДД"))
+		return
+	}
+	userID := 0
+	if value := query.Get("user_id"); value != "" {
+		if userID, err = strconv.Atoi(value); err != nil {
+			api.writeError(w, r, http.StatusBadRequest, errors.New("Некорректный параметр user_id"))
+			return
+		}
+	}
+
+	entries, err := reports.New(db, api.Location).Timesheet(from, to.AddDate(0, 0, 1), userID)
+	if errors.Is(err, reports.RangeErr) {
+		api.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		api.writeStorageError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="timesheet.csv"`)
+	if err := reports.TimesheetCSV(w, entries); err != nil {
+		api.logger.WarnContext(r.Context(), "Ошибка при отправке табеля", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,163 @@
+// Пакет app управляет жизненным циклом сервиса:
+// запуск компонентов по порядку, ожидание сигнала завершения и остановка в обратном порядке
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Время на остановку компонентов по умолчанию
+const DefaultShutdownTimeout = 15 * time.Second
+
+// Hook - компонент приложения с функциями запуска и остановки
+// Любая из функций может быть nil
+type Hook struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// App - контейнер компонентов сервиса
+// Компоненты запускаются в порядке добавления и останавливаются в обратном,
+// поэтому ресурсы, от которых зависят остальные (например пул соединений с БД), нужно добавлять первыми
+type App struct {
+	logger          *slog.Logger
+	shutdownTimeout time.Duration
+
+	hooks   []Hook
+	started int
+
+	mu     sync.Mutex
+	err    error
+	cancel context.CancelFunc
+}
+
+// New создает пустой контейнер
+// shutdownTimeout - общее время на остановку всех компонентов, 0 - DefaultShutdownTimeout
+func New(logger *slog.Logger, shutdownTimeout time.Duration) *App {
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = DefaultShutdownTimeout
+	}
+	return &App{logger: logger, shutdownTimeout: shutdownTimeout}
+}
+
+// Append добавляет компонент
+func (a *App) Append(h Hook) {
+	a.hooks = append(a.hooks, h)
+}
+
+// AddWorker добавляет фоновую задачу, которая выполняется в отдельной горутине до отмены ctx
+// При остановке контекст задачи отменяется, и App ждет ее завершения, но не дольше времени на остановку
+// Если задача завершилась с ошибкой, то приложение начинает остановку, а Run возвращает эту ошибку
+func (a *App) AddWorker(name string, run func(ctx context.Context) error) {
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+	a.Append(Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			go func() {
+				defer close(done)
+				if err := run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+					a.fail(fmt.Errorf("%s: %w", name, err))
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return fmt.Errorf("Фоновая задача не завершилась вовремя: %w", ctx.Err())
+			}
+		},
+	})
+}
+
+// Start запускает компоненты по порядку
+// Если компонент не запустился, то уже запущенные останавливаются и возвращается ошибка
+func (a *App) Start(ctx context.Context) error {
+	for _, h := range a.hooks {
+		if h.Start != nil {
+			a.logger.InfoContext(ctx, "Запуск компонента", slog.String("component", h.Name))
+			if err := h.Start(ctx); err != nil {
+				stopErr := a.Stop(context.Background())
+				return errors.Join(fmt.Errorf("Ошибка запуска %s: %w", h.Name, err), stopErr)
+			}
+		}
+		a.started++
+	}
+	return nil
+}
+
+// Stop останавливает запущенные компоненты в обратном порядке
+// Ошибка одного компонента не прерывает остановку остальных, все ошибки объединяются
+func (a *App) Stop(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, a.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for ; a.started > 0; a.started-- {
+		h := a.hooks[a.started-1]
+		if h.Stop == nil {
+			continue
+		}
+		a.logger.InfoContext(ctx, "Остановка компонента", slog.String("component", h.Name))
+		if err := h.Stop(ctx); err != nil {
+			a.logger.ErrorContext(ctx, "Ошибка остановки компонента",
+				slog.String("component", h.Name), slog.Any("error", err))
+			errs = append(errs, fmt.Errorf("Ошибка остановки %s: %w", h.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run запускает компоненты, ждет отмены ctx (например по сигналу SIGTERM) или ошибки фоновой задачи
+// и останавливает компоненты
+// Возвращает ошибку фоновой задачи и ошибки остановки
+func (a *App) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	a.mu.Lock()
+	a.cancel = cancel
+	a.mu.Unlock()
+
+	if err := a.Start(ctx); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	a.logger.Info("Остановка приложения")
+
+	stopErr := a.Stop(context.Background())
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return errors.Join(a.err, stopErr)
+}
+
+// Shutdown начинает остановку приложения, запущенного через Run
+func (a *App) Shutdown() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cancel != nil {
+		a.cancel()
+	}
+}
+
+// fail запоминает первую ошибку фоновой задачи и начинает остановку
+func (a *App) fail(err error) {
+	a.mu.Lock()
+	if a.err == nil {
+		a.err = err
+	}
+	a.mu.Unlock()
+	a.Shutdown()
+}
@@ -0,0 +1,247 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// events - журнал вызовов функций компонентов
+type events struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (e *events) add(s string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.list = append(e.list, s)
+}
+
+func (e *events) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.list...)
+}
+
+// hook возвращает компонент, который записывает запуск и остановку в e
+// и возвращает из них ошибки startErr и stopErr
+func (e *events) hook(name string, startErr, stopErr error) Hook {
+	return Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			e.add("start " + name)
+			return startErr
+		},
+		Stop: func(context.Context) error {
+			e.add("stop " + name)
+			return stopErr
+		},
+	}
+}
+
+func newTestApp(timeout time.Duration) *App {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), timeout)
+}
+
+func TestStartStopOrder(t *testing.T) {
+	var e events
+	a := newTestApp(time.Second)
+	a.Append(e.hook("pool", nil, nil))
+	a.Append(e.hook("scheduler", nil, nil))
+	a.Append(Hook{Name: "no-op"})
+	a.Append(e.hook("http", nil, nil))
+
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"start pool", "start scheduler", "start http", "stop http", "stop scheduler", "stop pool"}
+	if got := e.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("вызовы %v, want %v", got, want)
+	}
+
+	// Повторная остановка ничего не делает
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.get(); len(got) != len(want) {
+		t.Errorf("повторный Stop остановил компоненты: %v", got)
+	}
+}
+
+func TestStartFailureRollsBack(t *testing.T) {
+	var e events
+	startErr := errors.New("порт занят")
+	a := newTestApp(time.Second)
+	a.Append(e.hook("pool", nil, nil))
+	a.Append(e.hook("scheduler", nil, nil))
+	a.Append(e.hook("http", startErr, nil))
+	a.Append(e.hook("grpc", nil, nil))
+
+	err := a.Start(context.Background())
+	if !errors.Is(err, startErr) {
+		t.Fatalf("Start() error = %v, want %v", err, startErr)
+	}
+
+	// Не запустившийся компонент не останавливается, следующие не запускаются
+	want := []string{"start pool", "start scheduler", "start http", "stop scheduler", "stop pool"}
+	if got := e.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("вызовы %v, want %v", got, want)
+	}
+}
+
+func TestStartFailureJoinsStopErrors(t *testing.T) {
+	var e events
+	startErr := errors.New("порт занят")
+	stopErr := errors.New("не остановлен")
+	a := newTestApp(time.Second)
+	a.Append(e.hook("pool", nil, stopErr))
+	a.Append(e.hook("http", startErr, nil))
+
+	err := a.Start(context.Background())
+	if !errors.Is(err, startErr) || !errors.Is(err, stopErr) {
+		t.Errorf("Start() error = %v, want %v и %v", err, startErr, stopErr)
+	}
+}
+
+func TestStopContinuesAfterError(t *testing.T) {
+	var e events
+	stopErr := errors.New("не остановлен")
+	a := newTestApp(time.Second)
+	a.Append(e.hook("pool", nil, nil))
+	a.Append(e.hook("http", nil, stopErr))
+	a.Append(e.hook("grpc", nil, nil))
+
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Stop(context.Background()); !errors.Is(err, stopErr) {
+		t.Errorf("Stop() error = %v, want %v", err, stopErr)
+	}
+	want := []string{"start pool", "start http", "start grpc", "stop grpc", "stop http", "stop pool"}
+	if got := e.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("вызовы %v, want %v", got, want)
+	}
+}
+
+func TestStopDeadline(t *testing.T) {
+	var e events
+	timeout := 50 * time.Millisecond
+	a := newTestApp(timeout)
+	a.Append(e.hook("pool", nil, nil))
+	// Компонент, который завершается только по истечении контекста остановки
+	a.Append(Hook{
+		Name: "slow",
+		Stop: func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Error("контекст остановки без срока")
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	begin := time.Now()
+	err := a.Stop(context.Background())
+	if elapsed := time.Since(begin); elapsed > timeout+time.Second {
+		t.Errorf("Stop() длился %v, want около %v", elapsed, timeout)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop() error = %v, want DeadlineExceeded", err)
+	}
+	// Пул закрывается даже после истечения срока остановки
+	want := []string{"start pool", "stop pool"}
+	if got := e.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("вызовы %v, want %v", got, want)
+	}
+}
+
+func TestDefaultShutdownTimeout(t *testing.T) {
+	if a := newTestApp(0); a.shutdownTimeout != DefaultShutdownTimeout {
+		t.Errorf("shutdownTimeout = %v, want %v", a.shutdownTimeout, DefaultShutdownTimeout)
+	}
+}
+
+func TestRunShutdown(t *testing.T) {
+	var e events
+	a := newTestApp(time.Second)
+	a.Append(e.hook("pool", nil, nil))
+	a.AddWorker("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	a.Append(Hook{
+		Name: "http",
+		Start: func(context.Context) error {
+			e.add("start http")
+			a.Shutdown()
+			return nil
+		},
+		Stop: func(context.Context) error {
+			e.add("stop http")
+			return nil
+		},
+	})
+
+	if err := a.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := []string{"start pool", "start http", "stop http", "stop pool"}
+	if got := e.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("вызовы %v, want %v", got, want)
+	}
+}
+
+func TestRunWorkerFailure(t *testing.T) {
+	var e events
+	workerErr := errors.New("сбой планировщика")
+	a := newTestApp(time.Second)
+	a.Append(e.hook("pool", nil, nil))
+	a.AddWorker("scheduler", func(context.Context) error {
+		return workerErr
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- a.Run(context.Background()) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, workerErr) {
+			t.Errorf("Run() error = %v, want %v", err, workerErr)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() не завершился после ошибки фоновой задачи")
+	}
+	want := []string{"start pool", "stop pool"}
+	if got := e.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("вызовы %v, want %v", got, want)
+	}
+}
+
+func TestWorkerStopDeadline(t *testing.T) {
+	a := newTestApp(50 * time.Millisecond)
+	release := make(chan struct{})
+	defer close(release)
+	a.AddWorker("stuck", func(context.Context) error {
+		<-release
+		return nil
+	})
+
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Stop(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop() error = %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc"
+)
+
+// GRPCServer возвращает компонент gRPC-сервера srv на адресе addr
+// При запуске занимает адрес (ошибка занятого порта возвращается сразу),
+// при остановке ждет завершения обрабатываемых вызовов, а по истечении ctx прерывает их
+func (a *App) GRPCServer(name, addr string, srv *grpc.Server) Hook {
+	return Hook{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			a.logger.InfoContext(ctx, "gRPC-сервер запущен",
+				slog.String("component", name), slog.String("addr", ln.Addr().String()))
+			go func() {
+				if err := srv.Serve(ln); err != nil {
+					a.fail(err)
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				srv.Stop()
+				return ctx.Err()
+			}
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+)
+
+// HTTPServer возвращает компонент HTTP-сервера srv
+// При запуске занимает адрес srv.Addr (ошибка занятого порта возвращается сразу),
+// при остановке перестает принимать соединения и ждет завершения обрабатываемых запросов
+func (a *App) HTTPServer(name string, srv *http.Server) Hook {
+	return Hook{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			a.logger.InfoContext(ctx, "HTTP-сервер запущен",
+				slog.String("component", name), slog.String("addr", ln.Addr().String()))
+			go func() {
+				if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					a.fail(err)
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			return srv.Shutdown(ctx)
+		},
+	}
+}
@@ -0,0 +1,186 @@
+// Пакет auth отвечает за аутентификацию пользователей:
+// хеширование паролей, вход по логину и паролю, выдачу, проверку и отзыв токенов
+package auth
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/storage"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Ошибки аутентификации
+var (
+	InvalidCredentialsErr = errors.New("Неверный логин или пароль")
+	InvalidTokenErr       = errors.New("Недействительный токен")
+	TokenExpiredErr       = errors.New("Срок действия токена истек")
+	TokenRevokedErr       = errors.New("Токен отозван")
+	UserInactiveErr       = errors.New("Пользователь заблокирован")
+)
+
+// Срок действия токена входа по умолчанию
+const DefaultSessionTTL = 24 * time.Hour
+
+// Минимальная длина ключа подписи токенов в байтах
+const MinKeyLength = 32
+
+// Service выполняет аутентификацию поверх storage.Interface
+type Service struct {
+	db         storage.Interface
+	key        []byte
+	sessionTTL time.Duration
+	// Хеш для сравнения при входе несуществующего пользователя,
+	// чтобы время ответа не выдавало наличие логина
+	dummyHash string
+}
+
+// New создает сервис аутентификации
+// key - секретный ключ подписи токенов, не короче MinKeyLength байт
+// sessionTTL - срок действия токена входа, 0 - DefaultSessionTTL
+func New(db storage.Interface, key []byte, sessionTTL time.Duration) (*Service, error) {
+	if len(key) < MinKeyLength {
+		return nil, fmt.Errorf("Ключ подписи токенов должен быть не короче %d байт", MinKeyLength)
+	}
+	if sessionTTL <= 0 {
+		sessionTTL = DefaultSessionTTL
+	}
+	dummy, err := HashPassword("dummy-password")
+	if err != nil {
+		return nil, err
+	}
+	return &Service{db: db, key: key, sessionTTL: sessionTTL, dummyHash: dummy}, nil
+}
+
+// SetPassword задает пароль пользователя и отзывает все его токены
+func (a *Service) SetPassword(ctx context.Context, userID int, password string) error {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return a.db.WithContext(ctx).WithTx(func(tx storage.Interface) error {
+		if err := tx.SetUserPassword(userID, hash); err != nil {
+			return err
+		}
+		_, err := tx.RevokeUserTokens(userID)
+		return err
+	})
+}
+
+// Login проверяет логин и пароль и выдает токен входа
+// Возвращает токен, срок его действия и пользователя
+// При неверном логине или пароле возвращает InvalidCredentialsErr, не уточняя причину
+func (a *Service) Login(ctx context.Context, login, password string) (string, time.Time, model.User, error) {
+	db := a.db.WithContext(ctx)
+	user, err := db.SelectUserByLogin(login)
+	if err != nil {
+		if errors.Is(err, myerrors.NotFoundErr) {
+			VerifyPassword(password, a.dummyHash)
+			return "", time.Time{}, model.User{}, InvalidCredentialsErr
+		}
+		return "", time.Time{}, model.User{}, err
+	}
+
+	hash, err := db.SelectUserPassword(user.ID)
+	if err != nil {
+		return "", time.Time{}, model.User{}, err
+	}
+	if hash == "" {
+		VerifyPassword(password, a.dummyHash)
+		return "", time.Time{}, model.User{}, InvalidCredentialsErr
+	}
+	ok, err := VerifyPassword(password, hash)
+	if err != nil {
+		return "", time.Time{}, model.User{}, err
+	}
+	if !ok {
+		return "", time.Time{}, model.User{}, InvalidCredentialsErr
+	}
+	if !user.Active {
+		return "", time.Time{}, model.User{}, UserInactiveErr
+	}
+
+	expires := time.Now().Add(a.sessionTTL)
+	token, err := a.issue(db, model.Token{UserID: user.ID, Kind: model.TokenSession, ExpiresAt: expires})
+	if err != nil {
+		return "", time.Time{}, model.User{}, err
+	}
+	return token, expires, user, nil
+}
+
+// IssueAPIToken выдает пользователю долгоживущий токен для программного доступа
+// ttl - срок действия, 0 - без ограничения срока
+func (a *Service) IssueAPIToken(ctx context.Context, userID int, name string, ttl time.Duration) (string, error) {
+	t := model.Token{UserID: userID, Kind: model.TokenAPI, Name: name}
+	if ttl > 0 {
+		t.ExpiresAt = time.Now().Add(ttl)
+	}
+	return a.issue(a.db.WithContext(ctx), t)
+}
+
+// issue создает токен и сохраняет его хеш
+func (a *Service) issue(db storage.Interface, t model.Token) (string, error) {
+	token, err := newToken(a.key)
+	if err != nil {
+		return "", err
+	}
+	t.Hash = tokenHash(token)
+	if _, err := db.NewToken(t); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Authenticate проверяет токен и возвращает его владельца
+// Проверяются подпись, наличие в БД, отзыв, срок действия и активность пользователя
+func (a *Service) Authenticate(ctx context.Context, token string) (model.User, model.Token, error) {
+	if !checkToken(a.key, token) {
+		return model.User{}, model.Token{}, InvalidTokenErr
+	}
+	db := a.db.WithContext(ctx)
+	t, err := db.SelectTokenByHash(tokenHash(token))
+	if err != nil {
+		if errors.Is(err, myerrors.NotFoundErr) {
+			return model.User{}, model.Token{}, InvalidTokenErr
+		}
+		return model.User{}, model.Token{}, err
+	}
+	if !t.RevokedAt.IsZero() {
+		return model.User{}, t, TokenRevokedErr
+	}
+	if !t.ExpiresAt.IsZero() && time.Now().After(t.ExpiresAt) {
+		return model.User{}, t, TokenExpiredErr
+	}
+
+	user, err := db.SelectUserByID(t.UserID)
+	if err != nil {
+		return model.User{}, t, err
+	}
+	if !user.Active {
+		return model.User{}, t, UserInactiveErr
+	}
+	return user, t, nil
+}
+
+// Revoke отзывает токен
+func (a *Service) Revoke(ctx context.Context, token string) error {
+	if !checkToken(a.key, token) {
+		return InvalidTokenErr
+	}
+	db := a.db.WithContext(ctx)
+	t, err := db.SelectTokenByHash(tokenHash(token))
+	if err != nil {
+		if errors.Is(err, myerrors.NotFoundErr) {
+			return InvalidTokenErr
+		}
+		return err
+	}
+	return db.RevokeToken(t.ID)
+}
+
+// RevokeAll отзывает все токены пользователя и возвращает их количество
+func (a *Service) RevokeAll(ctx context.Context, userID int) (int, error) {
+	return a.db.WithContext(ctx).RevokeUserTokens(userID)
+}
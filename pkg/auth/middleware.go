@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type contextKey int
+
+const (
+	userKey contextKey = iota
+	tokenKey
+)
+
+// WithUser возвращает контекст с текущим пользователем и его токеном
+// Пользователь также становится автором изменений хранилища (storage.WithActor)
+func WithUser(ctx context.Context, user model.User, token model.Token) context.Context {
+	ctx = storage.WithActor(ctx, user.ID)
+	ctx = context.WithValue(ctx, userKey, user)
+	return context.WithValue(ctx, tokenKey, token)
+}
+
+// UserFromContext возвращает текущего пользователя из контекста запроса
+func UserFromContext(ctx context.Context) (model.User, bool) {
+	user, ok := ctx.Value(userKey).(model.User)
+	return user, ok
+}
+
+// TokenFromContext возвращает токен, по которому аутентифицирован запрос
+func TokenFromContext(ctx context.Context) (model.Token, bool) {
+	t, ok := ctx.Value(tokenKey).(model.Token)
+	return t, ok
+}
+
+// BearerToken возвращает токен из заголовка Authorization: Bearer <токен>
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// Middleware проверяет токен из заголовка Authorization и добавляет пользователя в контекст запроса
+// Если токена нет или он недействителен, то отвечает 401 и не вызывает next
+func (a *Service) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := BearerToken(r)
+		if !ok {
+			unauthorized(w, "Требуется токен в заголовке Authorization")
+			return
+		}
+		user, t, err := a.Authenticate(r.Context(), token)
+		if err != nil {
+			switch {
+			case errors.Is(err, InvalidTokenErr), errors.Is(err, TokenExpiredErr),
+				errors.Is(err, TokenRevokedErr), errors.Is(err, UserInactiveErr):
+				unauthorized(w, err.Error())
+			default:
+				http.Error(w, "Ошибка проверки токена", http.StatusInternalServerError)
+			}
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user, t)))
+	})
+}
+
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="db_apps"`)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
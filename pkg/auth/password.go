@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Минимальная длина пароля в символах
+const MinPasswordLength = 8
+
+// Ошибки паролей
+var PasswordTooShortErr = fmt.Errorf("Пароль должен содержать не менее %d символов", MinPasswordLength)
+var PasswordHashFormatErr = errors.New("Некорректный формат хеша пароля")
+
+// Параметры argon2id (рекомендации RFC 9106 для ограниченной памяти)
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	argonKeyLen  = 32
+	argonSaltLen = 16
+)
+
+// Допустимые параметры argon2id в хеше из БД: выход за них означает поврежденный хеш
+// Верхние границы не дают испорченной записи занять процессор и память при входе
+const (
+	argonMaxTime    = 16
+	argonMaxMemory  = 1024 * 1024
+	argonMaxThreads = 64
+	argonMinKeyLen  = 16
+	argonMaxKeyLen  = 64
+	argonMinSaltLen = 8
+)
+
+// HashPassword возвращает хеш пароля argon2id в формате PHC:
+// $argon2id$v=19$m=65536,t=3,p=4$<соль>$<хеш>
+func HashPassword(password string) (string, error) {
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		return "", PasswordTooShortErr
+	}
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argonMemory, argonTime, argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// VerifyPassword сравнивает пароль с хешем, полученным от HashPassword
+// Параметры argon2id берутся из хеша, поэтому старые хеши остаются действительными после смены параметров
+// Если хеш поврежден или его параметры вне допустимых границ, то возвращается PasswordHashFormatErr
+func VerifyPassword(password, hash string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, PasswordHashFormatErr
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, PasswordHashFormatErr
+	}
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false, PasswordHashFormatErr
+	}
+	// argon2.IDKey паникует при t < 1 или p < 1, а память меньше 8*p не соответствует RFC 9106
+	if time < 1 || time > argonMaxTime || threads < 1 || threads > argonMaxThreads ||
+		memory < 8*uint32(threads) || memory > argonMaxMemory {
+		return false, PasswordHashFormatErr
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(salt) < argonMinSaltLen {
+		return false, PasswordHashFormatErr
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) < argonMinKeyLen || len(key) > argonMaxKeyLen {
+		return false, PasswordHashFormatErr
+	}
+
+	other := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=4$") {
+		t.Errorf("HashPassword() = %q, want формат PHC argon2id", hash)
+	}
+
+	ok, err := VerifyPassword("correct horse", hash)
+	if err != nil || !ok {
+		t.Errorf("VerifyPassword(верный пароль) = %v, %v, want true, nil", ok, err)
+	}
+	ok, err = VerifyPassword("correct horsf", hash)
+	if err != nil || ok {
+		t.Errorf("VerifyPassword(неверный пароль) = %v, %v, want false, nil", ok, err)
+	}
+	ok, err = VerifyPassword("", hash)
+	if err != nil || ok {
+		t.Errorf("VerifyPassword(пустой пароль) = %v, %v, want false, nil", ok, err)
+	}
+
+	// Соль случайная, поэтому хеши одного пароля различаются
+	other, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == hash {
+		t.Error("HashPassword() вернул одинаковые хеши для двух вызовов")
+	}
+}
+
+func TestHashPasswordTooShort(t *testing.T) {
+	if _, err := HashPassword("пароль1"); !errors.Is(err, PasswordTooShortErr) {
+		t.Errorf("HashPassword(7 символов) error = %v, want PasswordTooShortErr", err)
+	}
+	// Длина считается в символах, а не в байтах
+	if _, err := HashPassword("пароль12"); err != nil {
+		t.Errorf("HashPassword(8 символов) error = %v", err)
+	}
+}
+
+func TestVerifyPasswordMalformed(t *testing.T) {
+	const (
+		salt = "c2FsdHNhbHRzYWx0c2FsdA"
+		key  = "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+	)
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{name: "пустой", hash: ""},
+		{name: "без частей", hash: "argon2id"},
+		{name: "другой алгоритм", hash: "$argon2i$v=19$m=65536,t=3,p=4$" + salt + "$" + key},
+		{name: "другая версия", hash: "$argon2id$v=16$m=65536,t=3,p=4$" + salt + "$" + key},
+		{name: "нет параметров", hash: "$argon2id$v=19$$" + salt + "$" + key},
+		{name: "t=0", hash: "$argon2id$v=19$m=65536,t=0,p=4$" + salt + "$" + key},
+		{name: "p=0", hash: "$argon2id$v=19$m=65536,t=3,p=0$" + salt + "$" + key},
+		{name: "мало памяти", hash: "$argon2id$v=19$m=31,t=3,p=4$" + salt + "$" + key},
+		{name: "слишком много памяти", hash: "$argon2id$v=19$m=4294967295,t=3,p=4$" + salt + "$" + key},
+		{name: "слишком много проходов", hash: "$argon2id$v=19$m=65536,t=100000,p=4$" + salt + "$" + key},
+		{name: "слишком много потоков", hash: "$argon2id$v=19$m=65536,t=3,p=255$" + salt + "$" + key},
+		{name: "отрицательный параметр", hash: "$argon2id$v=19$m=65536,t=-1,p=4$" + salt + "$" + key},
+		{name: "соль не base64", hash: "$argon2id$v=19$m=65536,t=3,p=4$!!!$" + key},
+		{name: "короткая соль", hash: "$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$" + key},
+		{name: "хеш не base64", hash: "$argon2id$v=19$m=65536,t=3,p=4$" + salt + "$!!!"},
+		// Пустой хеш совпал бы с результатом IDKey нулевой длины для любого пароля
+		{name: "пустой хеш", hash: "$argon2id$v=19$m=65536,t=3,p=4$" + salt + "$"},
+		{name: "короткий хеш", hash: "$argon2id$v=19$m=65536,t=3,p=4$" + salt + "$a2V5"},
+		{name: "лишняя часть", hash: "$argon2id$v=19$m=65536,t=3,p=4$" + salt + "$" + key + "$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := VerifyPassword("correct horse", tt.hash)
+			if ok || !errors.Is(err, PasswordHashFormatErr) {
+				t.Errorf("VerifyPassword(%q) = %v, %v, want false, PasswordHashFormatErr", tt.hash, ok, err)
+			}
+		})
+	}
+}
+
+func TestVerifyPasswordCustomParams(t *testing.T) {
+	// Хеш с параметрами, отличными от текущих, остается действительным
+	salt := []byte("saltsaltsaltsalt")
+	key := argon2.IDKey([]byte("correct horse"), salt, 1, 64, 1, 16)
+	hash := fmt.Sprintf("$argon2id$v=19$m=64,t=1,p=1$%s$%s",
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+
+	if ok, err := VerifyPassword("correct horse", hash); err != nil || !ok {
+		t.Errorf("VerifyPassword(%q) = %v, %v, want true, nil", hash, ok, err)
+	}
+	if ok, err := VerifyPassword("wrong horse", hash); err != nil || ok {
+		t.Errorf("VerifyPassword(неверный пароль) = %v, %v, want false, nil", ok, err)
+	}
+}
+
+func TestToken(t *testing.T) {
+	key := []byte(strings.Repeat("k", MinKeyLength))
+	token, err := newToken(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !checkToken(key, token) {
+		t.Errorf("checkToken(%q) = false", token)
+	}
+	if checkToken([]byte(strings.Repeat("x", MinKeyLength)), token) {
+		t.Error("checkToken() принял токен, подписанный другим ключом")
+	}
+	body, _, _ := strings.Cut(token, ".")
+	for _, forged := range []string{"", body, body + ".", body + "x." + sign(key, body)} {
+		if checkToken(key, forged) {
+			t.Errorf("checkToken(%q) = true", forged)
+		}
+	}
+	if len(tokenHash(token)) != 32 {
+		t.Errorf("tokenHash() длины %d, want 32", len(tokenHash(token)))
+	}
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// Длина случайной части токена в байтах
+const tokenRandomLen = 32
+
+// newToken создает токен вида <случайная часть>.<подпись HMAC-SHA256>
+// Подпись позволяет отклонить поддельный токен без обращения к БД
+func newToken(key []byte) (string, error) {
+	random := make([]byte, tokenRandomLen)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	body := base64.RawURLEncoding.EncodeToString(random)
+	return body + "." + sign(key, body), nil
+}
+
+// checkToken проверяет подпись токена
+func checkToken(key []byte, token string) bool {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(sign(key, body)))
+}
+
+func sign(key []byte, body string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// tokenHash возвращает SHA-256 токена, под которым он хранится в БД
+func tokenHash(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
@@ -0,0 +1,73 @@
+package gqlapi
+
+import (
+	"DB_Apps/pkg/access"
+	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/names"
+	"DB_Apps/pkg/storage/postgresql"
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// Коды ошибок в extensions.code ответа
+const (
+	codeNotFound     = "NOT_FOUND"
+	codeForbidden    = "FORBIDDEN"
+	codeBadInput     = "BAD_USER_INPUT"
+	codeConflict     = "CONFLICT"
+	codePrecondition = "FAILED_PRECONDITION"
+	codeInternal     = "INTERNAL"
+)
+
+// gqlError - ошибка резолвера с кодом в extensions
+type gqlError struct {
+	msg  string
+	code string
+}
+
+func (e *gqlError) Error() string {
+	return e.msg
+}
+
+func (e *gqlError) Extensions() map[string]any {
+	return map[string]any{"code": e.code}
+}
+
+// badInput возвращает ошибку некорректных аргументов запроса
+func badInput(msg string) error {
+	return &gqlError{msg: msg, code: codeBadInput}
+}
+
+// code возвращает код ошибки хранилища
+func code(err error) string {
+	var partial myerrors.TaskPartialErr
+	switch {
+	case errors.Is(err, myerrors.NotFoundErr), errors.Is(err, postgresql.LabelOrTaskNotExistErr):
+		return codeNotFound
+	case errors.Is(err, access.ForbiddenErr):
+		return codeForbidden
+	case errors.Is(err, postgresql.DuplicateLabelIDErr):
+		return codeConflict
+	case names.IsInvalid(err), errors.As(err, &partial):
+		return codeBadInput
+	case errors.Is(err, postgresql.NotProjectMemberErr):
+		return codePrecondition
+	}
+	return codeInternal
+}
+
+// error преобразует ошибку хранилища в ошибку GraphQL
+// Текст внутренних ошибок клиенту не передается, а записывается в журнал
+func (r *request) error(ctx context.Context, err error) error {
+	var e *gqlError
+	if errors.As(err, &e) {
+		return err
+	}
+	c := code(err)
+	if c == codeInternal {
+		r.api.logger.ErrorContext(ctx, "Ошибка обработки запроса GraphQL", slog.Any("error", err))
+		return &gqlError{msg: "Внутренняя ошибка сервера", code: c}
+	}
+	return &gqlError{msg: err.Error(), code: c}
+}
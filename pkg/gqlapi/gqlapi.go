@@ -0,0 +1,95 @@
+// Пакет gqlapi содержит GraphQL API сервиса: задачи проекта с автором, исполнителем и метками в одном запросе
+// Пользователи и метки задач загружаются пакетами (dataloader), чтобы не выполнять запрос на каждую задачу
+package gqlapi
+
+import (
+	"DB_Apps/pkg/access"
+	"DB_Apps/pkg/auth"
+	"DB_Apps/pkg/storage"
+	"context"
+	_ "embed"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// Заголовок с ID проекта, задачами и метками которого ограничен запрос
+// Без него используется проект хранилища db
+const ProjectHeader = "X-Project-ID"
+
+// Наибольшая глубина вложенности запроса
+const MaxDepth = 10
+
+// API - GraphQL API поверх storage.Interface
+type API struct {
+	db     storage.Interface
+	auth   *auth.Service
+	logger *slog.Logger
+	schema *graphql.Schema
+}
+
+// New создает GraphQL API
+// Если authService не равен nil, то запросы требуют токен в заголовке Authorization
+// и выполняются от имени его владельца с проверкой прав (access.DefaultPolicy)
+func New(db storage.Interface, authService *auth.Service, logger *slog.Logger) *API {
+	api := &API{db: db, auth: authService, logger: logger}
+	api.schema = graphql.MustParseSchema(schema, &resolver{api: api}, graphql.MaxDepth(MaxDepth))
+	return api
+}
+
+// Handler возвращает обработчик запросов GraphQL (POST с JSON-телом query, operationName, variables)
+func (api *API) Handler() http.Handler {
+	var h http.Handler = http.HandlerFunc(api.serve)
+	if api.auth != nil {
+		h = api.auth.Middleware(h)
+	}
+	return h
+}
+
+// serve готовит хранилище и загрузчики запроса и выполняет его
+func (api *API) serve(w http.ResponseWriter, r *http.Request) {
+	db := api.db.WithContext(r.Context())
+	if value := r.Header.Get(ProjectHeader); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Некорректный ID проекта в заголовке "+ProjectHeader, http.StatusBadRequest)
+			return
+		}
+		db = db.WithProject(id)
+	}
+	if api.auth != nil {
+		user, _ := auth.UserFromContext(r.Context())
+		db = access.New(db, user, nil)
+	}
+	ctx := withRequest(r.Context(), newRequest(api, db))
+	(&relay.Handler{Schema: api.schema}).ServeHTTP(w, r.WithContext(ctx))
+}
+
+// request - состояние запроса: хранилище и загрузчики
+type request struct {
+	api        *API
+	db         storage.Interface
+	users      *userLoader
+	taskLabels *taskLabelsLoader
+}
+
+func newRequest(api *API, db storage.Interface) *request {
+	return &request{api: api, db: db, users: newUserLoader(db), taskLabels: newTaskLabelsLoader(db)}
+}
+
+type requestKey struct{}
+
+func withRequest(ctx context.Context, r *request) context.Context {
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// requestFrom возвращает состояние запроса, добавленное serve
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
@@ -0,0 +1,86 @@
+package gqlapi
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage"
+	"context"
+	"time"
+
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// Время ожидания, за которое загрузчик собирает ключи в один пакет
+const batchWait = 2 * time.Millisecond
+
+type userLoader = dataloader.Loader[int, *model.User]
+
+// newUserLoader создает загрузчик пользователей по ID одним запросом SelectUsersByIDs на пакет
+// Для несуществующего пользователя загружается nil
+func newUserLoader(db storage.Interface) *userLoader {
+	return dataloader.NewBatchedLoader(func(_ context.Context, ids []int) []*dataloader.Result[*model.User] {
+		users, err := db.SelectUsersByIDs(ids)
+		byID := make(map[int]*model.User, len(users))
+		for i := range users {
+			byID[users[i].ID] = &users[i]
+		}
+		results := make([]*dataloader.Result[*model.User], len(ids))
+		for i, id := range ids {
+			results[i] = &dataloader.Result[*model.User]{Data: byID[id], Error: err}
+		}
+		return results
+	}, dataloader.WithWait[int, *model.User](batchWait))
+}
+
+type taskLabelsLoader = dataloader.Loader[int, []model.Label]
+
+// newTaskLabelsLoader создает загрузчик меток задач по ID задачи
+// На пакет выполняется два запроса: ID меток задач (SelectTaskLabelIDs) и сами метки (SelectLabelsByIDs)
+func newTaskLabelsLoader(db storage.Interface) *taskLabelsLoader {
+	return dataloader.NewBatchedLoader(func(_ context.Context, taskIDs []int) []*dataloader.Result[[]model.Label] {
+		results := make([]*dataloader.Result[[]model.Label], len(taskIDs))
+		labels, err := loadTaskLabels(db, taskIDs)
+		for i, id := range taskIDs {
+			results[i] = &dataloader.Result[[]model.Label]{Data: labels[id], Error: err}
+		}
+		return results
+	}, dataloader.WithWait[int, []model.Label](batchWait))
+}
+
+// loadTaskLabels возвращает метки задач taskIDs
+func loadTaskLabels(db storage.Interface, taskIDs []int) (map[int][]model.Label, error) {
+	labelIDs, err := db.SelectTaskLabelIDs(taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	seen := make(map[int]bool)
+	for _, taskLabels := range labelIDs {
+		for _, id := range taskLabels {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	labels, err := db.SelectLabelsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]model.Label, len(labels))
+	for _, l := range labels {
+		byID[l.ID] = l
+	}
+
+	result := make(map[int][]model.Label, len(labelIDs))
+	for taskID, taskLabels := range labelIDs {
+		for _, id := range taskLabels {
+			if l, ok := byID[id]; ok {
+				result[taskID] = append(result[taskID], l)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,392 @@
+package gqlapi
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/storage"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Наибольшее количество задач на странице tasks
+const MaxPageSize = 100
+
+// resolver - корневой резолвер запросов и мутаций
+type resolver struct {
+	api *API
+}
+
+// parseID преобразует ID GraphQL в ID хранилища
+func parseID(id graphql.ID) (int, error) {
+	v, err := strconv.Atoi(string(id))
+	if err != nil {
+		return 0, badInput(fmt.Sprintf("Некорректный ID: %q", id))
+	}
+	return v, nil
+}
+
+// parseIDs преобразует необязательный список ID, nil - список не задан
+func parseIDs(ids *[]graphql.ID) ([]int, error) {
+	if ids == nil {
+		return nil, nil
+	}
+	result := make([]int, 0, len(*ids))
+	for _, id := range *ids {
+		v, err := parseID(id)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func formatID(id int) graphql.ID {
+	return graphql.ID(strconv.Itoa(id))
+}
+
+// Курсор задачи - base64 от "task:<ID>"
+const cursorPrefix = "task:"
+
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(id)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if s, ok := strings.CutPrefix(string(b), cursorPrefix); ok {
+			if id, err := strconv.Atoi(s); err == nil {
+				return id, nil
+			}
+		}
+	}
+	return 0, badInput(fmt.Sprintf("Некорректный курсор: %q", cursor))
+}
+
+func (r *resolver) Task(ctx context.Context, args struct{ ID graphql.ID }) (*taskResolver, error) {
+	req := requestFrom(ctx)
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	task, err := req.db.SelectTaskByID(id)
+	if errors.Is(err, myerrors.NotFoundErr) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, req.error(ctx, err)
+	}
+	return &taskResolver{task: task}, nil
+}
+
+type tasksArgs struct {
+	First      int32
+	After      *string
+	AuthorID   *graphql.ID
+	AssigneeID *graphql.ID
+	LabelID    *graphql.ID
+}
+
+// Tasks возвращает страницу задач проекта после курсора after
+func (r *resolver) Tasks(ctx context.Context, args tasksArgs) (*taskConnection, error) {
+	req := requestFrom(ctx)
+	if args.First < 1 || args.First > MaxPageSize {
+		return nil, badInput(fmt.Sprintf("first должен быть от 1 до %d", MaxPageSize))
+	}
+	q := model.TaskPageQuery{Limit: int(args.First) + 1}
+	var err error
+	if args.After != nil {
+		if q.AfterID, err = decodeCursor(*args.After); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range []struct {
+		id  *graphql.ID
+		dst *int
+	}{{args.AuthorID, &q.AuthorID}, {args.AssigneeID, &q.AssignedID}, {args.LabelID, &q.LabelID}} {
+		if f.id == nil {
+			continue
+		}
+		if *f.dst, err = parseID(*f.id); err != nil {
+			return nil, err
+		}
+	}
+
+	tasks, err := req.db.SelectTasksPage(q)
+	if err != nil {
+		return nil, req.error(ctx, err)
+	}
+	// Лишняя задача запрашивается только для признака следующей страницы
+	conn := &taskConnection{hasNext: len(tasks) > int(args.First)}
+	if conn.hasNext {
+		tasks = tasks[:args.First]
+	}
+	for _, t := range tasks {
+		conn.edges = append(conn.edges, &taskEdge{task: t})
+	}
+	return conn, nil
+}
+
+func (r *resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return loadUser(ctx, id)
+}
+
+func (r *resolver) Users(ctx context.Context) ([]*userResolver, error) {
+	req := requestFrom(ctx)
+	users, err := req.db.SelectUsers()
+	if err != nil {
+		return nil, req.error(ctx, err)
+	}
+	result := make([]*userResolver, len(users))
+	for i, u := range users {
+		result[i] = &userResolver{user: u}
+	}
+	return result, nil
+}
+
+func (r *resolver) Label(ctx context.Context, args struct{ ID graphql.ID }) (*labelResolver, error) {
+	req := requestFrom(ctx)
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	label, err := req.db.SelectLabelByID(id)
+	if errors.Is(err, myerrors.NotFoundErr) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, req.error(ctx, err)
+	}
+	return &labelResolver{label: label}, nil
+}
+
+func (r *resolver) Labels(ctx context.Context) ([]*labelResolver, error) {
+	req := requestFrom(ctx)
+	labels, err := req.db.SelectLabels()
+	if err != nil {
+		return nil, req.error(ctx, err)
+	}
+	result := make([]*labelResolver, len(labels))
+	for i, l := range labels {
+		result[i] = &labelResolver{label: l}
+	}
+	return result, nil
+}
+
+type createTaskInput struct {
+	Title      string
+	Content    *string
+	AuthorID   graphql.ID
+	AssigneeID *graphql.ID
+	LabelIDs   *[]graphql.ID
+}
+
+func (r *resolver) CreateTask(ctx context.Context, args struct{ Input createTaskInput }) (*taskResolver, error) {
+	req := requestFrom(ctx)
+	in := args.Input
+	task := model.Task{Title: in.Title}
+	if in.Content != nil {
+		task.Content = *in.Content
+	}
+	var err error
+	if task.AuthorID, err = parseID(in.AuthorID); err != nil {
+		return nil, err
+	}
+	if in.AssigneeID != nil {
+		if task.AssignedID, err = parseID(*in.AssigneeID); err != nil {
+			return nil, err
+		}
+	}
+	if task.LabelsID, err = parseIDs(in.LabelIDs); err != nil {
+		return nil, err
+	}
+
+	id, err := req.db.NewTask(task)
+	if err != nil {
+		return nil, req.error(ctx, err)
+	}
+	return r.Task(ctx, struct{ ID graphql.ID }{formatID(id)})
+}
+
+type updateTaskInput struct {
+	ID         graphql.ID
+	Title      *string
+	Content    *string
+	AssigneeID *graphql.ID
+	LabelIDs   *[]graphql.ID
+}
+
+// UpdateTask изменяет заданные поля задачи, остальные берет из текущего состояния в той же транзакции
+func (r *resolver) UpdateTask(ctx context.Context, args struct{ Input updateTaskInput }) (*taskResolver, error) {
+	req := requestFrom(ctx)
+	in := args.Input
+	id, err := parseID(in.ID)
+	if err != nil {
+		return nil, err
+	}
+	var assignee int
+	if in.AssigneeID != nil {
+		if assignee, err = parseID(*in.AssigneeID); err != nil {
+			return nil, err
+		}
+	}
+	labels, err := parseIDs(in.LabelIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var task model.Task
+	err = req.db.WithTx(func(tx storage.Interface) error {
+		if task, err = tx.SelectTaskByID(id); err != nil {
+			return err
+		}
+		if in.Title != nil {
+			task.Title = *in.Title
+		}
+		if in.Content != nil {
+			task.Content = *in.Content
+		}
+		if in.AssigneeID != nil {
+			task.AssignedID = assignee
+		}
+		if in.LabelIDs != nil {
+			task.LabelsID = labels
+		}
+		if err := tx.UpdateTaskByID(task); err != nil {
+			return err
+		}
+		task, err = tx.SelectTaskByID(id)
+		return err
+	})
+	if err != nil {
+		return nil, req.error(ctx, err)
+	}
+	return &taskResolver{task: task}, nil
+}
+
+func (r *resolver) CloseTask(ctx context.Context, args struct{ ID graphql.ID }) (*taskResolver, error) {
+	req := requestFrom(ctx)
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.db.CloseTask(id); err != nil {
+		return nil, req.error(ctx, err)
+	}
+	task, err := req.db.SelectTaskByID(id)
+	if err != nil {
+		return nil, req.error(ctx, err)
+	}
+	return &taskResolver{task: task}, nil
+}
+
+// loadUser загружает пользователя через загрузчик запроса, для несуществующего возвращает nil
+func loadUser(ctx context.Context, id int) (*userResolver, error) {
+	req := requestFrom(ctx)
+	user, err := req.users.Load(ctx, id)()
+	if err != nil {
+		return nil, req.error(ctx, err)
+	}
+	if user == nil {
+		return nil, nil
+	}
+	return &userResolver{user: *user}, nil
+}
+
+type taskResolver struct {
+	task model.Task
+}
+
+func (t *taskResolver) ID() graphql.ID       { return formatID(t.task.ID) }
+func (t *taskResolver) Title() string        { return t.task.Title }
+func (t *taskResolver) Content() string      { return t.task.Content }
+func (t *taskResolver) Opened() graphql.Time { return graphql.Time{Time: t.task.Opened} }
+
+func (t *taskResolver) Closed() *graphql.Time {
+	if t.task.Closed == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t.task.Closed}
+}
+
+func (t *taskResolver) Author(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, t.task.AuthorID)
+}
+
+func (t *taskResolver) Assignee(ctx context.Context) (*userResolver, error) {
+	return loadUser(ctx, t.task.AssignedID)
+}
+
+func (t *taskResolver) Labels(ctx context.Context) ([]*labelResolver, error) {
+	req := requestFrom(ctx)
+	labels, err := req.taskLabels.Load(ctx, t.task.ID)()
+	if err != nil {
+		return nil, req.error(ctx, err)
+	}
+	result := make([]*labelResolver, len(labels))
+	for i, l := range labels {
+		result[i] = &labelResolver{label: l}
+	}
+	return result, nil
+}
+
+type userResolver struct {
+	user model.User
+}
+
+func (u *userResolver) ID() graphql.ID      { return formatID(u.user.ID) }
+func (u *userResolver) Name() string        { return u.user.Name }
+func (u *userResolver) Login() string       { return u.user.Login }
+func (u *userResolver) Email() string       { return u.user.Email }
+func (u *userResolver) DisplayName() string { return u.user.DisplayName }
+func (u *userResolver) Active() bool        { return u.user.Active }
+func (u *userResolver) Role() string        { return string(u.user.Role) }
+
+type labelResolver struct {
+	label model.Label
+}
+
+func (l *labelResolver) ID() graphql.ID { return formatID(l.label.ID) }
+func (l *labelResolver) Name() string   { return l.label.Name }
+
+type taskConnection struct {
+	edges   []*taskEdge
+	hasNext bool
+}
+
+func (c *taskConnection) Edges() []*taskEdge { return c.edges }
+
+func (c *taskConnection) PageInfo() *pageInfo {
+	p := &pageInfo{hasNext: c.hasNext}
+	if len(c.edges) > 0 {
+		cursor := c.edges[len(c.edges)-1].Cursor()
+		p.endCursor = &cursor
+	}
+	return p
+}
+
+type taskEdge struct {
+	task model.Task
+}
+
+func (e *taskEdge) Cursor() string      { return encodeCursor(e.task.ID) }
+func (e *taskEdge) Node() *taskResolver { return &taskResolver{task: e.task} }
+
+type pageInfo struct {
+	endCursor *string
+	hasNext   bool
+}
+
+func (p *pageInfo) EndCursor() *string { return p.endCursor }
+func (p *pageInfo) HasNextPage() bool  { return p.hasNext }
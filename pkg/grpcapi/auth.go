@@ -0,0 +1,74 @@
+package grpcapi
+
+import (
+	"DB_Apps/pkg/auth"
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// bearerToken возвращает токен из метаданных authorization: Bearer <токен>
+func bearerToken(ctx context.Context) (string, bool) {
+	values := metadata.ValueFromIncomingContext(ctx, "authorization")
+	if len(values) == 0 {
+		return "", false
+	}
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// authenticate проверяет токен вызова и добавляет пользователя в контекст
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Требуется токен в метаданных authorization")
+	}
+	user, t, err := s.auth.Authenticate(ctx, token)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.InvalidTokenErr), errors.Is(err, auth.TokenExpiredErr),
+			errors.Is(err, auth.TokenRevokedErr), errors.Is(err, auth.UserInactiveErr):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, s.status(ctx, err)
+	}
+	return auth.WithUser(ctx, user, t), nil
+}
+
+// unaryAuth - перехватчик аутентификации обычных вызовов
+func (s *Server) unaryAuth(ctx context.Context, req any, _ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authStream подменяет контекст потока контекстом с пользователем
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+// streamAuth - перехватчик аутентификации потоковых вызовов
+func (s *Server) streamAuth(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
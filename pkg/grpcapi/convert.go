@@ -0,0 +1,76 @@
+package grpcapi
+
+import (
+	"DB_Apps/pkg/grpcapi/pb"
+	"DB_Apps/pkg/model"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toUser(u model.User) *pb.User {
+	return &pb.User{
+		Id:          int64(u.ID),
+		Name:        u.Name,
+		Login:       u.Login,
+		Email:       u.Email,
+		DisplayName: u.DisplayName,
+		Active:      u.Active,
+		Role:        string(u.Role),
+	}
+}
+
+func fromUser(u *pb.User) model.User {
+	return model.User{
+		ID:          int(u.GetId()),
+		Name:        u.GetName(),
+		Login:       u.GetLogin(),
+		Email:       u.GetEmail(),
+		DisplayName: u.GetDisplayName(),
+		Active:      u.GetActive(),
+		Role:        model.Role(u.GetRole()),
+	}
+}
+
+func toLabel(l model.Label) *pb.Label {
+	return &pb.Label{Id: int64(l.ID), ProjectId: int64(l.ProjectID), Name: l.Name}
+}
+
+func fromLabel(l *pb.Label) model.Label {
+	return model.Label{ID: int(l.GetId()), ProjectID: int(l.GetProjectId()), Name: l.GetName()}
+}
+
+func toTask(t model.Task) *pb.Task {
+	task := &pb.Task{
+		Id:         int64(t.ID),
+		ProjectId:  int64(t.ProjectID),
+		Opened:     timestamppb.New(t.Opened),
+		AuthorId:   int64(t.AuthorID),
+		AssignedId: int64(t.AssignedID),
+		Title:      t.Title,
+		Content:    t.Content,
+		LabelIds:   make([]int64, len(t.LabelsID)),
+	}
+	if t.Closed != nil {
+		task.Closed = timestamppb.New(*t.Closed)
+	}
+	for i, id := range t.LabelsID {
+		task.LabelIds[i] = int64(id)
+	}
+	return task
+}
+
+// fromTask преобразует задачу из сообщения, время открытия и закрытия задает хранилище
+func fromTask(t *pb.Task) model.Task {
+	task := model.Task{
+		ID:         int(t.GetId()),
+		ProjectID:  int(t.GetProjectId()),
+		AuthorID:   int(t.GetAuthorId()),
+		AssignedID: int(t.GetAssignedId()),
+		Title:      t.GetTitle(),
+		Content:    t.GetContent(),
+	}
+	for _, id := range t.GetLabelIds() {
+		task.LabelsID = append(task.LabelsID, int(id))
+	}
+	return task
+}
@@ -0,0 +1,55 @@
+package grpcapi
+
+import (
+	"DB_Apps/pkg/access"
+	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/names"
+	"DB_Apps/pkg/storage/postgresql"
+	"context"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// code возвращает код статуса gRPC для ошибки хранилища
+func code(err error) codes.Code {
+	var partial myerrors.TaskPartialErr
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, myerrors.NotFoundErr), errors.Is(err, postgresql.LabelOrTaskNotExistErr):
+		return codes.NotFound
+	case errors.Is(err, access.ForbiddenErr):
+		return codes.PermissionDenied
+	case errors.Is(err, postgresql.DuplicateLoginErr), errors.Is(err, postgresql.DuplicateEmailErr),
+		errors.Is(err, postgresql.DuplicateLabelIDErr), errors.Is(err, postgresql.DuplicateProjectErr):
+		return codes.AlreadyExists
+	case names.IsInvalid(err), errors.Is(err, postgresql.UserLoginErr), errors.Is(err, postgresql.UserEmailErr),
+		errors.Is(err, postgresql.UserRoleErr), errors.Is(err, postgresql.LabelNameErr),
+		errors.Is(err, postgresql.ProjectNameErr), errors.Is(err, postgresql.DeleteStrategyErr),
+		errors.As(err, &partial):
+		return codes.InvalidArgument
+	case errors.Is(err, postgresql.UserHasTasksErr), errors.Is(err, postgresql.DefaultUserErr),
+		errors.Is(err, postgresql.NotProjectMemberErr), errors.Is(err, postgresql.DefaultProjectErr):
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
+
+// status преобразует ошибку хранилища в статус gRPC
+// Текст внутренних ошибок клиенту не передается, а записывается в журнал
+func (s *Server) status(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	c := code(err)
+	if c == codes.Internal {
+		s.logger.ErrorContext(ctx, "Ошибка обработки вызова gRPC", slog.Any("error", err))
+		return status.Error(c, "Внутренняя ошибка сервера")
+	}
+	return status.Error(c, err.Error())
+}
@@ -0,0 +1,75 @@
+// Пакет grpcapi содержит gRPC API сервиса: пользователи, метки и задачи поверх storage.Interface
+// Списки передаются потоком сообщений, ошибки хранилища преобразуются в коды статусов gRPC
+package grpcapi
+
+import (
+	"DB_Apps/pkg/access"
+	"DB_Apps/pkg/auth"
+	"DB_Apps/pkg/grpcapi/pb"
+	"DB_Apps/pkg/storage"
+	"context"
+	"log/slog"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Ключ метаданных с ID проекта, задачами и метками которого ограничен вызов
+// Без него используется проект хранилища db
+const ProjectMetadata = "x-project-id"
+
+// Server - реализация сервисов Users, Labels и Tasks
+type Server struct {
+	db     storage.Interface
+	auth   *auth.Service
+	logger *slog.Logger
+}
+
+// New создает gRPC API
+// Если authService не равен nil, то вызовы требуют токен в метаданных authorization
+// и выполняются от имени его владельца с проверкой прав (access.DefaultPolicy)
+func New(db storage.Interface, authService *auth.Service, logger *slog.Logger) *Server {
+	return &Server{db: db, auth: authService, logger: logger}
+}
+
+// ServerOptions возвращает параметры grpc.Server, необходимые API (перехватчики аутентификации)
+func (s *Server) ServerOptions() []grpc.ServerOption {
+	if s.auth == nil {
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.unaryAuth),
+		grpc.ChainStreamInterceptor(s.streamAuth),
+	}
+}
+
+// Register регистрирует сервисы API на сервере srv
+func (s *Server) Register(srv grpc.ServiceRegistrar) {
+	pb.RegisterUsersServer(srv, &usersServer{Server: s})
+	pb.RegisterLabelsServer(srv, &labelsServer{Server: s})
+	pb.RegisterTasksServer(srv, &tasksServer{Server: s})
+}
+
+// storage возвращает хранилище для вызова: с контекстом и проектом вызова,
+// а при включенной аутентификации - с проверкой прав текущего пользователя
+func (s *Server) storage(ctx context.Context) (storage.Interface, error) {
+	db := s.db.WithContext(ctx)
+	if values := metadata.ValueFromIncomingContext(ctx, ProjectMetadata); len(values) > 0 {
+		id, err := strconv.Atoi(values[0])
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Некорректный ID проекта: %q", values[0])
+		}
+		db = db.WithProject(id)
+	}
+	if s.auth == nil {
+		return db, nil
+	}
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Требуется токен в метаданных authorization")
+	}
+	return access.New(db, user, nil), nil
+}
@@ -0,0 +1,291 @@
+package grpcapi
+
+import (
+	"DB_Apps/pkg/access"
+	"DB_Apps/pkg/auth"
+	"DB_Apps/pkg/grpcapi/pb"
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/names"
+	"DB_Apps/pkg/storage"
+	"DB_Apps/pkg/storage/postgresql"
+	"DB_Apps/pkg/storage/storagetest"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient запускает API над db на bufconn и возвращает клиент сервиса Tasks
+// Если authService не равен nil, то вызовы требуют токен
+func newTestClient(t *testing.T, db storage.Interface, authService *auth.Service) pb.TasksClient {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	api := New(db, authService, logger)
+	srv := grpc.NewServer(api.ServerOptions()...)
+	api.Register(srv)
+
+	ln := bufconn.Listen(1 << 20)
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ln.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewTasksClient(conn)
+}
+
+func newTestAuth(t *testing.T, db storage.Interface) *auth.Service {
+	t.Helper()
+	a, err := auth.New(db, []byte(strings.Repeat("k", auth.MinKeyLength)), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+// listTasks читает поток ListTasks целиком
+func listTasks(ctx context.Context, client pb.TasksClient, req *pb.ListTasksRequest) ([]*pb.Task, error) {
+	stream, err := client.ListTasks(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []*pb.Task
+	for {
+		task, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return tasks, nil
+		}
+		if err != nil {
+			return tasks, err
+		}
+		tasks = append(tasks, task)
+	}
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	db := storagetest.New()
+	db.Users[1] = model.User{ID: 1, Name: "Иван", Role: model.RoleMember, Active: true}
+	db.Users[2] = model.User{ID: 2, Name: "Петр", Role: model.RoleMember, Active: false}
+	db.Tasks = []model.Task{{ID: 1, Title: "Задача", Opened: time.Now()}}
+	a := newTestAuth(t, db)
+	client := newTestClient(t, db, a)
+
+	valid, err := a.IssueAPIToken(context.Background(), 1, "test", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := a.IssueAPIToken(context.Background(), 1, "test", time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inactive, err := a.IssueAPIToken(context.Background(), 2, "test", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{name: "без токена", ctx: context.Background(), want: codes.Unauthenticated},
+		{name: "другая схема", ctx: metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic "+valid),
+			want: codes.Unauthenticated},
+		{name: "неверная подпись", ctx: withToken(context.Background(), valid+"x"), want: codes.Unauthenticated},
+		{name: "произвольная строка", ctx: withToken(context.Background(), "token"), want: codes.Unauthenticated},
+		{name: "истекший токен", ctx: withToken(context.Background(), expired), want: codes.Unauthenticated},
+		{name: "заблокированный пользователь", ctx: withToken(context.Background(), inactive), want: codes.Unauthenticated},
+		{name: "действительный токен", ctx: withToken(context.Background(), valid), want: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetTask(tt.ctx, &pb.IDRequest{Id: 1})
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("GetTask() code = %v, want %v (%v)", got, tt.want, err)
+			}
+
+			_, err = listTasks(tt.ctx, client, &pb.ListTasksRequest{})
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("ListTasks() code = %v, want %v (%v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestAuthPermissionDenied(t *testing.T) {
+	db := storagetest.New()
+	db.Users[1] = model.User{ID: 1, Name: "Иван", Role: model.RoleViewer, Active: true}
+	a := newTestAuth(t, db)
+	client := newTestClient(t, db, a)
+
+	token, err := a.IssueAPIToken(context.Background(), 1, "test", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.CreateTask(withToken(context.Background(), token), &pb.Task{Title: "Задача", AuthorId: 1})
+	if got := status.Code(err); got != codes.PermissionDenied {
+		t.Errorf("CreateTask() code = %v, want PermissionDenied (%v)", got, err)
+	}
+	if len(db.Tasks) != 0 {
+		t.Errorf("задача создана без прав: %v", db.Tasks)
+	}
+}
+
+func TestListTasks(t *testing.T) {
+	db := storagetest.New()
+	opened := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	db.Tasks = []model.Task{
+		{ID: 1, AuthorID: 1, Title: "Первая", Opened: opened, LabelsID: []int{1}},
+		{ID: 2, AuthorID: 2, Title: "Вторая", Opened: opened, LabelsID: []int{1, 2}},
+		{ID: 3, AuthorID: 1, Title: "Третья", Opened: opened},
+	}
+	client := newTestClient(t, db, nil)
+
+	tests := []struct {
+		name string
+		req  *pb.ListTasksRequest
+		want []int64
+	}{
+		{name: "все", req: &pb.ListTasksRequest{}, want: []int64{1, 2, 3}},
+		{name: "по автору", req: &pb.ListTasksRequest{AuthorId: 1}, want: []int64{1, 3}},
+		{name: "по метке", req: &pb.ListTasksRequest{LabelId: 2}, want: []int64{2}},
+		{name: "пусто", req: &pb.ListTasksRequest{AuthorId: 9}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := listTasks(context.Background(), client, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int64
+			for _, task := range tasks {
+				got = append(got, task.GetId())
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ListTasks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	tasks, err := listTasks(context.Background(), client, &pb.ListTasksRequest{LabelId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task := tasks[1]; task.GetTitle() != "Вторая" || !task.GetOpened().AsTime().Equal(opened) ||
+		fmt.Sprint(task.GetLabelIds()) != "[1 2]" {
+		t.Errorf("ListTasks() передал задачу %v", task)
+	}
+}
+
+func TestListTasksBothFilters(t *testing.T) {
+	client := newTestClient(t, storagetest.New(), nil)
+	_, err := listTasks(context.Background(), client, &pb.ListTasksRequest{AuthorId: 1, LabelId: 1})
+	if got := status.Code(err); got != codes.InvalidArgument {
+		t.Errorf("ListTasks() code = %v, want InvalidArgument", got)
+	}
+}
+
+func TestListTasksStreamError(t *testing.T) {
+	db := storagetest.New()
+	db.Tasks = []model.Task{{ID: 1, Title: "Первая"}, {ID: 2, Title: "Вторая"}}
+	db.Err = errors.New("обрыв соединения с БД")
+	client := newTestClient(t, db, nil)
+
+	tasks, err := listTasks(context.Background(), client, &pb.ListTasksRequest{})
+	if len(tasks) != 2 {
+		t.Errorf("ListTasks() передал %d задач до ошибки, want 2", len(tasks))
+	}
+	if got := status.Code(err); got != codes.Internal {
+		t.Errorf("ListTasks() code = %v, want Internal", got)
+	}
+	if strings.Contains(err.Error(), "обрыв") {
+		t.Errorf("текст внутренней ошибки передан клиенту: %v", err)
+	}
+}
+
+func TestProjectMetadata(t *testing.T) {
+	db := storagetest.New()
+	client := newTestClient(t, db, nil)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), ProjectMetadata, "5")
+	if _, err := listTasks(ctx, client, &pb.ListTasksRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Calls) != 1 || db.Calls[0].Method != "IterTasks" || db.Calls[0].Project != 5 {
+		t.Errorf("вызовы %+v, want IterTasks в проекте 5", db.Calls)
+	}
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), ProjectMetadata, "abc")
+	if _, err := client.GetTask(ctx, &pb.IDRequest{Id: 1}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetTask() code = %v, want InvalidArgument", status.Code(err))
+	}
+}
+
+func TestStorageErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{name: "не найдено", err: myerrors.NotFound("Задача с ID %d не найдена", 1), want: codes.NotFound},
+		{name: "нет метки или задачи", err: postgresql.LabelOrTaskNotExistErr, want: codes.NotFound},
+		{name: "запрещено", err: &access.ForbiddenError{Action: access.CreateTask}, want: codes.PermissionDenied},
+		{name: "дубликат логина", err: postgresql.DuplicateLoginErr, want: codes.AlreadyExists},
+		{name: "дубликат проекта", err: fmt.Errorf("проект: %w", postgresql.DuplicateProjectErr), want: codes.AlreadyExists},
+		{name: "недопустимое имя", err: names.CyrillicOnlyErr, want: codes.InvalidArgument},
+		{name: "роль", err: postgresql.UserRoleErr, want: codes.InvalidArgument},
+		{name: "частичная ошибка задачи", err: myerrors.TaskPartialErr{TaskID: 1, Errs: []error{errors.New("автор")}},
+			want: codes.InvalidArgument},
+		{name: "есть задачи", err: postgresql.UserHasTasksErr, want: codes.FailedPrecondition},
+		{name: "не участник проекта", err: postgresql.NotProjectMemberErr, want: codes.FailedPrecondition},
+		{name: "отмена", err: context.Canceled, want: codes.Canceled},
+		{name: "срок", err: context.DeadlineExceeded, want: codes.DeadlineExceeded},
+		{name: "прочее", err: errors.New("ошибка драйвера"), want: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := code(tt.err); got != tt.want {
+				t.Errorf("code(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+
+			db := storagetest.New()
+			db.Err = tt.err
+			client := newTestClient(t, db, nil)
+			_, err := client.GetTask(context.Background(), &pb.IDRequest{Id: 1})
+			st := status.Convert(err)
+			if st.Code() != tt.want {
+				t.Errorf("GetTask() code = %v, want %v", st.Code(), tt.want)
+			}
+			if tt.want == codes.Internal && st.Message() == tt.err.Error() {
+				t.Errorf("текст внутренней ошибки передан клиенту: %q", st.Message())
+			}
+			if tt.want != codes.Internal && st.Message() != tt.err.Error() {
+				t.Errorf("GetTask() message = %q, want %q", st.Message(), tt.err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package grpcapi
+
+import (
+	"DB_Apps/pkg/grpcapi/pb"
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// labelsServer - сервис Labels
+type labelsServer struct {
+	pb.UnimplementedLabelsServer
+	*Server
+}
+
+func (s *labelsServer) CreateLabel(ctx context.Context, req *pb.Label) (*pb.IDResponse, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := db.NewLabel(fromLabel(req))
+	if err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return &pb.IDResponse{Id: int64(id)}, nil
+}
+
+func (s *labelsServer) GetLabel(ctx context.Context, req *pb.IDRequest) (*pb.Label, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	label, err := db.SelectLabelByID(int(req.GetId()))
+	if err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return toLabel(label), nil
+}
+
+func (s *labelsServer) ListLabels(_ *pb.ListRequest, stream grpc.ServerStreamingServer[pb.Label]) error {
+	ctx := stream.Context()
+	db, err := s.storage(ctx)
+	if err != nil {
+		return err
+	}
+	for label, err := range db.IterLabels() {
+		if err != nil {
+			return s.status(ctx, err)
+		}
+		if err := stream.Send(toLabel(label)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *labelsServer) RenameLabel(ctx context.Context, req *pb.RenameRequest) (*emptypb.Empty, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.UpdateLabelName(int(req.GetId()), req.GetName()); err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *labelsServer) DeleteLabel(ctx context.Context, req *pb.IDRequest) (*emptypb.Empty, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.DeleteLabel(int(req.GetId())); err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return &emptypb.Empty{}, nil
+}
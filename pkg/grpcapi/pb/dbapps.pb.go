@@ -0,0 +1,864 @@
+// gRPC API сервиса: пользователи, метки и задачи
+// Сообщения повторяют model.User, model.Label и model.Task
+// Проект задается метаданными x-project-id, токен - метаданными authorization: Bearer <токен>
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: dbapps.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type User struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Login       string                 `protobuf:"bytes,3,opt,name=login,proto3" json:"login,omitempty"`
+	Email       string                 `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	DisplayName string                 `protobuf:"bytes,5,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Active      bool                   `protobuf:"varint,6,opt,name=active,proto3" json:"active,omitempty"`
+	// admin, member или viewer
+	Role          string `protobuf:"bytes,7,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_dbapps_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_dbapps_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_dbapps_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *User) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *User) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *User) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+func (x *User) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *User) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *User) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *User) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type Label struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProjectId     int64                  `protobuf:"varint,2,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Label) Reset() {
+	*x = Label{}
+	mi := &file_dbapps_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Label) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Label) ProtoMessage() {}
+
+func (x *Label) ProtoReflect() protoreflect.Message {
+	mi := &file_dbapps_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Label.ProtoReflect.Descriptor instead.
+func (*Label) Descriptor() ([]byte, []int) {
+	return file_dbapps_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Label) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Label) GetProjectId() int64 {
+	if x != nil {
+		return x.ProjectId
+	}
+	return 0
+}
+
+func (x *Label) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type Task struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProjectId int64                  `protobuf:"varint,2,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Opened    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=opened,proto3" json:"opened,omitempty"`
+	// Не задано, если задача не закрыта
+	Closed        *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=closed,proto3" json:"closed,omitempty"`
+	AuthorId      int64                  `protobuf:"varint,5,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	AssignedId    int64                  `protobuf:"varint,6,opt,name=assigned_id,json=assignedId,proto3" json:"assigned_id,omitempty"`
+	Title         string                 `protobuf:"bytes,7,opt,name=title,proto3" json:"title,omitempty"`
+	Content       string                 `protobuf:"bytes,8,opt,name=content,proto3" json:"content,omitempty"`
+	LabelIds      []int64                `protobuf:"varint,9,rep,packed,name=label_ids,json=labelIds,proto3" json:"label_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_dbapps_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_dbapps_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_dbapps_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Task) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Task) GetProjectId() int64 {
+	if x != nil {
+		return x.ProjectId
+	}
+	return 0
+}
+
+func (x *Task) GetOpened() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Opened
+	}
+	return nil
+}
+
+func (x *Task) GetClosed() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Closed
+	}
+	return nil
+}
+
+func (x *Task) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *Task) GetAssignedId() int64 {
+	if x != nil {
+		return x.AssignedId
+	}
+	return 0
+}
+
+func (x *Task) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Task) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Task) GetLabelIds() []int64 {
+	if x != nil {
+		return x.LabelIds
+	}
+	return nil
+}
+
+type IDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IDRequest) Reset() {
+	*x = IDRequest{}
+	mi := &file_dbapps_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IDRequest) ProtoMessage() {}
+
+func (x *IDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dbapps_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IDRequest.ProtoReflect.Descriptor instead.
+func (*IDRequest) Descriptor() ([]byte, []int) {
+	return file_dbapps_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *IDRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type IDResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IDResponse) Reset() {
+	*x = IDResponse{}
+	mi := &file_dbapps_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IDResponse) ProtoMessage() {}
+
+func (x *IDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dbapps_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IDResponse.ProtoReflect.Descriptor instead.
+func (*IDResponse) Descriptor() ([]byte, []int) {
+	return file_dbapps_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *IDResponse) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_dbapps_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dbapps_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_dbapps_proto_rawDescGZIP(), []int{5}
+}
+
+type RenameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameRequest) Reset() {
+	*x = RenameRequest{}
+	mi := &file_dbapps_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameRequest) ProtoMessage() {}
+
+func (x *RenameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dbapps_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameRequest.ProtoReflect.Descriptor instead.
+func (*RenameRequest) Descriptor() ([]byte, []int) {
+	return file_dbapps_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RenameRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RenameRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteUserRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// reject (по умолчанию), reassign или unassign
+	Strategy      string `protobuf:"bytes,2,opt,name=strategy,proto3" json:"strategy,omitempty"`
+	ReassignTo    int64  `protobuf:"varint,3,opt,name=reassign_to,json=reassignTo,proto3" json:"reassign_to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUserRequest) Reset() {
+	*x = DeleteUserRequest{}
+	mi := &file_dbapps_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserRequest) ProtoMessage() {}
+
+func (x *DeleteUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dbapps_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserRequest.ProtoReflect.Descriptor instead.
+func (*DeleteUserRequest) Descriptor() ([]byte, []int) {
+	return file_dbapps_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteUserRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteUserRequest) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+func (x *DeleteUserRequest) GetReassignTo() int64 {
+	if x != nil {
+		return x.ReassignTo
+	}
+	return 0
+}
+
+type DeleteUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Authored      int32                  `protobuf:"varint,1,opt,name=authored,proto3" json:"authored,omitempty"`
+	Assigned      int32                  `protobuf:"varint,2,opt,name=assigned,proto3" json:"assigned,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUserResponse) Reset() {
+	*x = DeleteUserResponse{}
+	mi := &file_dbapps_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserResponse) ProtoMessage() {}
+
+func (x *DeleteUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dbapps_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserResponse.ProtoReflect.Descriptor instead.
+func (*DeleteUserResponse) Descriptor() ([]byte, []int) {
+	return file_dbapps_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteUserResponse) GetAuthored() int32 {
+	if x != nil {
+		return x.Authored
+	}
+	return 0
+}
+
+func (x *DeleteUserResponse) GetAssigned() int32 {
+	if x != nil {
+		return x.Assigned
+	}
+	return 0
+}
+
+type ListTasksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Фильтры, 0 - не задан. Одновременно можно задать только один
+	AuthorId      int64 `protobuf:"varint,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	LabelId       int64 `protobuf:"varint,2,opt,name=label_id,json=labelId,proto3" json:"label_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_dbapps_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dbapps_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_dbapps_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListTasksRequest) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetLabelId() int64 {
+	if x != nil {
+		return x.LabelId
+	}
+	return 0
+}
+
+type TaskLabelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        int64                  `protobuf:"varint,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	LabelId       int64                  `protobuf:"varint,2,opt,name=label_id,json=labelId,proto3" json:"label_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskLabelRequest) Reset() {
+	*x = TaskLabelRequest{}
+	mi := &file_dbapps_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskLabelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskLabelRequest) ProtoMessage() {}
+
+func (x *TaskLabelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dbapps_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskLabelRequest.ProtoReflect.Descriptor instead.
+func (*TaskLabelRequest) Descriptor() ([]byte, []int) {
+	return file_dbapps_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TaskLabelRequest) GetTaskId() int64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+func (x *TaskLabelRequest) GetLabelId() int64 {
+	if x != nil {
+		return x.LabelId
+	}
+	return 0
+}
+
+var File_dbapps_proto protoreflect.FileDescriptor
+
+const file_dbapps_proto_rawDesc = "" +
+	"\n" +
+	"\fdbapps.proto\x12\tdbapps.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa5\x01\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05login\x18\x03 \x01(\tR\x05login\x12\x14\n" +
+	"\x05email\x18\x04 \x01(\tR\x05email\x12!\n" +
+	"\fdisplay_name\x18\x05 \x01(\tR\vdisplayName\x12\x16\n" +
+	"\x06active\x18\x06 \x01(\bR\x06active\x12\x12\n" +
+	"\x04role\x18\a \x01(\tR\x04role\"J\n" +
+	"\x05Label\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x02 \x01(\x03R\tprojectId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\"\xa8\x02\n" +
+	"\x04Task\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x02 \x01(\x03R\tprojectId\x122\n" +
+	"\x06opened\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x06opened\x122\n" +
+	"\x06closed\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x06closed\x12\x1b\n" +
+	"\tauthor_id\x18\x05 \x01(\x03R\bauthorId\x12\x1f\n" +
+	"\vassigned_id\x18\x06 \x01(\x03R\n" +
+	"assignedId\x12\x14\n" +
+	"\x05title\x18\a \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\b \x01(\tR\acontent\x12\x1b\n" +
+	"\tlabel_ids\x18\t \x03(\x03R\blabelIds\"\x1b\n" +
+	"\tIDRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x1c\n" +
+	"\n" +
+	"IDResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\r\n" +
+	"\vListRequest\"3\n" +
+	"\rRenameRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"`\n" +
+	"\x11DeleteUserRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
+	"\bstrategy\x18\x02 \x01(\tR\bstrategy\x12\x1f\n" +
+	"\vreassign_to\x18\x03 \x01(\x03R\n" +
+	"reassignTo\"L\n" +
+	"\x12DeleteUserResponse\x12\x1a\n" +
+	"\bauthored\x18\x01 \x01(\x05R\bauthored\x12\x1a\n" +
+	"\bassigned\x18\x02 \x01(\x05R\bassigned\"J\n" +
+	"\x10ListTasksRequest\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\x03R\bauthorId\x12\x19\n" +
+	"\blabel_id\x18\x02 \x01(\x03R\alabelId\"F\n" +
+	"\x10TaskLabelRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\x03R\x06taskId\x12\x19\n" +
+	"\blabel_id\x18\x02 \x01(\x03R\alabelId2\xf0\x02\n" +
+	"\x05Users\x124\n" +
+	"\n" +
+	"CreateUser\x12\x0f.dbapps.v1.User\x1a\x15.dbapps.v1.IDResponse\x120\n" +
+	"\aGetUser\x12\x14.dbapps.v1.IDRequest\x1a\x0f.dbapps.v1.User\x126\n" +
+	"\tListUsers\x12\x16.dbapps.v1.ListRequest\x1a\x0f.dbapps.v1.User0\x01\x12>\n" +
+	"\n" +
+	"RenameUser\x12\x18.dbapps.v1.RenameRequest\x1a\x16.google.protobuf.Empty\x12<\n" +
+	"\x11UpdateUserProfile\x12\x0f.dbapps.v1.User\x1a\x16.google.protobuf.Empty\x12I\n" +
+	"\n" +
+	"DeleteUser\x12\x1c.dbapps.v1.DeleteUserRequest\x1a\x1d.dbapps.v1.DeleteUserResponse2\xac\x02\n" +
+	"\x06Labels\x126\n" +
+	"\vCreateLabel\x12\x10.dbapps.v1.Label\x1a\x15.dbapps.v1.IDResponse\x122\n" +
+	"\bGetLabel\x12\x14.dbapps.v1.IDRequest\x1a\x10.dbapps.v1.Label\x128\n" +
+	"\n" +
+	"ListLabels\x12\x16.dbapps.v1.ListRequest\x1a\x10.dbapps.v1.Label0\x01\x12?\n" +
+	"\vRenameLabel\x12\x18.dbapps.v1.RenameRequest\x1a\x16.google.protobuf.Empty\x12;\n" +
+	"\vDeleteLabel\x12\x14.dbapps.v1.IDRequest\x1a\x16.google.protobuf.Empty2\xa4\x03\n" +
+	"\x05Tasks\x124\n" +
+	"\n" +
+	"CreateTask\x12\x0f.dbapps.v1.Task\x1a\x15.dbapps.v1.IDResponse\x120\n" +
+	"\aGetTask\x12\x14.dbapps.v1.IDRequest\x1a\x0f.dbapps.v1.Task\x12;\n" +
+	"\tListTasks\x12\x1b.dbapps.v1.ListTasksRequest\x1a\x0f.dbapps.v1.Task0\x01\x125\n" +
+	"\n" +
+	"UpdateTask\x12\x0f.dbapps.v1.Task\x1a\x16.google.protobuf.Empty\x12:\n" +
+	"\n" +
+	"DeleteTask\x12\x14.dbapps.v1.IDRequest\x1a\x16.google.protobuf.Empty\x12?\n" +
+	"\bAddLabel\x12\x1b.dbapps.v1.TaskLabelRequest\x1a\x16.google.protobuf.Empty\x12B\n" +
+	"\vRemoveLabel\x12\x1b.dbapps.v1.TaskLabelRequest\x1a\x16.google.protobuf.EmptyB\x1bZ\x19DB_Apps/pkg/grpcapi/pb;pbb\x06proto3"
+
+var (
+	file_dbapps_proto_rawDescOnce sync.Once
+	file_dbapps_proto_rawDescData []byte
+)
+
+func file_dbapps_proto_rawDescGZIP() []byte {
+	file_dbapps_proto_rawDescOnce.Do(func() {
+		file_dbapps_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_dbapps_proto_rawDesc), len(file_dbapps_proto_rawDesc)))
+	})
+	return file_dbapps_proto_rawDescData
+}
+
+var file_dbapps_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_dbapps_proto_goTypes = []any{
+	(*User)(nil),                  // 0: dbapps.v1.User
+	(*Label)(nil),                 // 1: dbapps.v1.Label
+	(*Task)(nil),                  // 2: dbapps.v1.Task
+	(*IDRequest)(nil),             // 3: dbapps.v1.IDRequest
+	(*IDResponse)(nil),            // 4: dbapps.v1.IDResponse
+	(*ListRequest)(nil),           // 5: dbapps.v1.ListRequest
+	(*RenameRequest)(nil),         // 6: dbapps.v1.RenameRequest
+	(*DeleteUserRequest)(nil),     // 7: dbapps.v1.DeleteUserRequest
+	(*DeleteUserResponse)(nil),    // 8: dbapps.v1.DeleteUserResponse
+	(*ListTasksRequest)(nil),      // 9: dbapps.v1.ListTasksRequest
+	(*TaskLabelRequest)(nil),      // 10: dbapps.v1.TaskLabelRequest
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 12: google.protobuf.Empty
+}
+var file_dbapps_proto_depIdxs = []int32{
+	11, // 0: dbapps.v1.Task.opened:type_name -> google.protobuf.Timestamp
+	11, // 1: dbapps.v1.Task.closed:type_name -> google.protobuf.Timestamp
+	0,  // 2: dbapps.v1.Users.CreateUser:input_type -> dbapps.v1.User
+	3,  // 3: dbapps.v1.Users.GetUser:input_type -> dbapps.v1.IDRequest
+	5,  // 4: dbapps.v1.Users.ListUsers:input_type -> dbapps.v1.ListRequest
+	6,  // 5: dbapps.v1.Users.RenameUser:input_type -> dbapps.v1.RenameRequest
+	0,  // 6: dbapps.v1.Users.UpdateUserProfile:input_type -> dbapps.v1.User
+	7,  // 7: dbapps.v1.Users.DeleteUser:input_type -> dbapps.v1.DeleteUserRequest
+	1,  // 8: dbapps.v1.Labels.CreateLabel:input_type -> dbapps.v1.Label
+	3,  // 9: dbapps.v1.Labels.GetLabel:input_type -> dbapps.v1.IDRequest
+	5,  // 10: dbapps.v1.Labels.ListLabels:input_type -> dbapps.v1.ListRequest
+	6,  // 11: dbapps.v1.Labels.RenameLabel:input_type -> dbapps.v1.RenameRequest
+	3,  // 12: dbapps.v1.Labels.DeleteLabel:input_type -> dbapps.v1.IDRequest
+	2,  // 13: dbapps.v1.Tasks.CreateTask:input_type -> dbapps.v1.Task
+	3,  // 14: dbapps.v1.Tasks.GetTask:input_type -> dbapps.v1.IDRequest
+	9,  // 15: dbapps.v1.Tasks.ListTasks:input_type -> dbapps.v1.ListTasksRequest
+	2,  // 16: dbapps.v1.Tasks.UpdateTask:input_type -> dbapps.v1.Task
+	3,  // 17: dbapps.v1.Tasks.DeleteTask:input_type -> dbapps.v1.IDRequest
+	10, // 18: dbapps.v1.Tasks.AddLabel:input_type -> dbapps.v1.TaskLabelRequest
+	10, // 19: dbapps.v1.Tasks.RemoveLabel:input_type -> dbapps.v1.TaskLabelRequest
+	4,  // 20: dbapps.v1.Users.CreateUser:output_type -> dbapps.v1.IDResponse
+	0,  // 21: dbapps.v1.Users.GetUser:output_type -> dbapps.v1.User
+	0,  // 22: dbapps.v1.Users.ListUsers:output_type -> dbapps.v1.User
+	12, // 23: dbapps.v1.Users.RenameUser:output_type -> google.protobuf.Empty
+	12, // 24: dbapps.v1.Users.UpdateUserProfile:output_type -> google.protobuf.Empty
+	8,  // 25: dbapps.v1.Users.DeleteUser:output_type -> dbapps.v1.DeleteUserResponse
+	4,  // 26: dbapps.v1.Labels.CreateLabel:output_type -> dbapps.v1.IDResponse
+	1,  // 27: dbapps.v1.Labels.GetLabel:output_type -> dbapps.v1.Label
+	1,  // 28: dbapps.v1.Labels.ListLabels:output_type -> dbapps.v1.Label
+	12, // 29: dbapps.v1.Labels.RenameLabel:output_type -> google.protobuf.Empty
+	12, // 30: dbapps.v1.Labels.DeleteLabel:output_type -> google.protobuf.Empty
+	4,  // 31: dbapps.v1.Tasks.CreateTask:output_type -> dbapps.v1.IDResponse
+	2,  // 32: dbapps.v1.Tasks.GetTask:output_type -> dbapps.v1.Task
+	2,  // 33: dbapps.v1.Tasks.ListTasks:output_type -> dbapps.v1.Task
+	12, // 34: dbapps.v1.Tasks.UpdateTask:output_type -> google.protobuf.Empty
+	12, // 35: dbapps.v1.Tasks.DeleteTask:output_type -> google.protobuf.Empty
+	12, // 36: dbapps.v1.Tasks.AddLabel:output_type -> google.protobuf.Empty
+	12, // 37: dbapps.v1.Tasks.RemoveLabel:output_type -> google.protobuf.Empty
+	20, // [20:38] is the sub-list for method output_type
+	2,  // [2:20] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_dbapps_proto_init() }
+func file_dbapps_proto_init() {
+	if File_dbapps_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_dbapps_proto_rawDesc), len(file_dbapps_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   3,
+		},
+		GoTypes:           file_dbapps_proto_goTypes,
+		DependencyIndexes: file_dbapps_proto_depIdxs,
+		MessageInfos:      file_dbapps_proto_msgTypes,
+	}.Build()
+	File_dbapps_proto = out.File
+	file_dbapps_proto_goTypes = nil
+	file_dbapps_proto_depIdxs = nil
+}
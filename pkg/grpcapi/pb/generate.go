@@ -0,0 +1,4 @@
+// Пакет pb содержит сообщения и сервисы gRPC API, сгенерированные по dbapps.proto
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative dbapps.proto
@@ -0,0 +1,116 @@
+package grpcapi
+
+import (
+	"DB_Apps/pkg/grpcapi/pb"
+	"DB_Apps/pkg/model"
+	"context"
+	"iter"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// tasksServer - сервис Tasks
+type tasksServer struct {
+	pb.UnimplementedTasksServer
+	*Server
+}
+
+func (s *tasksServer) CreateTask(ctx context.Context, req *pb.Task) (*pb.IDResponse, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := db.NewTask(fromTask(req))
+	if err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return &pb.IDResponse{Id: int64(id)}, nil
+}
+
+func (s *tasksServer) GetTask(ctx context.Context, req *pb.IDRequest) (*pb.Task, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	task, err := db.SelectTaskByID(int(req.GetId()))
+	if err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return toTask(task), nil
+}
+
+// ListTasks передает потоком задачи проекта, автора или метки
+func (s *tasksServer) ListTasks(req *pb.ListTasksRequest, stream grpc.ServerStreamingServer[pb.Task]) error {
+	ctx := stream.Context()
+	db, err := s.storage(ctx)
+	if err != nil {
+		return err
+	}
+	var tasks iter.Seq2[model.Task, error]
+	switch {
+	case req.GetAuthorId() != 0 && req.GetLabelId() != 0:
+		return status.Error(codes.InvalidArgument, "Можно задать только один фильтр: author_id или label_id")
+	case req.GetAuthorId() != 0:
+		tasks = db.IterTasksByAuthorID(int(req.GetAuthorId()))
+	case req.GetLabelId() != 0:
+		tasks = db.IterTasksByLabelID(int(req.GetLabelId()))
+	default:
+		tasks = db.IterTasks()
+	}
+	for task, err := range tasks {
+		if err != nil {
+			return s.status(ctx, err)
+		}
+		if err := stream.Send(toTask(task)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *tasksServer) UpdateTask(ctx context.Context, req *pb.Task) (*emptypb.Empty, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.UpdateTaskByID(fromTask(req)); err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *tasksServer) DeleteTask(ctx context.Context, req *pb.IDRequest) (*emptypb.Empty, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.DeleteTask(int(req.GetId())); err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *tasksServer) AddLabel(ctx context.Context, req *pb.TaskLabelRequest) (*emptypb.Empty, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AddLabelToTask(int(req.GetLabelId()), int(req.GetTaskId())); err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *tasksServer) RemoveLabel(ctx context.Context, req *pb.TaskLabelRequest) (*emptypb.Empty, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.DeleteLabelToTask(int(req.GetLabelId()), int(req.GetTaskId())); err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,94 @@
+package grpcapi
+
+import (
+	"DB_Apps/pkg/grpcapi/pb"
+	"DB_Apps/pkg/storage"
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// usersServer - сервис Users
+type usersServer struct {
+	pb.UnimplementedUsersServer
+	*Server
+}
+
+func (s *usersServer) CreateUser(ctx context.Context, req *pb.User) (*pb.IDResponse, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := db.NewUser(fromUser(req))
+	if err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return &pb.IDResponse{Id: int64(id)}, nil
+}
+
+func (s *usersServer) GetUser(ctx context.Context, req *pb.IDRequest) (*pb.User, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := db.SelectUserByID(int(req.GetId()))
+	if err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return toUser(user), nil
+}
+
+func (s *usersServer) ListUsers(_ *pb.ListRequest, stream grpc.ServerStreamingServer[pb.User]) error {
+	ctx := stream.Context()
+	db, err := s.storage(ctx)
+	if err != nil {
+		return err
+	}
+	for user, err := range db.IterUsers() {
+		if err != nil {
+			return s.status(ctx, err)
+		}
+		if err := stream.Send(toUser(user)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *usersServer) RenameUser(ctx context.Context, req *pb.RenameRequest) (*emptypb.Empty, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.UpdateUserName(int(req.GetId()), req.GetName()); err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *usersServer) UpdateUserProfile(ctx context.Context, req *pb.User) (*emptypb.Empty, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.UpdateUserProfile(fromUser(req)); err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *usersServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	db, err := s.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report, err := db.DeleteUser(int(req.GetId()), storage.DeleteUserOptions{
+		Strategy:   storage.DeleteStrategy(req.GetStrategy()),
+		ReassignTo: int(req.GetReassignTo()),
+	})
+	if err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return &pb.DeleteUserResponse{Authored: int32(report.Authored), Assigned: int32(report.Assigned)}, nil
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Таблица вложений задач: метаданные файла, содержимое хранится в хранилище содержимого
+type Attachment struct {
+	ID     int
+	TaskID int
+	// Пользователь, загрузивший файл
+	UploaderID int
+	// Имя файла без каталогов
+	Name     string
+	Size     int64
+	MIMEType string
+	// Хеш SHA-256 содержимого
+	SHA256  []byte
+	Created time.Time
+}
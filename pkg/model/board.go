@@ -0,0 +1,49 @@
+package model
+
+// BoardKind - вид доски: чему соответствуют ее колонки
+type BoardKind string
+
+const (
+	// Колонки соответствуют состоянию задачи (открыта или закрыта)
+	BoardByStatus BoardKind = "status"
+	// Колонки соответствуют меткам задачи
+	BoardByLabel BoardKind = "label"
+)
+
+// Таблица досок задач проекта
+type Board struct {
+	ID        int
+	ProjectID int
+	Name      string
+	Kind      BoardKind
+	// Колонки в порядке отображения
+	Columns []BoardColumn
+}
+
+// Таблица колонок доски
+// В колонке доски BoardByStatus задано Status (StateOpen или StateClosed), доски BoardByLabel - LabelID
+// Несколько колонок могут соответствовать одному состоянию, тогда задача без положения попадает в первую из них
+type BoardColumn struct {
+	ID      int
+	BoardID int
+	Name    string
+	Status  TaskState
+	LabelID int
+}
+
+// BoardCard - задача в колонке доски
+type BoardCard struct {
+	ColumnID int
+	// Дробный ранг задачи в колонке, пустой у задач, которые еще не перемещались (они идут после остальных по ID)
+	Rank string
+	Task Task
+}
+
+// Параметры перемещения задачи TaskID в колонку ColumnID доски BoardID
+type BoardMove struct {
+	BoardID  int
+	TaskID   int
+	ColumnID int
+	// Задача колонки, после которой встает перемещаемая, 0 - в начало колонки
+	AfterID int
+}
@@ -2,6 +2,7 @@ package model
 
 // Таблица метки
 type Label struct {
-	ID   int
-	Name string
+	ID        int
+	ProjectID int
+	Name      string
 }
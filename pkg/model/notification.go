@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// NotificationKind - причина уведомления
+type NotificationKind string
+
+const (
+	// Пользователь назначен исполнителем задачи
+	NotifyAssigned NotificationKind = "assigned"
+	// Пользователь упомянут как @Имя в задаче или комментарии к записи о времени
+	NotifyMentioned NotificationKind = "mentioned"
+	// Изменилась задача, на которую подписан пользователь
+	NotifyTaskChanged NotificationKind = "task_changed"
+)
+
+// Таблица уведомлений пользователей
+// Заголовок задачи сохраняется на момент уведомления, так как входящие пользователя общие для всех проектов
+type Notification struct {
+	ID        int
+	UserID    int
+	ProjectID int
+	TaskID    int
+	// Пользователь, изменивший задачу, 0 - неизвестен
+	ActorID   int
+	Kind      NotificationKind
+	TaskTitle string
+	Text      string
+	Created   time.Time
+	// Время прочтения, nil - не прочитано
+	Read *time.Time
+}
+
+// NotificationQuery - параметры выборки входящих уведомлений пользователя
+type NotificationQuery struct {
+	UserID int
+	// Только непрочитанные
+	UnreadOnly bool
+	// Наибольшее количество уведомлений, 0 - значение по умолчанию хранилища
+	Limit int
+}
@@ -0,0 +1,9 @@
+package model
+
+// Таблица проектов
+// Задачи и метки принадлежат проекту, пользователи общие и участвуют в проектах через project_members
+type Project struct {
+	ID          int
+	Name        string
+	Description string
+}
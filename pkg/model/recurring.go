@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Таблица шаблонов повторяющихся задач
+type RecurringTask struct {
+	ID        int
+	ProjectID int
+	// Расписание в формате cron: 5 полей или @hourly, @daily, @weekly, @monthly
+	// Часовой пояс задается префиксом CRON_TZ=, например "CRON_TZ=Europe/Moscow 0 9 * * 1"
+	Schedule string
+	// Поля создаваемой задачи
+	AuthorID   int
+	AssignedID int
+	Title      string
+	Content    string
+	LabelsID   []int
+	// Время следующего создания задачи
+	NextRun time.Time
+	// Время последнего запуска, nil - задачи по шаблону еще не создавались
+	LastRun *time.Time
+	// Неактивный шаблон планировщик пропускает
+	Active bool
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// TaskCounts - количество открытых и закрытых задач
+type TaskCounts struct {
+	Open   int
+	Closed int
+}
+
+// AssigneeStats - задачи исполнителя
+type AssigneeStats struct {
+	UserID int
+	Name   string
+	TaskCounts
+}
+
+// LabelStats - задачи с меткой
+type LabelStats struct {
+	LabelID int
+	Name    string
+	TaskCounts
+}
+
+// CloseTimeStats - время от открытия до закрытия задач
+type CloseTimeStats struct {
+	// Количество закрытых задач
+	Closed  int
+	Average time.Duration
+	Max     time.Duration
+}
+
+// Period - интервал группировки задач по времени
+type Period string
+
+const (
+	PeriodDay  Period = "day"
+	PeriodWeek Period = "week"
+)
+
+// FlowQuery - параметры отчета об открытых и закрытых задачах по периодам
+type FlowQuery struct {
+	Period Period
+	// Интервал [From, To)
+	From time.Time
+	To   time.Time
+	// Часовой пояс IANA (например Europe/Moscow), в котором определяются границы дней и недель
+	TimeZone string
+}
+
+// FlowPoint - количество задач, открытых и закрытых за период, начинающийся в Start
+type FlowPoint struct {
+	Start  time.Time
+	Opened int
+	Closed int
+}
@@ -1,13 +1,32 @@
 package model
 
+import "time"
+
 // Таблица задач
 type Task struct {
-	ID         int
-	Opened     int64
-	Closed     int64
+	ID        int
+	ProjectID int
+	// Время создания задачи
+	Opened time.Time
+	// Время закрытия задачи, nil - задача не закрыта
+	Closed     *time.Time
 	AuthorID   int
 	AssignedID int
 	Title      string
 	Content    string
 	LabelsID   []int
+	// Оценка трудоемкости, 0 - не задана
+	Estimate time.Duration
+}
+
+// Параметры постраничной выборки задач (SelectTasksPage)
+// Нулевые AuthorID, AssignedID и LabelID означают отсутствие фильтра
+type TaskPageQuery struct {
+	AuthorID   int
+	AssignedID int
+	LabelID    int
+	// Выбираются задачи с ID больше AfterID (курсор предыдущей страницы)
+	AfterID int
+	// Наибольшее количество задач на странице
+	Limit int
 }
@@ -0,0 +1,16 @@
+package model
+
+// Таблица шаблонов задач
+type TaskTemplate struct {
+	ID        int
+	ProjectID int
+	// Уникальное в проекте название шаблона, например "Ошибка" или "Запрос на доработку"
+	Name string
+	// Заголовок задачи с переменными {{имя}}, например "Ошибка: {{summary}}"
+	TitlePattern string
+	// Заготовка описания задачи, также может содержать переменные
+	Content string
+	// Исполнитель и метки создаваемой задачи по умолчанию
+	AssignedID int
+	LabelsID   []int
+}
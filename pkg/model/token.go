@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Виды токенов
+const (
+	TokenSession = "session"
+	TokenAPI     = "api"
+)
+
+// Таблица токенов аутентификации
+type Token struct {
+	ID     int
+	UserID int
+	Kind   string
+	Name   string
+	// SHA-256 токена
+	Hash      []byte
+	CreatedAt time.Time
+	// Нулевое значение - срок действия не ограничен
+	ExpiresAt time.Time
+	// Нулевое значение - токен не отозван
+	RevokedAt time.Time
+}
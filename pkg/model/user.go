@@ -1,7 +1,38 @@
 package model
 
+// Role - роль пользователя, определяет разрешенные ему действия
+type Role string
+
+const (
+	// RoleAdmin - администратор, разрешены все действия
+	RoleAdmin Role = "admin"
+	// RoleMember - участник, работает со своими задачами и метками
+	RoleMember Role = "member"
+	// RoleViewer - наблюдатель, доступно только чтение
+	RoleViewer Role = "viewer"
+)
+
+// Valid сообщает, является ли r одной из известных ролей
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleMember, RoleViewer:
+		return true
+	}
+	return false
+}
+
 // Таблица пользователей
 type User struct {
 	ID   int
 	Name string
+	// Логин для входа, уникален без учета регистра. Пустая строка - не задан
+	Login string
+	// Адрес электронной почты, уникален без учета регистра. Пустая строка - не задан
+	Email string
+	// Отображаемое имя. Пустая строка - используется Name
+	DisplayName string
+	// Активен ли пользователь
+	Active bool
+	// Роль пользователя. Пустая строка при создании - RoleMember
+	Role Role
 }
@@ -0,0 +1,53 @@
+package model
+
+// TaskState - состояние задач в фильтре
+type TaskState string
+
+const (
+	// Все задачи
+	StateAll TaskState = ""
+	// Только открытые задачи
+	StateOpen TaskState = "open"
+	// Только закрытые задачи
+	StateClosed TaskState = "closed"
+)
+
+// TaskSort - порядок задач в фильтре, "-" перед полем - по убыванию
+type TaskSort string
+
+const (
+	SortID         TaskSort = "id"
+	SortIDDesc     TaskSort = "-id"
+	SortOpened     TaskSort = "opened"
+	SortOpenedDesc TaskSort = "-opened"
+	SortClosed     TaskSort = "closed"
+	SortClosedDesc TaskSort = "-closed"
+	SortTitle      TaskSort = "title"
+	SortTitleDesc  TaskSort = "-title"
+)
+
+// TaskFilter - фильтр задач проекта, хранится в сохраненном представлении в формате JSON
+// Незаданные поля не ограничивают выборку
+type TaskFilter struct {
+	AuthorID   *int `json:"author_id,omitempty"`
+	AssignedID *int `json:"assigned_id,omitempty"`
+	// Задачи, у которых есть все перечисленные метки
+	LabelsID []int     `json:"label_ids,omitempty"`
+	State    TaskState `json:"state,omitempty"`
+	// Текст, который ищется в заголовке и описании без учета регистра
+	Query string `json:"query,omitempty"`
+	// Порядок задач, по умолчанию - по возрастанию ID
+	Sort TaskSort `json:"sort,omitempty"`
+}
+
+// Таблица сохраненных представлений (фильтров задач) пользователей
+type SavedView struct {
+	ID        int
+	ProjectID int
+	// Владелец представления
+	OwnerID int
+	Name    string
+	Filter  TaskFilter
+	// Общее представление видят и выполняют все участники проекта, изменяет только владелец
+	Shared bool
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// Таблица записей о затраченном на задачу времени
+type Worklog struct {
+	ID     int
+	TaskID int
+	// Пользователь, который работал над задачей
+	UserID int
+	// Начало работы
+	Started  time.Time
+	Duration time.Duration
+	Comment  string
+}
+
+// TaskTimeStats - оценка задачи и затраченное на нее время
+type TaskTimeStats struct {
+	TaskID     int
+	Title      string
+	AssignedID int
+	Estimate   time.Duration
+	Spent      time.Duration
+}
+
+// UserTimeStats - суммарная оценка задач исполнителя и время, затраченное пользователем на задачи проекта
+type UserTimeStats struct {
+	UserID   int
+	Name     string
+	Estimate time.Duration
+	Spent    time.Duration
+}
+
+// TimesheetQuery - параметры табеля: записи о времени, начатые в интервале [From, To)
+type TimesheetQuery struct {
+	From time.Time
+	To   time.Time
+	// Пользователь, 0 - все пользователи
+	UserID int
+}
+
+// TimesheetEntry - строка табеля: запись о времени с именем пользователя и заголовком задачи
+type TimesheetEntry struct {
+	Worklog
+	UserName  string
+	TaskTitle string
+}
@@ -1,6 +1,7 @@
 package myerrors
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -17,3 +18,24 @@ func (e TaskPartialErr) Error() string {
 	}
 	return fmt.Sprintf("\n\t- Ошибка: %s", strings.Join(msgs, "\n\t- Ошибка: "))
 }
+
+// NotFoundErr - общая ошибка отсутствия записи
+// Ошибки, созданные через NotFound, проверяются с помощью errors.Is(err, NotFoundErr)
+var NotFoundErr = errors.New("Запись не найдена")
+
+type notFoundErr struct {
+	msg string
+}
+
+func (e notFoundErr) Error() string {
+	return e.msg
+}
+
+func (e notFoundErr) Is(target error) bool {
+	return target == NotFoundErr
+}
+
+// NotFound формирует ошибку отсутствия записи с текстом по формату format
+func NotFound(format string, a ...any) error {
+	return notFoundErr{msg: fmt.Sprintf(format, a...)}
+}
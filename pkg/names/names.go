@@ -0,0 +1,141 @@
+// Пакет names содержит правила проверки и нормализации имен пользователей
+package names
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Ошибки проверки имени
+var (
+	EmptyErr        = errors.New("Пустое поле имени")
+	CyrillicOnlyErr = errors.New("В имени допускается только Кириллица")
+	LettersOnlyErr  = errors.New("В имени допускаются только буквы, пробелы, дефис и апостроф")
+	PatternErr      = errors.New("Имя не соответствует шаблону")
+	SeparatorErr    = errors.New("Дефис и апостроф допускаются только между буквами")
+)
+
+// IsInvalid сообщает, что err - ошибка проверки имени
+func IsInvalid(err error) bool {
+	return errors.Is(err, EmptyErr) || errors.Is(err, CyrillicOnlyErr) || errors.Is(err, LettersOnlyErr) ||
+		errors.Is(err, PatternErr) || errors.Is(err, SeparatorErr)
+}
+
+// Validator проверяет имя пользователя и приводит его к единому виду
+type Validator interface {
+	// Normalize возвращает нормализованное имя или ошибку, если имя недопустимо
+	Normalize(name string) (string, error)
+}
+
+// Policy - правило проверки имени, общее для всех встроенных политик:
+// 1. Имя приводится к форме NFC, пробелы по краям удаляются, подряд идущие пробелы сводятся к одному
+// 2. Каждый символ проверяется функцией allowed
+// 3. Дефис и апостроф должны стоять между буквами (Петров-Водкин, Д'Артаньян)
+// 4. Если задан шаблон, то имя должно ему соответствовать целиком
+// 5. Каждая часть имени (в том числе после дефиса) пишется с заглавной буквы по правилам языка,
+// результат снова приводится к форме NFC
+type Policy struct {
+	allowed    func(rune) bool
+	invalidErr error
+	pattern    *regexp.Regexp
+	lang       language.Tag
+}
+
+// CyrillicStrict - только кириллица (включая ё/Ё), пробел, дефис и апостроф
+// Используется по умолчанию
+func CyrillicStrict() Policy {
+	return Policy{
+		allowed: func(r rune) bool {
+			return unicode.Is(unicode.Cyrillic, r) && unicode.IsLetter(r) || isSeparator(r) || r == ' '
+		},
+		invalidErr: CyrillicOnlyErr,
+		lang:       language.Russian,
+	}
+}
+
+// UnicodeLetters - буквы любых алфавитов, диакритические знаки, пробел, дефис и апостроф
+func UnicodeLetters() Policy {
+	return Policy{
+		allowed: func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.Is(unicode.Mn, r) || isSeparator(r) || r == ' '
+		},
+		invalidErr: LettersOnlyErr,
+		lang:       language.Und,
+	}
+}
+
+// Regexp - буквы любых алфавитов, дополнительно имя целиком должно соответствовать шаблону pattern
+// Шаблон проверяется после нормализации пробелов, но до изменения регистра
+func Regexp(pattern string) (Policy, error) {
+	re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return Policy{}, fmt.Errorf("Некорректный шаблон имени: %w", err)
+	}
+	p := UnicodeLetters()
+	p.pattern = re
+	return p, nil
+}
+
+// Normalize проверяет имя по правилу и возвращает его нормализованным
+func (p Policy) Normalize(name string) (string, error) {
+	name = strings.Join(strings.Fields(norm.NFC.String(name)), " ")
+	if name == "" {
+		return "", EmptyErr
+	}
+
+	for _, r := range name {
+		if !p.allowed(r) {
+			return "", p.invalidErr
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		if isSeparator(r) && (i == len(runes)-1 || !letterBefore(runes, i) || !unicode.IsLetter(runes[i+1])) {
+			return "", SeparatorErr
+		}
+	}
+
+	if p.pattern != nil && !p.pattern.MatchString(name) {
+		return "", PatternErr
+	}
+
+	// При смене регистра буква может разложиться на несколько символов (ΰ -> Ϋ́), поэтому форма NFC восстанавливается
+	return norm.NFC.String(titleCase(name, p.lang)), nil
+}
+
+// titleCase переводит каждую часть имени в нижний регистр с заглавной первой буквой
+// Частями считаются слова, а также фрагменты, разделенные дефисом или апострофом
+func titleCase(name string, lang language.Tag) string {
+	c := cases.Title(lang)
+	var b strings.Builder
+	start := 0
+	for i, r := range name {
+		if r == ' ' || isSeparator(r) {
+			b.WriteString(c.String(name[start:i]))
+			b.WriteRune(r)
+			start = i + len(string(r))
+		}
+	}
+	b.WriteString(c.String(name[start:]))
+	return b.String()
+}
+
+// letterBefore сообщает, что перед runes[i] стоит буква, возможно с диакритическими знаками (J̌)
+func letterBefore(runes []rune, i int) bool {
+	for i--; i >= 0 && unicode.Is(unicode.M, runes[i]); i-- {
+	}
+	return i >= 0 && unicode.IsLetter(runes[i])
+}
+
+// isSeparator сообщает, является ли r допустимым разделителем внутри слова
+func isSeparator(r rune) bool {
+	return r == '-' || r == '\'' || r == '’'
+}
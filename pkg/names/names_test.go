@@ -0,0 +1,176 @@
+package names
+
+import (
+	"errors"
+	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestCyrillicStrict(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+		err  error
+	}{
+		{name: "простое имя", in: "иван", want: "Иван"},
+		{name: "ё", in: "семён", want: "Семён"},
+		{name: "Ё", in: "ЁЖИКОВ", want: "Ёжиков"},
+		{name: "двойная фамилия", in: "петров-водкин", want: "Петров-Водкин"},
+		{name: "апостроф", in: "д'артаньян", want: "Д'Артаньян"},
+		{name: "типографский апостроф", in: "д’артаньян", want: "Д’Артаньян"},
+		{name: "несколько слов", in: "  анна   мария  ", want: "Анна Мария"},
+		{name: "разложенная й", in: "йгорь", want: "Йгорь"},
+		{name: "латиница", in: "John Doe", err: CyrillicOnlyErr},
+		{name: "смешанные алфавиты", in: "Иван Doe", err: CyrillicOnlyErr},
+		{name: "цифры", in: "Иван2", err: CyrillicOnlyErr},
+		{name: "пустое", in: "", err: EmptyErr},
+		{name: "только пробелы", in: " \t\n ", err: EmptyErr},
+		{name: "дефис в начале", in: "-Иван", err: SeparatorErr},
+		{name: "дефис в конце", in: "Иван-", err: SeparatorErr},
+		{name: "двойной дефис", in: "Петров--Водкин", err: SeparatorErr},
+		{name: "дефис и апостроф подряд", in: "Д'-Артаньян", err: SeparatorErr},
+		{name: "дефис перед пробелом", in: "Петров- Водкин", err: SeparatorErr},
+		{name: "одиночный апостроф", in: "'", err: SeparatorErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CyrillicStrict().Normalize(tt.in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Normalize(%q) error = %v, want %v", tt.in, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnicodeLetters(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+		err  error
+	}{
+		{name: "латиница", in: "john doe", want: "John Doe"},
+		{name: "кириллица", in: "петров-водкин", want: "Петров-Водкин"},
+		{name: "апостроф", in: "o'brien", want: "O'Brien"},
+		{name: "диакритика", in: "josé", want: "José"},
+		{name: "разложенная диакритика", in: "josé", want: "José"},
+		{name: "греческий", in: "ΑΛΈΞΑΝΔΡΟΣ", want: "Αλέξανδρος"},
+		{name: "результат в NFC", in: "ΰ", want: "\u03ab\u0301"},
+		{name: "знак перед апострофом", in: "ǰ'a", want: "J\u030c'A"},
+		{name: "цифры", in: "John 2", err: LettersOnlyErr},
+		{name: "знаки", in: "John_Doe", err: LettersOnlyErr},
+		{name: "пустое", in: "  ", err: EmptyErr},
+		{name: "апостроф в конце", in: "John'", err: SeparatorErr},
+		{name: "двойной апостроф", in: "O''Brien", err: SeparatorErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnicodeLetters().Normalize(tt.in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Normalize(%q) error = %v, want %v", tt.in, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegexp(t *testing.T) {
+	// Имя и фамилия через один пробел
+	p, err := Regexp(`\p{L}+ \p{L}+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		in   string
+		want string
+		err  error
+	}{
+		{name: "соответствует", in: "john  doe", want: "John Doe"},
+		{name: "кириллица", in: "анна петрова", want: "Анна Петрова"},
+		{name: "одно слово", in: "John", err: PatternErr},
+		{name: "три слова", in: "John Ronald Doe", err: PatternErr},
+		{name: "дефис не входит в шаблон", in: "Anna-Maria Doe", err: PatternErr},
+		{name: "цифры проверяются до шаблона", in: "John D0e", err: LettersOnlyErr},
+		{name: "пустое", in: "", err: EmptyErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Normalize(tt.in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Normalize(%q) error = %v, want %v", tt.in, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegexpInvalidPattern(t *testing.T) {
+	if _, err := Regexp(`(`); err == nil {
+		t.Error("Regexp(\"(\") error = nil, want ошибку")
+	}
+}
+
+func TestIsInvalid(t *testing.T) {
+	for _, err := range []error{EmptyErr, CyrillicOnlyErr, LettersOnlyErr, PatternErr, SeparatorErr} {
+		if !IsInvalid(err) {
+			t.Errorf("IsInvalid(%v) = false", err)
+		}
+	}
+	if IsInvalid(errors.New("другая ошибка")) {
+		t.Error("IsInvalid(другая ошибка) = true")
+	}
+}
+
+func FuzzNormalize(f *testing.F) {
+	for _, s := range []string{"иван", "Семён", "ЁЖИКОВ", "Петров-Водкин", "Д'Артаньян", "д’артаньян",
+		"John Doe", "  анна   мария  ", "josé", "josé", "ǆemal", "straße", "--", "'", "", "\xff"} {
+		f.Add(s)
+	}
+	// Шаблон допускает диакритические знаки: при смене регистра буква может разложиться (ǰ -> J̌)
+	re, err := Regexp(`[\p{L}\p{M}]+( [\p{L}\p{M}]+)*`)
+	if err != nil {
+		f.Fatal(err)
+	}
+	policies := map[string]Policy{
+		"CyrillicStrict": CyrillicStrict(),
+		"UnicodeLetters": UnicodeLetters(),
+		"Regexp":         re,
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		for policyName, p := range policies {
+			got, err := p.Normalize(name)
+			if err != nil {
+				if !IsInvalid(err) {
+					t.Errorf("%s: Normalize(%q) error = %v, want ошибку проверки имени", policyName, name, err)
+				}
+				continue
+			}
+			if got == "" {
+				t.Errorf("%s: Normalize(%q) = \"\" без ошибки", policyName, name)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("%s: Normalize(%q) = %q, не UTF-8", policyName, name, got)
+			}
+			if !norm.NFC.IsNormalString(got) {
+				t.Errorf("%s: Normalize(%q) = %q, не в форме NFC", policyName, name, got)
+			}
+			again, err := p.Normalize(got)
+			if err != nil {
+				t.Errorf("%s: Normalize(%q) error = %v для уже нормализованного имени", policyName, got, err)
+			} else if again != got {
+				t.Errorf("%s: Normalize(%q) = %q, повторная нормализация дает %q", policyName, name, got, again)
+			}
+		}
+	})
+}
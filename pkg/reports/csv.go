@@ -0,0 +1,105 @@
+package reports
+
+import (
+	"DB_Apps/pkg/model"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// writeCSV записывает в w заголовок header и строки rows, преобразованные функцией record
+func writeCSV[T any](w io.Writer, header []string, rows []T, record func(T) []string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := cw.Write(record(row)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// AssigneeCSV записывает отчет по исполнителям в формате CSV
+func AssigneeCSV(w io.Writer, rows []model.AssigneeStats) error {
+	return writeCSV(w, []string{"user_id", "name", "open", "closed"}, rows, func(r model.AssigneeStats) []string {
+		return []string{strconv.Itoa(r.UserID), r.Name, strconv.Itoa(r.Open), strconv.Itoa(r.Closed)}
+	})
+}
+
+// LabelCSV записывает отчет по меткам в формате CSV
+func LabelCSV(w io.Writer, rows []model.LabelStats) error {
+	return writeCSV(w, []string{"label_id", "name", "open", "closed"}, rows, func(r model.LabelStats) []string {
+		return []string{strconv.Itoa(r.LabelID), r.Name, strconv.Itoa(r.Open), strconv.Itoa(r.Closed)}
+	})
+}
+
+// TimeToCloseCSV записывает время до закрытия задач в формате CSV, длительности - в секундах
+func TimeToCloseCSV(w io.Writer, s model.CloseTimeStats) error {
+	return writeCSV(w, []string{"closed", "average_seconds", "max_seconds"}, []model.CloseTimeStats{s},
+		func(s model.CloseTimeStats) []string {
+			return []string{strconv.Itoa(s.Closed), seconds(s.Average), seconds(s.Max)}
+		})
+}
+
+// FlowCSV записывает отчет по периодам в формате CSV, начало периода - в формате RFC 3339
+func FlowCSV(w io.Writer, points []model.FlowPoint) error {
+	return writeCSV(w, []string{"period_start", "opened", "closed"}, points, func(p model.FlowPoint) []string {
+		return []string{p.Start.Format(time.RFC3339), strconv.Itoa(p.Opened), strconv.Itoa(p.Closed)}
+	})
+}
+
+// TasksCSV записывает задачи в формате CSV, время - в часовом поясе loc в формате RFC 3339
+// Для открытых задач столбец closed пустой
+func TasksCSV(w io.Writer, tasks []model.Task, loc *time.Location) error {
+	if loc == nil {
+		loc = time.UTC
+	}
+	header := []string{"id", "project_id", "title", "author_id", "assigned_id", "opened", "closed"}
+	return writeCSV(w, header, tasks, func(t model.Task) []string {
+		closed := ""
+		if t.Closed != nil {
+			closed = t.Closed.In(loc).Format(time.RFC3339)
+		}
+		return []string{strconv.Itoa(t.ID), strconv.Itoa(t.ProjectID), t.Title, strconv.Itoa(t.AuthorID),
+			strconv.Itoa(t.AssignedID), t.Opened.In(loc).Format(time.RFC3339), closed}
+	})
+}
+
+// TimeByTaskCSV записывает оценку и затраченное время по задачам в формате CSV, длительности - в часах
+func TimeByTaskCSV(w io.Writer, rows []model.TaskTimeStats) error {
+	header := []string{"task_id", "title", "assigned_id", "estimate_hours", "spent_hours"}
+	return writeCSV(w, header, rows, func(r model.TaskTimeStats) []string {
+		return []string{strconv.Itoa(r.TaskID), r.Title, strconv.Itoa(r.AssignedID), hours(r.Estimate), hours(r.Spent)}
+	})
+}
+
+// TimeByUserCSV записывает оценку и затраченное время по пользователям в формате CSV, длительности - в часах
+func TimeByUserCSV(w io.Writer, rows []model.UserTimeStats) error {
+	header := []string{"user_id", "name", "estimate_hours", "spent_hours"}
+	return writeCSV(w, header, rows, func(r model.UserTimeStats) []string {
+		return []string{strconv.Itoa(r.UserID), r.Name, hours(r.Estimate), hours(r.Spent)}
+	})
+}
+
+// TimesheetCSV записывает табель в формате CSV: начало работы - в формате RFC 3339, длительность - в часах
+func TimesheetCSV(w io.Writer, entries []model.TimesheetEntry) error {
+	header := []string{"date", "started", "user_id", "user", "task_id", "task", "hours", "comment"}
+	return writeCSV(w, header, entries, func(e model.TimesheetEntry) []string {
+		return []string{e.Started.Format(time.DateOnly), e.Started.Format(time.RFC3339), strconv.Itoa(e.UserID),
+			e.UserName, strconv.Itoa(e.TaskID), e.TaskTitle, hours(e.Duration), e.Comment}
+	})
+}
+
+// hours выводит длительность в часах с двумя знаками после точки
+func hours(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours(), 'f', 2, 64)
+}
+
+// seconds выводит длительность в секундах без дробной части
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
@@ -0,0 +1,149 @@
+// Пакет reports строит отчеты по задачам проекта поверх storage.Interface:
+// счетчики по исполнителям и меткам, время до закрытия, открытые и закрытые задачи по периодам,
+// самые старые открытые задачи, затраченное на задачи время и табель.
+// Отчеты возвращаются структурами и выводятся в CSV
+package reports
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Ошибки параметров отчетов
+var (
+	PeriodErr = errors.New("Период отчета должен быть day или week")
+	RangeErr  = errors.New("Некорректный интервал отчета")
+)
+
+// Количество самых старых открытых задач по умолчанию
+const DefaultOldestLimit = 10
+
+// Наибольшее количество периодов в отчете Flow
+const MaxFlowPoints = 1000
+
+// Reports строит отчеты по задачам проекта хранилища db
+// Время в отчетах приводится к часовому поясу loc
+type Reports struct {
+	db  storage.Interface
+	loc *time.Location
+}
+
+// New создает построитель отчетов
+// loc должен быть часовым поясом IANA (time.LoadLocation), nil или time.Local - UTC,
+// так как границы дней и недель вычисляет БД по имени пояса
+func New(db storage.Interface, loc *time.Location) *Reports {
+	if loc == nil || loc == time.Local {
+		loc = time.UTC
+	}
+	return &Reports{db: db, loc: loc}
+}
+
+// Summary - сводный отчет по проекту
+type Summary struct {
+	ByAssignee  []model.AssigneeStats
+	ByLabel     []model.LabelStats
+	TimeToClose model.CloseTimeStats
+	OldestOpen  []model.Task
+}
+
+// ByAssignee возвращает количество открытых и закрытых задач по исполнителям
+func (r *Reports) ByAssignee() ([]model.AssigneeStats, error) {
+	return r.db.ReportByAssignee()
+}
+
+// ByLabel возвращает количество открытых и закрытых задач по меткам
+func (r *Reports) ByLabel() ([]model.LabelStats, error) {
+	return r.db.ReportByLabel()
+}
+
+// TimeToClose возвращает среднее и максимальное время от открытия до закрытия задач
+func (r *Reports) TimeToClose() (model.CloseTimeStats, error) {
+	return r.db.ReportTimeToClose()
+}
+
+// Flow возвращает количество задач, открытых и закрытых за каждый день или неделю интервала [from, to)
+// Недели начинаются с понедельника, начало периода возвращается в часовом поясе отчетов
+func (r *Reports) Flow(period model.Period, from, to time.Time) ([]model.FlowPoint, error) {
+	step := 24 * time.Hour
+	switch period {
+	case model.PeriodDay:
+	case model.PeriodWeek:
+		step *= 7
+	default:
+		return nil, fmt.Errorf("%w: %q", PeriodErr, period)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: начало %s не раньше конца %s", RangeErr, from, to)
+	}
+	if to.Sub(from)/step > MaxFlowPoints {
+		return nil, fmt.Errorf("%w: больше %d периодов", RangeErr, MaxFlowPoints)
+	}
+
+	points, err := r.db.ReportFlow(model.FlowQuery{Period: period, From: from, To: to, TimeZone: r.loc.String()})
+	if err != nil {
+		return nil, err
+	}
+	for i := range points {
+		points[i].Start = points[i].Start.In(r.loc)
+	}
+	return points, nil
+}
+
+// OldestOpen возвращает не более limit открытых задач, начиная с самых старых
+// Если limit не положителен, то используется DefaultOldestLimit
+func (r *Reports) OldestOpen(limit int) ([]model.Task, error) {
+	if limit <= 0 {
+		limit = DefaultOldestLimit
+	}
+	return r.db.ReportOldestOpen(limit)
+}
+
+// TimeByTask возвращает оценку и затраченное время по задачам с оценкой или записями о времени
+func (r *Reports) TimeByTask() ([]model.TaskTimeStats, error) {
+	return r.db.ReportTimeByTask()
+}
+
+// TimeByUser возвращает по пользователям оценку их задач и затраченное ими время
+func (r *Reports) TimeByUser() ([]model.UserTimeStats, error) {
+	return r.db.ReportTimeByUser()
+}
+
+// Timesheet возвращает табель: записи о времени, начатые в интервале [from, to), пользователя userID (0 - всех)
+// Начало работы возвращается в часовом поясе отчетов
+func (r *Reports) Timesheet(from, to time.Time, userID int) ([]model.TimesheetEntry, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: начало %s не раньше конца %s", RangeErr, from, to)
+	}
+	entries, err := r.db.ReportTimesheet(model.TimesheetQuery{From: from, To: to, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Started = entries[i].Started.In(r.loc)
+	}
+	return entries, nil
+}
+
+// Summary строит сводный отчет в одной транзакции, чтобы все его части были согласованы
+func (r *Reports) Summary() (Summary, error) {
+	var s Summary
+	opts := storage.TxOptions{IsoLevel: storage.RepeatableRead}
+	err := r.db.WithTxOptions(opts, func(tx storage.Interface) error {
+		var err error
+		if s.ByAssignee, err = tx.ReportByAssignee(); err != nil {
+			return err
+		}
+		if s.ByLabel, err = tx.ReportByLabel(); err != nil {
+			return err
+		}
+		if s.TimeToClose, err = tx.ReportTimeToClose(); err != nil {
+			return err
+		}
+		s.OldestOpen, err = tx.ReportOldestOpen(DefaultOldestLimit)
+		return err
+	})
+	return s, err
+}
@@ -0,0 +1,33 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ScheduleErr - некорректное расписание повторяющейся задачи
+var ScheduleErr = errors.New("Некорректное расписание повторяющейся задачи")
+
+// Schedule вычисляет время запусков по расписанию
+type Schedule interface {
+	// Next возвращает первое время запуска строго после t
+	Next(t time.Time) time.Time
+}
+
+// Разбор расписаний cron: 5 полей (минута, час, день месяца, месяц, день недели),
+// @hourly, @daily, @weekly, @monthly, @yearly, @every <длительность> и префикс часового пояса CRON_TZ=
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ParseSchedule разбирает расписание spec
+// Без префикса CRON_TZ= время запусков вычисляется в часовом поясе переданного в Next времени
+func ParseSchedule(spec string) (Schedule, error) {
+	sched, err := parser.Parse(strings.TrimSpace(spec))
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ScheduleErr, spec, err)
+	}
+	return sched, nil
+}
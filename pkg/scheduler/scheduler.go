@@ -0,0 +1,109 @@
+// Пакет scheduler создает задачи по шаблонам повторяющихся задач (model.RecurringTask) по расписанию cron
+// Планировщик можно запускать в нескольких экземплярах сервиса: каждый шаблон обрабатывается под
+// advisory lock PostgreSQL, а каждый запуск записывается в БД, поэтому задача за один запуск создается один раз
+package scheduler
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// Интервал проверки шаблонов по умолчанию
+const DefaultInterval = time.Minute
+
+// Наибольшее количество шаблонов, обрабатываемых за одну проверку, по умолчанию
+const DefaultBatchSize = 100
+
+// Scheduler периодически создает задачи по шаблонам, время запуска которых наступило
+type Scheduler struct {
+	db     storage.Interface
+	logger *slog.Logger
+	loc    *time.Location
+
+	// Интервал проверки шаблонов
+	Interval time.Duration
+	// Наибольшее количество шаблонов за одну проверку
+	BatchSize int
+}
+
+// New создает планировщик над хранилищем db
+// Расписания без CRON_TZ= вычисляются в часовом поясе loc (nil - UTC)
+func New(db storage.Interface, logger *slog.Logger, loc *time.Location) *Scheduler {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Scheduler{db: db, logger: logger, loc: loc, Interval: DefaultInterval, BatchSize: DefaultBatchSize}
+}
+
+// NewRecurringTask проверяет расписание шаблона rt и создает его в проекте хранилища db
+// Первый запуск - ближайшее по расписанию время после текущего, расписания без CRON_TZ= вычисляются в поясе loc
+func NewRecurringTask(db storage.Interface, rt model.RecurringTask, loc *time.Location) (int, error) {
+	sched, err := ParseSchedule(rt.Schedule)
+	if err != nil {
+		return 0, err
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	rt.NextRun = sched.Next(time.Now().In(loc))
+	return db.NewRecurringTask(rt)
+}
+
+// Run проверяет шаблоны каждые Interval до отмены ctx
+// Ошибки проверки записываются в журнал и не останавливают планировщик
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.RunDue(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "Ошибка проверки повторяющихся задач", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue создает задачи по шаблонам, время запуска которых не позже now, и возвращает количество созданных задач
+// За просроченный шаблон создается одна задача, пропущенные запуски не догоняются:
+// следующий запуск - ближайшее по расписанию время после now
+// Шаблоны, которые обрабатывает другой экземпляр планировщика, пропускаются,
+// ошибки отдельных шаблонов записываются в журнал
+func (s *Scheduler) RunDue(ctx context.Context, now time.Time) (int, error) {
+	db := s.db.WithContext(ctx)
+	due, err := db.SelectDueRecurringTasks(now, s.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, rt := range due {
+		if ctx.Err() != nil {
+			return created, ctx.Err()
+		}
+		log := s.logger.With(slog.Int("recurring_task_id", rt.ID), slog.Int("project_id", rt.ProjectID))
+		sched, err := ParseSchedule(rt.Schedule)
+		if err != nil {
+			log.ErrorContext(ctx, "Некорректное расписание повторяющейся задачи", slog.Any("error", err))
+			continue
+		}
+		taskID, err := db.RunRecurringTask(rt, sched.Next(now.In(s.loc)))
+		switch {
+		case errors.Is(err, storage.RecurringLockedErr):
+			log.DebugContext(ctx, "Повторяющаяся задача обрабатывается другим экземпляром")
+		case err != nil:
+			log.ErrorContext(ctx, "Ошибка создания повторяющейся задачи", slog.Any("error", err))
+		case taskID != 0:
+			created++
+			log.InfoContext(ctx, "Создана повторяющаяся задача", slog.Int("task_id", taskID),
+				slog.Time("run_at", rt.NextRun))
+		}
+	}
+	return created, nil
+}
@@ -0,0 +1,202 @@
+package scheduler
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage/storagetest"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func newTestScheduler(db *storagetest.Fake, loc *time.Location) *Scheduler {
+	return New(db, slog.New(slog.NewTextHandler(io.Discard, nil)), loc)
+}
+
+// Время проверки шаблонов в тестах
+var now = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func TestRunDue(t *testing.T) {
+	db := storagetest.New()
+	db.Recurring = []model.RecurringTask{
+		{ID: 1, ProjectID: 1, Schedule: "0 9 * * *", Title: "Ежедневная", NextRun: now.Add(-time.Hour), Active: true},
+		{ID: 2, ProjectID: 2, Schedule: "@hourly", Title: "Ежечасная", NextRun: now, Active: true},
+		{ID: 3, ProjectID: 1, Schedule: "@daily", Title: "Будущая", NextRun: now.Add(time.Minute), Active: true},
+		{ID: 4, ProjectID: 1, Schedule: "@daily", Title: "Приостановленная", NextRun: now.Add(-time.Hour)},
+	}
+	s := newTestScheduler(db, nil)
+
+	created, err := s.RunDue(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != 2 {
+		t.Errorf("RunDue() = %d, want 2", created)
+	}
+	if len(db.Tasks) != 2 || db.Tasks[0].Title != "Ежедневная" || db.Tasks[1].Title != "Ежечасная" {
+		t.Fatalf("созданы задачи %+v", db.Tasks)
+	}
+	if db.Tasks[0].ProjectID != 1 || db.Tasks[1].ProjectID != 2 {
+		t.Errorf("задачи созданы в проектах %d, %d, want 1, 2", db.Tasks[0].ProjectID, db.Tasks[1].ProjectID)
+	}
+
+	// Пропущенные запуски не догоняются: следующий запуск - ближайший после now
+	if want := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC); !db.Recurring[0].NextRun.Equal(want) {
+		t.Errorf("NextRun шаблона 1 = %v, want %v", db.Recurring[0].NextRun, want)
+	}
+	if want := now.Add(time.Hour); !db.Recurring[1].NextRun.Equal(want) {
+		t.Errorf("NextRun шаблона 2 = %v, want %v", db.Recurring[1].NextRun, want)
+	}
+	if last := db.Recurring[0].LastRun; last == nil || !last.Equal(now.Add(-time.Hour)) {
+		t.Errorf("LastRun шаблона 1 = %v, want %v", last, now.Add(-time.Hour))
+	}
+}
+
+// Повторная проверка того же времени (например после перезапуска сервиса) задачи не дублирует
+func TestRunDueRepeated(t *testing.T) {
+	db := storagetest.New()
+	db.Recurring = []model.RecurringTask{
+		{ID: 1, Schedule: "@daily", Title: "Ежедневная", NextRun: now.Add(-time.Hour), Active: true},
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := newTestScheduler(db, nil).RunDue(context.Background(), now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(db.Tasks) != 1 {
+		t.Errorf("создано задач %d, want 1", len(db.Tasks))
+	}
+}
+
+// Шаблон, полученный до запуска другим экземпляром, повторно не выполняется
+func TestRunDueStaleTemplate(t *testing.T) {
+	db := storagetest.New()
+	db.Recurring = []model.RecurringTask{
+		{ID: 1, Schedule: "@daily", Title: "Ежедневная", NextRun: now.Add(-time.Hour), Active: true},
+	}
+	stale := db.Recurring[0]
+
+	if _, err := newTestScheduler(db, nil).RunDue(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+	id, err := db.RunRecurringTask(stale, now.Add(24*time.Hour))
+	if err != nil || id != 0 {
+		t.Errorf("RunRecurringTask(устаревший шаблон) = %d, %v, want 0, nil", id, err)
+	}
+	if len(db.Tasks) != 1 {
+		t.Errorf("создано задач %d, want 1", len(db.Tasks))
+	}
+}
+
+func TestRunDueLocked(t *testing.T) {
+	db := storagetest.New()
+	db.Recurring = []model.RecurringTask{
+		{ID: 1, Schedule: "@daily", Title: "Занятая", NextRun: now.Add(-time.Hour), Active: true},
+		{ID: 2, Schedule: "@daily", Title: "Свободная", NextRun: now.Add(-time.Hour), Active: true},
+	}
+	db.Locked[1] = true
+
+	created, err := newTestScheduler(db, nil).RunDue(context.Background(), now)
+	if err != nil {
+		t.Fatalf("RunDue() error = %v, занятый шаблон должен пропускаться", err)
+	}
+	if created != 1 || len(db.Tasks) != 1 || db.Tasks[0].Title != "Свободная" {
+		t.Errorf("RunDue() = %d, задачи %+v, want только задачу по свободному шаблону", created, db.Tasks)
+	}
+	// Время запуска занятого шаблона не меняется: его переносит другой экземпляр
+	if !db.Recurring[0].NextRun.Equal(now.Add(-time.Hour)) {
+		t.Errorf("NextRun занятого шаблона = %v", db.Recurring[0].NextRun)
+	}
+}
+
+func TestRunDueInvalidSchedule(t *testing.T) {
+	db := storagetest.New()
+	db.Recurring = []model.RecurringTask{
+		{ID: 1, Schedule: "каждый день", Title: "Некорректная", NextRun: now.Add(-time.Hour), Active: true},
+		{ID: 2, Schedule: "@daily", Title: "Корректная", NextRun: now.Add(-time.Hour), Active: true},
+	}
+
+	created, err := newTestScheduler(db, nil).RunDue(context.Background(), now)
+	if err != nil || created != 1 {
+		t.Errorf("RunDue() = %d, %v, want 1, nil", created, err)
+	}
+}
+
+func TestRunDueBatchSize(t *testing.T) {
+	db := storagetest.New()
+	for i := 1; i <= 5; i++ {
+		db.Recurring = append(db.Recurring, model.RecurringTask{
+			ID: i, Schedule: "@daily", Title: "Задача", NextRun: now.Add(-time.Duration(i) * time.Minute), Active: true,
+		})
+	}
+	s := newTestScheduler(db, nil)
+	s.BatchSize = 2
+
+	created, err := s.RunDue(context.Background(), now)
+	if err != nil || created != 2 {
+		t.Errorf("RunDue() = %d, %v, want 2, nil", created, err)
+	}
+	// Первыми обрабатываются самые просроченные шаблоны
+	if db.Recurring[4].LastRun == nil || db.Recurring[3].LastRun == nil || db.Recurring[0].LastRun != nil {
+		t.Errorf("обработаны не самые просроченные шаблоны: %+v", db.Recurring)
+	}
+}
+
+func TestRunDueLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	db := storagetest.New()
+	db.Recurring = []model.RecurringTask{
+		{ID: 1, Schedule: "0 9 * * *", Title: "В поясе планировщика", NextRun: now.Add(-time.Hour), Active: true},
+		{ID: 2, Schedule: "CRON_TZ=UTC 0 9 * * *", Title: "В своем поясе", NextRun: now.Add(-time.Hour), Active: true},
+	}
+
+	if _, err := newTestScheduler(db, loc).RunDue(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 5, 2, 9, 0, 0, 0, loc); !db.Recurring[0].NextRun.Equal(want) {
+		t.Errorf("NextRun = %v, want %v", db.Recurring[0].NextRun, want)
+	}
+	if want := time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC); !db.Recurring[1].NextRun.Equal(want) {
+		t.Errorf("NextRun с CRON_TZ = %v, want %v", db.Recurring[1].NextRun, want)
+	}
+}
+
+func TestRunDueCanceled(t *testing.T) {
+	db := storagetest.New()
+	db.Recurring = []model.RecurringTask{
+		{ID: 1, Schedule: "@daily", Title: "Задача", NextRun: now.Add(-time.Hour), Active: true},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	created, err := newTestScheduler(db, nil).RunDue(ctx, now)
+	if !errors.Is(err, context.Canceled) || created != 0 {
+		t.Errorf("RunDue() = %d, %v, want 0, context.Canceled", created, err)
+	}
+	if len(db.Tasks) != 0 {
+		t.Errorf("создано задач %d после отмены", len(db.Tasks))
+	}
+}
+
+func TestNewRecurringTask(t *testing.T) {
+	db := storagetest.New()
+	before := time.Now()
+	id, err := NewRecurringTask(db.WithProject(3), model.RecurringTask{Schedule: "@hourly", Title: "Задача"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := db.Recurring[id-1]
+	if rt.ProjectID != 3 {
+		t.Errorf("ProjectID = %d, want 3", rt.ProjectID)
+	}
+	if !rt.NextRun.After(before) || rt.NextRun.After(before.Add(time.Hour)) || rt.NextRun.Minute() != 0 {
+		t.Errorf("NextRun = %v, want начало следующего часа после %v", rt.NextRun, before)
+	}
+
+	if _, err := NewRecurringTask(db, model.RecurringTask{Schedule: "* *", Title: "Задача"}, nil); !errors.Is(err, ScheduleErr) {
+		t.Errorf("NewRecurringTask(некорректное расписание) error = %v, want ScheduleErr", err)
+	}
+}
@@ -0,0 +1,17 @@
+package storage
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor возвращает контекст с ID пользователя, от имени которого выполняются изменения
+// Хранилище не уведомляет пользователя об его собственных изменениях задач
+func WithActor(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorFromContext возвращает ID пользователя, заданного через WithActor
+func ActorFromContext(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(actorKey{}).(int)
+	return id, ok
+}
@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore - хранилище содержимого вложений, адресуемого ключом
+// Ключи создает хранилище задач: это строки из цифр и строчных латинских букв a-f
+type BlobStore interface {
+	// Put записывает содержимое r под ключом key и возвращает число записанных байт
+	// При ошибке записанная часть удаляется или остается недоступной
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open открывает содержимое для чтения, если ключ не найден - ошибка myerrors.NotFoundErr
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет содержимое, отсутствие ключа ошибкой не считается
+	Delete(ctx context.Context, key string) error
+}
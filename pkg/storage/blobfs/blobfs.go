@@ -0,0 +1,112 @@
+// Пакет blobfs хранит содержимое вложений файлами в каталоге на локальном диске
+// Файл с ключом key лежит в подкаталоге из первых двух символов ключа, чтобы каталоги не разрастались
+package blobfs
+
+import (
+	"DB_Apps/pkg/myerrors"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KeyErr - ключ содержимого не подходит для имени файла
+var KeyErr = errors.New("Некорректный ключ содержимого")
+
+// Store - хранилище содержимого в каталоге dir, реализует storage.BlobStore
+type Store struct {
+	dir string
+}
+
+// New создает хранилище в каталоге dir, создавая его при необходимости
+func New(dir string) (*Store, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("Ошибка при создании каталога вложений %s: %w", dir, err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Put записывает содержимое во временный файл и переименовывает его после записи,
+// поэтому недописанное содержимое никогда не открывается по ключу
+func (s *Store) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+
+	n, err := io.Copy(f, contextReader{ctx: ctx, r: r})
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (s *Store) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, myerrors.NotFound("Содержимое вложения %s не найдено", key)
+	}
+	return f, err
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path возвращает путь к файлу содержимого
+// Ключ должен состоять из цифр и букв a-f, чтобы путь не выходил за пределы каталога
+func (s *Store) path(key string) (string, error) {
+	if len(key) < 3 || strings.Trim(key, "0123456789abcdef") != "" {
+		return "", fmt.Errorf("%w: %q", KeyErr, key)
+	}
+	return filepath.Join(s.dir, key[:2], key), nil
+}
+
+// contextReader прекращает чтение после отмены ctx
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,27 @@
+package storage
+
+import "time"
+
+// PoolStats - состояние пула соединений
+type PoolStats struct {
+	TotalConns      int32
+	IdleConns       int32
+	AcquiredConns   int32
+	MaxConns        int32
+	AcquireCount    int64
+	AcquireDuration time.Duration
+	// Количество получений соединения, которым пришлось ждать освобождения
+	EmptyAcquireCount int64
+}
+
+// Diagnostics - диагностические сведения о подключении к БД
+type Diagnostics struct {
+	Pool          PoolStats
+	ServerVersion string
+	// Последняя примененная миграция схемы, 0 - миграции не применялись
+	MigrationVersion int
+	// Сервер является репликой (находится в режиме восстановления)
+	InRecovery bool
+	// Отставание реплики от основного сервера, для основного сервера 0
+	ReplicationLag time.Duration
+}
@@ -2,33 +2,161 @@ package storage
 
 import (
 	"DB_Apps/pkg/model"
+	"context"
+	"io"
+	"iter"
+	"time"
 )
 
 type Interface interface {
 	// Для работы с пользователем(users)
 	NewUser(model.User) (int, error)
-	DeleteUser(int) error
+	DeleteUser(int, DeleteUserOptions) (DeleteUserReport, error)
 	UpdateUserName(int, string) error
+	UpdateUserProfile(model.User) error
+	SetUserRole(int, model.Role) error
+	SetUserActive(int, bool) error
 	SelectUsers() ([]model.User, error)
 	SelectUserByID(int) (model.User, error)
+	SelectUserByLogin(string) (model.User, error)
+	SelectUserByEmail(string) (model.User, error)
+	SelectUsersByIDs([]int) ([]model.User, error)
+	IterUsers() iter.Seq2[model.User, error]
 
-	// Для работы с метками(labels)
+	// Для аутентификации: пароли пользователей и токены(auth_tokens)
+	SetUserPassword(int, string) error
+	SelectUserPassword(int) (string, error)
+	NewToken(model.Token) (int, error)
+	SelectTokenByHash([]byte) (model.Token, error)
+	RevokeToken(int) error
+	RevokeUserTokens(int) (int, error)
+
+	// Для работы с проектами(projects) и их участниками(project_members)
+	NewProject(model.Project) (int, error)
+	DeleteProject(int) error
+	SelectProjects() ([]model.Project, error)
+	SelectProjectByID(int) (model.Project, error)
+	SelectUserProjects(int) ([]model.Project, error)
+	AddProjectMember(int, int) error
+	DeleteProjectMember(int, int) error
+	SelectProjectMembers(int) ([]model.User, error)
+	IsProjectMember(int, int) (bool, error)
+
+	// Для работы с метками(labels) проекта
 	NewLabel(model.Label) (int, error)
 	DeleteLabel(int) error
 	UpdateLabelName(int, string) error
 	SelectLabels() ([]model.Label, error)
 	SelectLabelByID(int) (model.Label, error)
+	SelectLabelsByIDs([]int) ([]model.Label, error)
+	IterLabels() iter.Seq2[model.Label, error]
 
-	// Для работы с задачами(tasks)
+	// Для работы с задачами(tasks) проекта
 	NewTask(model.Task) (int, error)
 	SelectTasks() ([]model.Task, error)
+	SelectTaskByID(int) (model.Task, error)
 	SelectTasksByAuthorID(int) ([]model.Task, error)
 	SelectTasksByLabelID(int) ([]model.Task, error)
+	SelectTasksPage(model.TaskPageQuery) ([]model.Task, error)
+	SelectTaskLabelIDs([]int) (map[int][]int, error)
+	IterTasks() iter.Seq2[model.Task, error]
+	IterTasksByAuthorID(int) iter.Seq2[model.Task, error]
+	IterTasksByLabelID(int) iter.Seq2[model.Task, error]
 	DeleteTask(int) error
 	UpdateTaskByID(model.Task) error
+	CloseTask(int) error
 	AddLabelToTask(int, int) error
 	DeleteLabelToTask(int, int) error
 
+	// Для работы с сохраненными представлениями(saved_views) проекта и выборки задач по фильтру
+	NewSavedView(model.SavedView) (int, error)
+	UpdateSavedView(model.SavedView) error
+	DeleteSavedView(int) error
+	SelectSavedViews(int) ([]model.SavedView, error)
+	SelectSavedViewByID(int) (model.SavedView, error)
+	SelectTasksByFilter(model.TaskFilter) ([]model.Task, error)
+	ExecuteSavedView(int) ([]model.Task, error)
+
+	// Для работы с досками(boards) проекта: колонки и положение задач в них
+	NewBoard(model.Board) (int, error)
+	UpdateBoard(model.Board) error
+	DeleteBoard(int) error
+	SelectBoards() ([]model.Board, error)
+	SelectBoardByID(int) (model.Board, error)
+	SelectBoardCards(int) ([]model.BoardCard, error)
+	MoveBoardTask(model.BoardMove) error
+
+	// Для работы с шаблонами задач(task_templates) проекта
+	NewTaskTemplate(model.TaskTemplate) (int, error)
+	UpdateTaskTemplate(model.TaskTemplate) error
+	DeleteTaskTemplate(int) error
+	SelectTaskTemplates() ([]model.TaskTemplate, error)
+	SelectTaskTemplateByID(int) (model.TaskTemplate, error)
+	NewTaskFromTemplate(int, int, map[string]string) (int, error)
+
+	// Для работы с шаблонами повторяющихся задач(recurring_tasks) проекта
+	NewRecurringTask(model.RecurringTask) (int, error)
+	SelectRecurringTasks() ([]model.RecurringTask, error)
+	SelectRecurringTaskByID(int) (model.RecurringTask, error)
+	SetRecurringTaskActive(int, bool) error
+	DeleteRecurringTask(int) error
+	// Для планировщика: шаблоны всех проектов, время запуска которых наступило, и создание задачи по шаблону
+	// Если шаблон захвачен другим экземпляром планировщика, то RunRecurringTask возвращает RecurringLockedErr
+	SelectDueRecurringTasks(time.Time, int) ([]model.RecurringTask, error)
+	RunRecurringTask(model.RecurringTask, time.Time) (int, error)
+
+	// Для учета времени: записи о затраченном на задачи времени(worklogs) и оценки задач
+	NewWorklog(model.Worklog) (int, error)
+	DeleteWorklog(int) error
+	SelectWorklogByID(int) (model.Worklog, error)
+	SelectWorklogs(int) ([]model.Worklog, error)
+	SetTaskEstimate(int, time.Duration) error
+
+	// Для работы с вложениями задач(attachments): метаданные и содержимое в хранилище содержимого
+	NewAttachment(model.Attachment, io.Reader) (int, error)
+	DeleteAttachment(int) error
+	SelectAttachmentByID(int) (model.Attachment, error)
+	SelectAttachments(int) ([]model.Attachment, error)
+	OpenAttachment(int) (model.Attachment, io.ReadCloser, error)
+
+	// Для подписки на изменения задач(task_watchers) и входящих уведомлений пользователей(notifications)
+	// Уведомления создаются при изменении задач, входящие пользователя общие для всех проектов
+	WatchTask(int, int) error
+	UnwatchTask(int, int) error
+	SelectTaskWatchers(int) ([]model.User, error)
+	SelectNotifications(model.NotificationQuery) ([]model.Notification, error)
+	MarkNotificationRead(int, int) error
+	MarkAllNotificationsRead(int) (int, error)
+
+	// Отчеты по задачам проекта (агрегация на стороне БД)
+	ReportByAssignee() ([]model.AssigneeStats, error)
+	ReportByLabel() ([]model.LabelStats, error)
+	ReportTimeToClose() (model.CloseTimeStats, error)
+	ReportFlow(model.FlowQuery) ([]model.FlowPoint, error)
+	ReportOldestOpen(int) ([]model.Task, error)
+	ReportTimeByTask() ([]model.TaskTimeStats, error)
+	ReportTimeByUser() ([]model.UserTimeStats, error)
+	ReportTimesheet(model.TimesheetQuery) ([]model.TimesheetEntry, error)
+
+	// Выполнение нескольких операций в одной транзакции
+	// Итераторы Iter* внутри транзакции считывают строки полностью до первой итерации,
+	// чтобы из тела цикла можно было вызывать другие методы хранилища
+	WithTx(func(Interface) error) error
+	WithTxOptions(TxOptions, func(Interface) error) error
+
+	// Проверка доступности БД и диагностика подключения
+	Ping() error
+	Diagnostics() (Diagnostics, error)
+
+	// Хранилище, задачи и метки которого ограничены проектом, и ID этого проекта
+	// Без WithProject хранилище работает с проектом по умолчанию (ID 0)
+	WithProject(int) Interface
+	ProjectID() int
+
+	// Хранилище, запросы которого выполняются с контекстом ctx
+	// (отмена, трассировка, журналирование)
+	WithContext(ctx context.Context) Interface
+
 	// Закрытие соедининя с БД
 	Close()
 }
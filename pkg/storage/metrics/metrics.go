@@ -0,0 +1,624 @@
+// Пакет metrics содержит обертку над storage.Interface, собирающую метрики Prometheus
+package metrics
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage"
+	"context"
+	"io"
+	"iter"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "db_apps"
+
+// Storage - обертка над storage.Interface, которая для каждого метода записывает
+// время выполнения и количество ошибок по категориям
+type Storage struct {
+	next     storage.Interface
+	classify func(error) string
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// New создает обертку над next и регистрирует ее метрики в reg
+// classify определяет категорию ошибки для метки category счетчика ошибок
+func New(next storage.Interface, reg prometheus.Registerer, classify func(error) string) (*Storage, error) {
+	s := &Storage{
+		next:     next,
+		classify: classify,
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "operation_duration_seconds",
+			Help:      "Время выполнения методов хранилища.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "operation_errors_total",
+			Help:      "Количество ошибок методов хранилища по категориям.",
+		}, []string{"method", "category"}),
+	}
+	if err := reg.Register(s.duration); err != nil {
+		return nil, err
+	}
+	if err := reg.Register(s.errors); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// observe записывает время выполнения метода method и, если *err не nil, его ошибку
+func (s *Storage) observe(method string, start time.Time, err *error) {
+	s.duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if *err != nil {
+		s.errors.WithLabelValues(method, s.classify(*err)).Inc()
+	}
+}
+
+// observeIter оборачивает итератор: время записывается за весь проход, включая досрочный выход
+func observeIter[T any](s *Storage, method string, seq iter.Seq2[T, error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var err error
+		defer s.observe(method, time.Now(), &err)
+		for v, e := range seq {
+			if e != nil {
+				err = e
+			}
+			if !yield(v, e) {
+				return
+			}
+		}
+	}
+}
+
+func (s *Storage) NewUser(user model.User) (id int, err error) {
+	defer s.observe("NewUser", time.Now(), &err)
+	return s.next.NewUser(user)
+}
+
+func (s *Storage) DeleteUser(id int, opts storage.DeleteUserOptions) (r storage.DeleteUserReport, err error) {
+	defer s.observe("DeleteUser", time.Now(), &err)
+	return s.next.DeleteUser(id, opts)
+}
+
+func (s *Storage) UpdateUserName(id int, name string) (err error) {
+	defer s.observe("UpdateUserName", time.Now(), &err)
+	return s.next.UpdateUserName(id, name)
+}
+
+func (s *Storage) UpdateUserProfile(user model.User) (err error) {
+	defer s.observe("UpdateUserProfile", time.Now(), &err)
+	return s.next.UpdateUserProfile(user)
+}
+
+func (s *Storage) SetUserRole(id int, role model.Role) (err error) {
+	defer s.observe("SetUserRole", time.Now(), &err)
+	return s.next.SetUserRole(id, role)
+}
+
+func (s *Storage) SetUserActive(id int, active bool) (err error) {
+	defer s.observe("SetUserActive", time.Now(), &err)
+	return s.next.SetUserActive(id, active)
+}
+
+func (s *Storage) SelectUsers() (users []model.User, err error) {
+	defer s.observe("SelectUsers", time.Now(), &err)
+	return s.next.SelectUsers()
+}
+
+func (s *Storage) SelectUserByID(id int) (user model.User, err error) {
+	defer s.observe("SelectUserByID", time.Now(), &err)
+	return s.next.SelectUserByID(id)
+}
+
+func (s *Storage) SelectUserByLogin(login string) (user model.User, err error) {
+	defer s.observe("SelectUserByLogin", time.Now(), &err)
+	return s.next.SelectUserByLogin(login)
+}
+
+func (s *Storage) SelectUserByEmail(email string) (user model.User, err error) {
+	defer s.observe("SelectUserByEmail", time.Now(), &err)
+	return s.next.SelectUserByEmail(email)
+}
+
+func (s *Storage) SelectUsersByIDs(ids []int) (users []model.User, err error) {
+	defer s.observe("SelectUsersByIDs", time.Now(), &err)
+	return s.next.SelectUsersByIDs(ids)
+}
+
+func (s *Storage) IterUsers() iter.Seq2[model.User, error] {
+	return observeIter(s, "IterUsers", s.next.IterUsers())
+}
+
+func (s *Storage) SetUserPassword(userID int, hash string) (err error) {
+	defer s.observe("SetUserPassword", time.Now(), &err)
+	return s.next.SetUserPassword(userID, hash)
+}
+
+func (s *Storage) SelectUserPassword(userID int) (hash string, err error) {
+	defer s.observe("SelectUserPassword", time.Now(), &err)
+	return s.next.SelectUserPassword(userID)
+}
+
+func (s *Storage) NewToken(t model.Token) (id int, err error) {
+	defer s.observe("NewToken", time.Now(), &err)
+	return s.next.NewToken(t)
+}
+
+func (s *Storage) SelectTokenByHash(hash []byte) (t model.Token, err error) {
+	defer s.observe("SelectTokenByHash", time.Now(), &err)
+	return s.next.SelectTokenByHash(hash)
+}
+
+func (s *Storage) RevokeToken(id int) (err error) {
+	defer s.observe("RevokeToken", time.Now(), &err)
+	return s.next.RevokeToken(id)
+}
+
+func (s *Storage) RevokeUserTokens(userID int) (n int, err error) {
+	defer s.observe("RevokeUserTokens", time.Now(), &err)
+	return s.next.RevokeUserTokens(userID)
+}
+
+func (s *Storage) NewProject(p model.Project) (id int, err error) {
+	defer s.observe("NewProject", time.Now(), &err)
+	return s.next.NewProject(p)
+}
+
+func (s *Storage) DeleteProject(id int) (err error) {
+	defer s.observe("DeleteProject", time.Now(), &err)
+	return s.next.DeleteProject(id)
+}
+
+func (s *Storage) SelectProjects() (projects []model.Project, err error) {
+	defer s.observe("SelectProjects", time.Now(), &err)
+	return s.next.SelectProjects()
+}
+
+func (s *Storage) SelectProjectByID(id int) (p model.Project, err error) {
+	defer s.observe("SelectProjectByID", time.Now(), &err)
+	return s.next.SelectProjectByID(id)
+}
+
+func (s *Storage) SelectUserProjects(userID int) (projects []model.Project, err error) {
+	defer s.observe("SelectUserProjects", time.Now(), &err)
+	return s.next.SelectUserProjects(userID)
+}
+
+func (s *Storage) AddProjectMember(projectID, userID int) (err error) {
+	defer s.observe("AddProjectMember", time.Now(), &err)
+	return s.next.AddProjectMember(projectID, userID)
+}
+
+func (s *Storage) DeleteProjectMember(projectID, userID int) (err error) {
+	defer s.observe("DeleteProjectMember", time.Now(), &err)
+	return s.next.DeleteProjectMember(projectID, userID)
+}
+
+func (s *Storage) SelectProjectMembers(projectID int) (users []model.User, err error) {
+	defer s.observe("SelectProjectMembers", time.Now(), &err)
+	return s.next.SelectProjectMembers(projectID)
+}
+
+func (s *Storage) IsProjectMember(projectID, userID int) (ok bool, err error) {
+	defer s.observe("IsProjectMember", time.Now(), &err)
+	return s.next.IsProjectMember(projectID, userID)
+}
+
+func (s *Storage) NewLabel(label model.Label) (id int, err error) {
+	defer s.observe("NewLabel", time.Now(), &err)
+	return s.next.NewLabel(label)
+}
+
+func (s *Storage) DeleteLabel(id int) (err error) {
+	defer s.observe("DeleteLabel", time.Now(), &err)
+	return s.next.DeleteLabel(id)
+}
+
+func (s *Storage) UpdateLabelName(id int, name string) (err error) {
+	defer s.observe("UpdateLabelName", time.Now(), &err)
+	return s.next.UpdateLabelName(id, name)
+}
+
+func (s *Storage) SelectLabels() (labels []model.Label, err error) {
+	defer s.observe("SelectLabels", time.Now(), &err)
+	return s.next.SelectLabels()
+}
+
+func (s *Storage) SelectLabelByID(id int) (label model.Label, err error) {
+	defer s.observe("SelectLabelByID", time.Now(), &err)
+	return s.next.SelectLabelByID(id)
+}
+
+func (s *Storage) SelectLabelsByIDs(ids []int) (labels []model.Label, err error) {
+	defer s.observe("SelectLabelsByIDs", time.Now(), &err)
+	return s.next.SelectLabelsByIDs(ids)
+}
+
+func (s *Storage) IterLabels() iter.Seq2[model.Label, error] {
+	return observeIter(s, "IterLabels", s.next.IterLabels())
+}
+
+func (s *Storage) NewTask(task model.Task) (id int, err error) {
+	defer s.observe("NewTask", time.Now(), &err)
+	return s.next.NewTask(task)
+}
+
+func (s *Storage) SelectTasks() (tasks []model.Task, err error) {
+	defer s.observe("SelectTasks", time.Now(), &err)
+	return s.next.SelectTasks()
+}
+
+func (s *Storage) SelectTaskByID(id int) (task model.Task, err error) {
+	defer s.observe("SelectTaskByID", time.Now(), &err)
+	return s.next.SelectTaskByID(id)
+}
+
+func (s *Storage) SelectTasksByAuthorID(authorID int) (tasks []model.Task, err error) {
+	defer s.observe("SelectTasksByAuthorID", time.Now(), &err)
+	return s.next.SelectTasksByAuthorID(authorID)
+}
+
+func (s *Storage) SelectTasksByLabelID(labelID int) (tasks []model.Task, err error) {
+	defer s.observe("SelectTasksByLabelID", time.Now(), &err)
+	return s.next.SelectTasksByLabelID(labelID)
+}
+
+func (s *Storage) SelectTasksPage(q model.TaskPageQuery) (tasks []model.Task, err error) {
+	defer s.observe("SelectTasksPage", time.Now(), &err)
+	return s.next.SelectTasksPage(q)
+}
+
+func (s *Storage) SelectTaskLabelIDs(taskIDs []int) (labels map[int][]int, err error) {
+	defer s.observe("SelectTaskLabelIDs", time.Now(), &err)
+	return s.next.SelectTaskLabelIDs(taskIDs)
+}
+
+func (s *Storage) IterTasks() iter.Seq2[model.Task, error] {
+	return observeIter(s, "IterTasks", s.next.IterTasks())
+}
+
+func (s *Storage) IterTasksByAuthorID(authorID int) iter.Seq2[model.Task, error] {
+	return observeIter(s, "IterTasksByAuthorID", s.next.IterTasksByAuthorID(authorID))
+}
+
+func (s *Storage) IterTasksByLabelID(labelID int) iter.Seq2[model.Task, error] {
+	return observeIter(s, "IterTasksByLabelID", s.next.IterTasksByLabelID(labelID))
+}
+
+func (s *Storage) DeleteTask(id int) (err error) {
+	defer s.observe("DeleteTask", time.Now(), &err)
+	return s.next.DeleteTask(id)
+}
+
+func (s *Storage) UpdateTaskByID(task model.Task) (err error) {
+	defer s.observe("UpdateTaskByID", time.Now(), &err)
+	return s.next.UpdateTaskByID(task)
+}
+
+func (s *Storage) CloseTask(id int) (err error) {
+	defer s.observe("CloseTask", time.Now(), &err)
+	return s.next.CloseTask(id)
+}
+
+func (s *Storage) AddLabelToTask(labelID, taskID int) (err error) {
+	defer s.observe("AddLabelToTask", time.Now(), &err)
+	return s.next.AddLabelToTask(labelID, taskID)
+}
+
+func (s *Storage) DeleteLabelToTask(labelID, taskID int) (err error) {
+	defer s.observe("DeleteLabelToTask", time.Now(), &err)
+	return s.next.DeleteLabelToTask(labelID, taskID)
+}
+
+func (s *Storage) NewSavedView(v model.SavedView) (id int, err error) {
+	defer s.observe("NewSavedView", time.Now(), &err)
+	return s.next.NewSavedView(v)
+}
+
+func (s *Storage) UpdateSavedView(v model.SavedView) (err error) {
+	defer s.observe("UpdateSavedView", time.Now(), &err)
+	return s.next.UpdateSavedView(v)
+}
+
+func (s *Storage) DeleteSavedView(id int) (err error) {
+	defer s.observe("DeleteSavedView", time.Now(), &err)
+	return s.next.DeleteSavedView(id)
+}
+
+func (s *Storage) SelectSavedViews(ownerID int) (views []model.SavedView, err error) {
+	defer s.observe("SelectSavedViews", time.Now(), &err)
+	return s.next.SelectSavedViews(ownerID)
+}
+
+func (s *Storage) SelectSavedViewByID(id int) (v model.SavedView, err error) {
+	defer s.observe("SelectSavedViewByID", time.Now(), &err)
+	return s.next.SelectSavedViewByID(id)
+}
+
+func (s *Storage) SelectTasksByFilter(f model.TaskFilter) (tasks []model.Task, err error) {
+	defer s.observe("SelectTasksByFilter", time.Now(), &err)
+	return s.next.SelectTasksByFilter(f)
+}
+
+func (s *Storage) ExecuteSavedView(id int) (tasks []model.Task, err error) {
+	defer s.observe("ExecuteSavedView", time.Now(), &err)
+	return s.next.ExecuteSavedView(id)
+}
+
+func (s *Storage) NewBoard(b model.Board) (id int, err error) {
+	defer s.observe("NewBoard", time.Now(), &err)
+	return s.next.NewBoard(b)
+}
+
+func (s *Storage) UpdateBoard(b model.Board) (err error) {
+	defer s.observe("UpdateBoard", time.Now(), &err)
+	return s.next.UpdateBoard(b)
+}
+
+func (s *Storage) DeleteBoard(id int) (err error) {
+	defer s.observe("DeleteBoard", time.Now(), &err)
+	return s.next.DeleteBoard(id)
+}
+
+func (s *Storage) SelectBoards() (boards []model.Board, err error) {
+	defer s.observe("SelectBoards", time.Now(), &err)
+	return s.next.SelectBoards()
+}
+
+func (s *Storage) SelectBoardByID(id int) (b model.Board, err error) {
+	defer s.observe("SelectBoardByID", time.Now(), &err)
+	return s.next.SelectBoardByID(id)
+}
+
+func (s *Storage) SelectBoardCards(boardID int) (cards []model.BoardCard, err error) {
+	defer s.observe("SelectBoardCards", time.Now(), &err)
+	return s.next.SelectBoardCards(boardID)
+}
+
+func (s *Storage) MoveBoardTask(m model.BoardMove) (err error) {
+	defer s.observe("MoveBoardTask", time.Now(), &err)
+	return s.next.MoveBoardTask(m)
+}
+
+func (s *Storage) NewWorklog(w model.Worklog) (id int, err error) {
+	defer s.observe("NewWorklog", time.Now(), &err)
+	return s.next.NewWorklog(w)
+}
+
+func (s *Storage) DeleteWorklog(id int) (err error) {
+	defer s.observe("DeleteWorklog", time.Now(), &err)
+	return s.next.DeleteWorklog(id)
+}
+
+func (s *Storage) SelectWorklogByID(id int) (w model.Worklog, err error) {
+	defer s.observe("SelectWorklogByID", time.Now(), &err)
+	return s.next.SelectWorklogByID(id)
+}
+
+func (s *Storage) SelectWorklogs(taskID int) (worklogs []model.Worklog, err error) {
+	defer s.observe("SelectWorklogs", time.Now(), &err)
+	return s.next.SelectWorklogs(taskID)
+}
+
+func (s *Storage) SetTaskEstimate(taskID int, estimate time.Duration) (err error) {
+	defer s.observe("SetTaskEstimate", time.Now(), &err)
+	return s.next.SetTaskEstimate(taskID, estimate)
+}
+
+func (s *Storage) NewAttachment(a model.Attachment, content io.Reader) (id int, err error) {
+	defer s.observe("NewAttachment", time.Now(), &err)
+	return s.next.NewAttachment(a, content)
+}
+
+func (s *Storage) DeleteAttachment(id int) (err error) {
+	defer s.observe("DeleteAttachment", time.Now(), &err)
+	return s.next.DeleteAttachment(id)
+}
+
+func (s *Storage) SelectAttachmentByID(id int) (a model.Attachment, err error) {
+	defer s.observe("SelectAttachmentByID", time.Now(), &err)
+	return s.next.SelectAttachmentByID(id)
+}
+
+func (s *Storage) SelectAttachments(taskID int) (a []model.Attachment, err error) {
+	defer s.observe("SelectAttachments", time.Now(), &err)
+	return s.next.SelectAttachments(taskID)
+}
+
+// OpenAttachment измеряет только открытие, чтение содержимого в длительность не входит
+func (s *Storage) OpenAttachment(id int) (a model.Attachment, content io.ReadCloser, err error) {
+	defer s.observe("OpenAttachment", time.Now(), &err)
+	return s.next.OpenAttachment(id)
+}
+
+func (s *Storage) WatchTask(taskID, userID int) (err error) {
+	defer s.observe("WatchTask", time.Now(), &err)
+	return s.next.WatchTask(taskID, userID)
+}
+
+func (s *Storage) UnwatchTask(taskID, userID int) (err error) {
+	defer s.observe("UnwatchTask", time.Now(), &err)
+	return s.next.UnwatchTask(taskID, userID)
+}
+
+func (s *Storage) SelectTaskWatchers(taskID int) (users []model.User, err error) {
+	defer s.observe("SelectTaskWatchers", time.Now(), &err)
+	return s.next.SelectTaskWatchers(taskID)
+}
+
+func (s *Storage) SelectNotifications(q model.NotificationQuery) (n []model.Notification, err error) {
+	defer s.observe("SelectNotifications", time.Now(), &err)
+	return s.next.SelectNotifications(q)
+}
+
+func (s *Storage) MarkNotificationRead(userID, id int) (err error) {
+	defer s.observe("MarkNotificationRead", time.Now(), &err)
+	return s.next.MarkNotificationRead(userID, id)
+}
+
+func (s *Storage) MarkAllNotificationsRead(userID int) (n int, err error) {
+	defer s.observe("MarkAllNotificationsRead", time.Now(), &err)
+	return s.next.MarkAllNotificationsRead(userID)
+}
+
+func (s *Storage) NewTaskTemplate(t model.TaskTemplate) (id int, err error) {
+	defer s.observe("NewTaskTemplate", time.Now(), &err)
+	return s.next.NewTaskTemplate(t)
+}
+
+func (s *Storage) UpdateTaskTemplate(t model.TaskTemplate) (err error) {
+	defer s.observe("UpdateTaskTemplate", time.Now(), &err)
+	return s.next.UpdateTaskTemplate(t)
+}
+
+func (s *Storage) DeleteTaskTemplate(id int) (err error) {
+	defer s.observe("DeleteTaskTemplate", time.Now(), &err)
+	return s.next.DeleteTaskTemplate(id)
+}
+
+func (s *Storage) SelectTaskTemplates() (templates []model.TaskTemplate, err error) {
+	defer s.observe("SelectTaskTemplates", time.Now(), &err)
+	return s.next.SelectTaskTemplates()
+}
+
+func (s *Storage) SelectTaskTemplateByID(id int) (t model.TaskTemplate, err error) {
+	defer s.observe("SelectTaskTemplateByID", time.Now(), &err)
+	return s.next.SelectTaskTemplateByID(id)
+}
+
+func (s *Storage) NewTaskFromTemplate(templateID, authorID int, vars map[string]string) (id int, err error) {
+	defer s.observe("NewTaskFromTemplate", time.Now(), &err)
+	return s.next.NewTaskFromTemplate(templateID, authorID, vars)
+}
+
+func (s *Storage) NewRecurringTask(rt model.RecurringTask) (id int, err error) {
+	defer s.observe("NewRecurringTask", time.Now(), &err)
+	return s.next.NewRecurringTask(rt)
+}
+
+func (s *Storage) SelectRecurringTasks() (rts []model.RecurringTask, err error) {
+	defer s.observe("SelectRecurringTasks", time.Now(), &err)
+	return s.next.SelectRecurringTasks()
+}
+
+func (s *Storage) SelectRecurringTaskByID(id int) (rt model.RecurringTask, err error) {
+	defer s.observe("SelectRecurringTaskByID", time.Now(), &err)
+	return s.next.SelectRecurringTaskByID(id)
+}
+
+func (s *Storage) SetRecurringTaskActive(id int, active bool) (err error) {
+	defer s.observe("SetRecurringTaskActive", time.Now(), &err)
+	return s.next.SetRecurringTaskActive(id, active)
+}
+
+func (s *Storage) DeleteRecurringTask(id int) (err error) {
+	defer s.observe("DeleteRecurringTask", time.Now(), &err)
+	return s.next.DeleteRecurringTask(id)
+}
+
+func (s *Storage) SelectDueRecurringTasks(now time.Time, limit int) (rts []model.RecurringTask, err error) {
+	defer s.observe("SelectDueRecurringTasks", time.Now(), &err)
+	return s.next.SelectDueRecurringTasks(now, limit)
+}
+
+func (s *Storage) RunRecurringTask(rt model.RecurringTask, next time.Time) (id int, err error) {
+	defer s.observe("RunRecurringTask", time.Now(), &err)
+	return s.next.RunRecurringTask(rt, next)
+}
+
+func (s *Storage) ReportByAssignee() (rows []model.AssigneeStats, err error) {
+	defer s.observe("ReportByAssignee", time.Now(), &err)
+	return s.next.ReportByAssignee()
+}
+
+func (s *Storage) ReportByLabel() (rows []model.LabelStats, err error) {
+	defer s.observe("ReportByLabel", time.Now(), &err)
+	return s.next.ReportByLabel()
+}
+
+func (s *Storage) ReportTimeToClose() (stats model.CloseTimeStats, err error) {
+	defer s.observe("ReportTimeToClose", time.Now(), &err)
+	return s.next.ReportTimeToClose()
+}
+
+func (s *Storage) ReportFlow(q model.FlowQuery) (points []model.FlowPoint, err error) {
+	defer s.observe("ReportFlow", time.Now(), &err)
+	return s.next.ReportFlow(q)
+}
+
+func (s *Storage) ReportTimeByTask() (stats []model.TaskTimeStats, err error) {
+	defer s.observe("ReportTimeByTask", time.Now(), &err)
+	return s.next.ReportTimeByTask()
+}
+
+func (s *Storage) ReportTimeByUser() (stats []model.UserTimeStats, err error) {
+	defer s.observe("ReportTimeByUser", time.Now(), &err)
+	return s.next.ReportTimeByUser()
+}
+
+func (s *Storage) ReportTimesheet(q model.TimesheetQuery) (entries []model.TimesheetEntry, err error) {
+	defer s.observe("ReportTimesheet", time.Now(), &err)
+	return s.next.ReportTimesheet(q)
+}
+
+func (s *Storage) ReportOldestOpen(limit int) (tasks []model.Task, err error) {
+	defer s.observe("ReportOldestOpen", time.Now(), &err)
+	return s.next.ReportOldestOpen(limit)
+}
+
+// WithTx записывает время выполнения всей транзакции
+// Операции внутри fn также проходят через обертку и попадают в метрики
+func (s *Storage) WithTx(fn func(storage.Interface) error) (err error) {
+	defer s.observe("WithTx", time.Now(), &err)
+	return s.next.WithTx(s.wrapTx(fn))
+}
+
+func (s *Storage) WithTxOptions(opts storage.TxOptions, fn func(storage.Interface) error) (err error) {
+	defer s.observe("WithTx", time.Now(), &err)
+	return s.next.WithTxOptions(opts, s.wrapTx(fn))
+}
+
+// wrapTx подменяет хранилище транзакции, переданное в fn, на обертку с теми же метриками
+func (s *Storage) wrapTx(fn func(storage.Interface) error) func(storage.Interface) error {
+	return func(tx storage.Interface) error {
+		wrapped := *s
+		wrapped.next = tx
+		return fn(&wrapped)
+	}
+}
+
+func (s *Storage) Ping() (err error) {
+	defer s.observe("Ping", time.Now(), &err)
+	return s.next.Ping()
+}
+
+func (s *Storage) Diagnostics() (d storage.Diagnostics, err error) {
+	defer s.observe("Diagnostics", time.Now(), &err)
+	return s.next.Diagnostics()
+}
+
+func (s *Storage) WithProject(projectID int) storage.Interface {
+	c := *s
+	c.next = s.next.WithProject(projectID)
+	return &c
+}
+
+func (s *Storage) ProjectID() int {
+	return s.next.ProjectID()
+}
+
+func (s *Storage) WithContext(ctx context.Context) storage.Interface {
+	c := *s
+	c.next = s.next.WithContext(ctx)
+	return &c
+}
+
+func (s *Storage) Close() {
+	s.next.Close()
+}
@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage/postgresql"
+	"DB_Apps/pkg/storage/storagetest"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newTestStorage(t *testing.T) (*Storage, *storagetest.Fake, *prometheus.Registry) {
+	t.Helper()
+	next := storagetest.New()
+	reg := prometheus.NewRegistry()
+	s, err := New(next, reg, postgresql.ErrorCategory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, next, reg
+}
+
+// sampleCounts возвращает число наблюдений гистограммы времени выполнения по методам
+func sampleCounts(t *testing.T, reg *prometheus.Registry) map[string]uint64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]uint64)
+	for _, f := range families {
+		if f.GetName() != "db_apps_storage_operation_duration_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "method" {
+					counts[l.GetValue()] = m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return counts
+}
+
+func TestDurationPerMethod(t *testing.T) {
+	s, next, reg := newTestStorage(t)
+	next.Users[1] = model.User{ID: 1}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.SelectUserByID(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.NewTask(model.Task{Title: "Задача"}); err != nil {
+		t.Fatal(err)
+	}
+	// Ошибочный вызов тоже учитывается во времени выполнения
+	_, _ = s.SelectUserByID(2)
+	// Итератор учитывается один раз за весь проход, в том числе при досрочном выходе
+	for range s.IterTasks() {
+		break
+	}
+
+	got := sampleCounts(t, reg)
+	want := map[string]uint64{"SelectUserByID": 3, "NewTask": 1, "IterTasks": 1}
+	if len(got) != len(want) {
+		t.Errorf("методы гистограммы %v, want %v", got, want)
+	}
+	for method, n := range want {
+		if got[method] != n {
+			t.Errorf("наблюдений %s: %d, want %d", method, got[method], n)
+		}
+	}
+}
+
+func TestErrorsByCategory(t *testing.T) {
+	s, next, reg := newTestStorage(t)
+
+	// Не найден
+	_, _ = s.SelectUserByID(1)
+	_, _ = s.SelectUserByID(2)
+	// Нарушение ограничения и конфликт транзакций
+	next.Err = &pgconn.PgError{Code: "23505"}
+	_, _ = s.NewTask(model.Task{Title: "Задача"})
+	next.Err = &pgconn.PgError{Code: postgresql.SerializationFailure}
+	_ = s.DeleteTask(1)
+	for _, err := range s.IterTasks() {
+		if err != nil {
+			break
+		}
+	}
+	// Успешный вызов ошибок не добавляет
+	next.Err = nil
+	if _, err := s.NewTask(model.Task{Title: "Задача"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+# HELP db_apps_storage_operation_errors_total Количество ошибок методов хранилища по категориям.
+# TYPE db_apps_storage_operation_errors_total counter
+db_apps_storage_operation_errors_total{category="conflict",method="DeleteTask"} 1
+db_apps_storage_operation_errors_total{category="conflict",method="IterTasks"} 1
+db_apps_storage_operation_errors_total{category="constraint",method="NewTask"} 1
+db_apps_storage_operation_errors_total{category="not_found",method="SelectUserByID"} 2
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "db_apps_storage_operation_errors_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewDuplicateRegistration(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := New(storagetest.New(), reg, postgresql.ErrorCategory); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(storagetest.New(), reg, postgresql.ErrorCategory); err == nil {
+		t.Error("повторная регистрация метрик в том же реестре: error = nil")
+	}
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"DB_Apps/pkg/storage/postgresql"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolStats - источник статистики пула соединений и счетчиков повторов, его реализует postgresql.Storage
+type PoolStats interface {
+	PoolStat() *pgxpool.Stat
+	RetryStats() postgresql.RetryStats
+}
+
+// PoolCollector собирает статистику пула соединений и счетчики повторов хранилища
+// Значения читаются в момент запроса /metrics
+type PoolCollector struct {
+	db PoolStats
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	constructingConns    *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	retries              *prometheus.Desc
+	retriesExhausted     *prometheus.Desc
+}
+
+// NewPoolCollector создает сборщик статистики пула для db
+func NewPoolCollector(db PoolStats) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &PoolCollector{
+		db:                   db,
+		acquiredConns:        desc("acquired_conns", "Количество занятых соединений."),
+		idleConns:            desc("idle_conns", "Количество свободных соединений."),
+		constructingConns:    desc("constructing_conns", "Количество устанавливаемых соединений."),
+		totalConns:           desc("total_conns", "Общее количество соединений в пуле."),
+		maxConns:             desc("max_conns", "Максимальный размер пула."),
+		acquireCount:         desc("acquire_total", "Количество успешных получений соединения из пула."),
+		acquireDuration:      desc("acquire_duration_seconds_total", "Суммарное время ожидания соединения из пула."),
+		emptyAcquireCount:    desc("empty_acquire_total", "Количество получений, которым пришлось ждать соединение."),
+		canceledAcquireCount: desc("canceled_acquire_total", "Количество получений, отмененных до выдачи соединения."),
+		retries:              desc("retries_total", "Количество повторов операций после временных ошибок."),
+		retriesExhausted:     desc("retries_exhausted_total", "Количество операций, завершившихся ошибкой после всех попыток."),
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	st := c.db.PoolStat()
+	rs := c.db.RetryStats()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(st.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(st.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(st.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(st.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(st.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(st.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, st.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(st.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(st.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.retries, prometheus.CounterValue, float64(rs.Retries))
+	ch <- prometheus.MustNewConstMetric(c.retriesExhausted, prometheus.CounterValue, float64(rs.Exhausted))
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"DB_Apps/pkg/storage/postgresql"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakePoolStats возвращает статистику пула, который не подключается к БД, и заданные счетчики повторов
+type fakePoolStats struct {
+	pool  *pgxpool.Pool
+	retry postgresql.RetryStats
+}
+
+func (f fakePoolStats) PoolStat() *pgxpool.Stat           { return f.pool.Stat() }
+func (f fakePoolStats) RetryStats() postgresql.RetryStats { return f.retry }
+
+func TestPoolCollector(t *testing.T) {
+	cfg, err := pgxpool.ParseConfig("postgres://localhost:1/test?pool_max_conns=7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.LazyConnect = true
+	pool, err := pgxpool.ConnectConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	c := NewPoolCollector(fakePoolStats{pool: pool, retry: postgresql.RetryStats{Retries: 5, Exhausted: 2}})
+
+	want := `
+# HELP db_apps_db_pool_acquired_conns Количество занятых соединений.
+# TYPE db_apps_db_pool_acquired_conns gauge
+db_apps_db_pool_acquired_conns 0
+# HELP db_apps_db_pool_max_conns Максимальный размер пула.
+# TYPE db_apps_db_pool_max_conns gauge
+db_apps_db_pool_max_conns 7
+# HELP db_apps_db_pool_total_conns Общее количество соединений в пуле.
+# TYPE db_apps_db_pool_total_conns gauge
+db_apps_db_pool_total_conns 0
+# HELP db_apps_db_pool_retries_total Количество повторов операций после временных ошибок.
+# TYPE db_apps_db_pool_retries_total counter
+db_apps_db_pool_retries_total 5
+# HELP db_apps_db_pool_retries_exhausted_total Количество операций, завершившихся ошибкой после всех попыток.
+# TYPE db_apps_db_pool_retries_exhausted_total counter
+db_apps_db_pool_retries_exhausted_total 2
+`
+	err = testutil.CollectAndCompare(c, strings.NewReader(want),
+		"db_apps_db_pool_acquired_conns", "db_apps_db_pool_max_conns", "db_apps_db_pool_total_conns",
+		"db_apps_db_pool_retries_total", "db_apps_db_pool_retries_exhausted_total")
+	if err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(c); n != 11 {
+		t.Errorf("метрик %d, want 11", n)
+	}
+	if problems, err := testutil.CollectAndLint(c); err != nil || len(problems) > 0 {
+		t.Errorf("CollectAndLint() = %v, %v", problems, err)
+	}
+}
@@ -0,0 +1,305 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Ошибки вложений
+var (
+	AttachmentNameErr = errors.New("Некорректное имя файла вложения")
+	AttachmentTypeErr = errors.New("Некорректный MIME-тип вложения")
+	AttachmentSizeErr = errors.New("Размер вложения превышает допустимый")
+)
+
+const (
+	// Наибольший размер вложения по умолчанию
+	DefaultMaxAttachmentSize = 10 << 20
+	// Наибольшая длина имени файла вложения в символах
+	MaxAttachmentNameLength = 255
+	// Время на загрузку содержимого: если вложение не сохранено за это время, то содержимое удаляется
+	blobUploadGrace = time.Hour
+	// Наибольшее число ключей, удаляемых из хранилища содержимого за один вызов purgeBlobs
+	blobPurgeBatch = 100
+)
+
+// Столбцы вложения в порядке scanAttachment
+const attachmentColumns = `attachments.id, attachments.task_id, attachments.uploader_id, attachments.name,
+	attachments.size, attachments.mime_type, attachments.sha256, attachments.created`
+
+// NewAttachment сохраняет файл content как вложение задачи a.TaskID и возвращает ID вложения,
+// подписчики задачи получают уведомление
+// Задача должна быть в проекте хранилища, загрузивший пользователь a.UploaderID - состоять в нем
+// Имя файла очищается от каталогов, MIME-тип без параметров или application/octet-stream
+// определяется по началу содержимого; размер и SHA-256 вычисляются при записи
+// Содержимое больше наибольшего размера (Options.MaxAttachmentSize) не сохраняется - AttachmentSizeErr
+// Содержимое записывается в хранилище содержимого вне транзакции, до сохранения метаданных его ключ стоит
+// в очереди удаления, поэтому при ошибке или откате транзакции WithTx оно будет удалено
+func (s *Storage) NewAttachment(a model.Attachment, content io.Reader) (int, error) {
+	var err error
+	if a.Name, err = attachmentName(a.Name); err != nil {
+		return 0, err
+	}
+	if a.MIMEType, err = attachmentType(a.MIMEType); err != nil {
+		return 0, err
+	}
+	// Задача и пользователь проверяются до загрузки, чтобы не принимать содержимое зря
+	if _, err := s.checkAttachmentTask(s.db, a.TaskID, a.UploaderID); err != nil {
+		return 0, err
+	}
+
+	key, err := newBlobKey()
+	if err != nil {
+		return 0, err
+	}
+	// Очередь пополняется вне транзакции WithTx, чтобы ключ остался в ней при откате
+	_, err = s.pool.Exec(s.ctx, `INSERT INTO blob_purge_queue(blob_key, purge_after) VALUES ($1, $2);`,
+		key, time.Now().Add(blobUploadGrace))
+	if err != nil {
+		return 0, fmt.Errorf("Ошибка при постановке содержимого в очередь удаления: %w", err)
+	}
+
+	br := bufio.NewReader(content)
+	if a.MIMEType == "" {
+		head, _ := br.Peek(512)
+		a.MIMEType = http.DetectContentType(head)
+	}
+	hash := sha256.New()
+	limited := &io.LimitedReader{R: io.TeeReader(br, hash), N: s.maxAttachmentSize + 1}
+	if a.Size, err = s.blobs.Put(s.ctx, key, limited); err != nil {
+		s.discardBlob(key)
+		return 0, fmt.Errorf("Ошибка при записи содержимого вложения: %w", err)
+	}
+	if a.Size > s.maxAttachmentSize {
+		s.discardBlob(key)
+		return 0, fmt.Errorf("%w: больше %d байт", AttachmentSizeErr, s.maxAttachmentSize)
+	}
+	a.SHA256 = hash.Sum(nil)
+
+	var id int
+	err = s.withTxRetry(s.retry.MaxAttempts, func() error {
+		tx, err := s.db.Begin(s.ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(s.ctx)
+
+		task, err := s.checkAttachmentTask(tx, a.TaskID, a.UploaderID)
+		if err != nil {
+			return err
+		}
+		err = tx.QueryRow(s.ctx, `INSERT INTO attachments(task_id, uploader_id, name, size, mime_type, sha256, blob_key)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id;`,
+			a.TaskID, a.UploaderID, a.Name, a.Size, a.MIMEType, a.SHA256, key).Scan(&id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(s.ctx, `DELETE FROM blob_purge_queue WHERE blob_key = $1;`, key); err != nil {
+			return err
+		}
+		n := s.newNotifications(task, a.UploaderID)
+		if err := s.addWatchers(tx, n, fmt.Sprintf("Добавлено вложение %q", a.Name)); err != nil {
+			return err
+		}
+		if err := s.send(tx, n); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(s.ctx); err != nil {
+			return fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.discardBlob(key)
+		return 0, err
+	}
+	return id, nil
+}
+
+// DeleteAttachment удаляет вложение задачи проекта и его содержимое
+// Если вложение не найдено, то возвращает ошибку
+func (s *Storage) DeleteAttachment(id int) error {
+	r, err := s.db.Exec(s.ctx, `DELETE FROM attachments USING tasks
+		WHERE attachments.id = $1 AND tasks.id = attachments.task_id AND tasks.project_id = $2;`, id, s.project)
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Вложение с ID %d не найдено", id)
+	}
+	s.blobsReleased()
+	return nil
+}
+
+// SelectAttachmentByID возвращает метаданные вложения задачи проекта
+// Если вложение не найдено, то возвращает ошибку
+func (s *Storage) SelectAttachmentByID(id int) (model.Attachment, error) {
+	return retryValue(s, func() (model.Attachment, error) {
+		a, err := scanAttachment(s.db.QueryRow(s.ctx, "SELECT "+attachmentColumns+` FROM attachments
+			JOIN tasks ON tasks.id = attachments.task_id
+			WHERE attachments.id = $1 AND tasks.project_id = $2;`, id, s.project))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return a, myerrors.NotFound("Вложение с ID %d не найдено", id)
+		}
+		return a, err
+	})
+}
+
+// SelectAttachments возвращает метаданные вложений задачи проекта в порядке загрузки
+func (s *Storage) SelectAttachments(taskID int) ([]model.Attachment, error) {
+	return retryValue(s, func() ([]model.Attachment, error) {
+		return collect(s, func(rows pgx.Rows) (model.Attachment, error) {
+			return scanAttachment(rows)
+		}, "SELECT "+attachmentColumns+` FROM attachments
+			JOIN tasks ON tasks.id = attachments.task_id
+			WHERE attachments.task_id = $1 AND tasks.project_id = $2
+			ORDER BY attachments.id;`, taskID, s.project)
+	})
+}
+
+// OpenAttachment возвращает метаданные вложения задачи проекта и его содержимое для чтения
+// Содержимое читается из хранилища содержимого по мере чтения, вызывающий должен его закрыть
+// Если вложение не найдено, то возвращает ошибку
+func (s *Storage) OpenAttachment(id int) (model.Attachment, io.ReadCloser, error) {
+	var key string
+	a, err := retryValue(s, func() (model.Attachment, error) {
+		a, err := scanAttachment(s.db.QueryRow(s.ctx, "SELECT "+attachmentColumns+`, attachments.blob_key
+			FROM attachments JOIN tasks ON tasks.id = attachments.task_id
+			WHERE attachments.id = $1 AND tasks.project_id = $2;`, id, s.project), &key)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return a, myerrors.NotFound("Вложение с ID %d не найдено", id)
+		}
+		return a, err
+	})
+	if err != nil {
+		return a, nil, err
+	}
+	content, err := s.blobs.Open(s.ctx, key)
+	if err != nil {
+		return a, nil, fmt.Errorf("Ошибка при открытии содержимого вложения %d: %w", id, err)
+	}
+	return a, content, nil
+}
+
+// checkAttachmentTask проверяет, что задача есть в проекте хранилища, а пользователь состоит в нем,
+// и возвращает задачу с заголовком для уведомлений
+// В транзакции задача блокируется от удаления до ее завершения
+func (s *Storage) checkAttachmentTask(q querier, taskID, uploaderID int) (model.Task, error) {
+	task := model.Task{ID: taskID, ProjectID: s.project}
+	err := q.QueryRow(s.ctx, `SELECT title FROM tasks WHERE id = $1 AND project_id = $2 FOR SHARE;`,
+		taskID, s.project).Scan(&task.Title)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return task, myerrors.NotFound("Задача с ID %d не найдена", taskID)
+	}
+	if err != nil {
+		return task, err
+	}
+	return task, s.checkMembers(q, uploaderID)
+}
+
+// blobsReleased вызывается после удаления вложений, в том числе вместе с задачами
+// Вне транзакции WithTx содержимое удаляется сразу, внутри - после фиксации внешней транзакции
+func (s *Storage) blobsReleased() {
+	if s.tx == nil {
+		s.purgeBlobs()
+	} else if s.purgeAfterTx != nil {
+		*s.purgeAfterTx = true
+	}
+}
+
+// purgeBlobs удаляет из хранилища содержимого ключи очереди удаления, срок которых наступил
+// Ошибки только записываются в журнал: оставшиеся ключи будут удалены при следующем вызове
+func (s *Storage) purgeBlobs() {
+	keys, err := collect(s, func(rows pgx.Rows) (string, error) {
+		var key string
+		err := rows.Scan(&key)
+		return key, err
+	}, `SELECT blob_key FROM blob_purge_queue WHERE purge_after <= now() ORDER BY purge_after LIMIT $1;`,
+		blobPurgeBatch)
+	if err != nil {
+		s.logger.WarnContext(s.ctx, "Ошибка при чтении очереди удаления содержимого", slog.Any("error", err))
+		return
+	}
+	for _, key := range keys {
+		s.discardBlob(key)
+	}
+}
+
+// discardBlob удаляет содержимое из хранилища содержимого и его ключ из очереди удаления
+func (s *Storage) discardBlob(key string) {
+	if err := s.blobs.Delete(s.ctx, key); err != nil {
+		s.logger.WarnContext(s.ctx, "Ошибка при удалении содержимого вложения",
+			slog.String("blob_key", key), slog.Any("error", err))
+		return
+	}
+	if _, err := s.pool.Exec(s.ctx, `DELETE FROM blob_purge_queue WHERE blob_key = $1;`, key); err != nil {
+		s.logger.WarnContext(s.ctx, "Ошибка при удалении ключа из очереди удаления содержимого",
+			slog.String("blob_key", key), slog.Any("error", err))
+	}
+}
+
+// newBlobKey возвращает случайный ключ содержимого из 32 шестнадцатеричных цифр
+func newBlobKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Ошибка при создании ключа содержимого: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// attachmentName возвращает имя файла без каталогов и пробелов по краям
+// Пустое имя, "." и "..", управляющие символы и имя длиннее MaxAttachmentNameLength - AttachmentNameErr
+func attachmentName(name string) (string, error) {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." || !utf8.ValidString(name) ||
+		utf8.RuneCountInString(name) > MaxAttachmentNameLength || strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "", fmt.Errorf("%w: %q", AttachmentNameErr, name)
+	}
+	return name, nil
+}
+
+// attachmentType проверяет и нормализует MIME-тип
+// Пустая строка и application/octet-stream без параметров означают, что тип нужно определить по содержимому
+func attachmentType(mimeType string) (string, error) {
+	mimeType = strings.TrimSpace(mimeType)
+	if mimeType == "" {
+		return "", nil
+	}
+	mediaType, params, err := mime.ParseMediaType(mimeType)
+	if err != nil || !strings.Contains(mediaType, "/") {
+		return "", fmt.Errorf("%w: %q", AttachmentTypeErr, mimeType)
+	}
+	if mediaType == "application/octet-stream" && len(params) == 0 {
+		return "", nil
+	}
+	return mime.FormatMediaType(mediaType, params), nil
+}
+
+// scanAttachment считывает строку со столбцами attachmentColumns в метаданные вложения
+// Значения дополнительных столбцов после attachmentColumns записываются в extra
+func scanAttachment(row pgx.Row, extra ...any) (model.Attachment, error) {
+	var a model.Attachment
+	err := row.Scan(append([]any{&a.ID, &a.TaskID, &a.UploaderID, &a.Name, &a.Size, &a.MIMEType, &a.SHA256,
+		&a.Created}, extra...)...)
+	return a, err
+}
@@ -0,0 +1,113 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// countRows возвращает число строк таблицы table
+func countRows(t *testing.T, pool *pgxpool.Pool, table string) int {
+	t.Helper()
+	var n int
+	if err := pool.QueryRow(context.Background(), "SELECT count(*) FROM "+table+";").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func newTestTask(t *testing.T, s storage.Interface) int {
+	t.Helper()
+	id, err := s.NewTask(model.Task{Title: "Задача с вложением"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// Содержимое больше наибольшего размера не сохраняется и удаляется из хранилища содержимого
+func TestNewAttachmentSizeLimit(t *testing.T) {
+	url, pool := testDatabase(t)
+	migrate(t, url)
+	const maxSize = blobChunkSize + 10
+	s, err := NewWithOptions(url, Options{MaxAttachmentSize: maxSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	taskID := newTestTask(t, s)
+
+	content := bytes.Repeat([]byte("x"), maxSize+1)
+	_, err = s.NewAttachment(model.Attachment{TaskID: taskID, Name: "big.bin"}, bytes.NewReader(content))
+	if !errors.Is(err, AttachmentSizeErr) {
+		t.Errorf("NewAttachment(%d байт) error = %v, want AttachmentSizeErr", len(content), err)
+	}
+	if n := countRows(t, pool, "attachment_blobs"); n != 0 {
+		t.Errorf("осталось частей содержимого %d, want 0", n)
+	}
+	if n := countRows(t, pool, "blob_purge_queue"); n != 0 {
+		t.Errorf("осталось ключей в очереди удаления %d, want 0", n)
+	}
+
+	id, err := s.NewAttachment(model.Attachment{TaskID: taskID, Name: "max.bin"}, bytes.NewReader(content[:maxSize]))
+	if err != nil {
+		t.Fatalf("NewAttachment(%d байт) error = %v", maxSize, err)
+	}
+	a, r, err := s.OpenAttachment(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil || a.Size != maxSize || !bytes.Equal(got, content[:maxSize]) {
+		t.Errorf("прочитано %d байт, размер %d, ошибка %v, want %d байт", len(got), a.Size, err, maxSize)
+	}
+}
+
+// Содержимое вложений удаляется вместе с задачей, в том числе после фиксации транзакции WithTx
+func TestDeleteTaskPurgesBlobs(t *testing.T) {
+	s, pool := testStorage(t)
+
+	attach := func() int {
+		taskID := newTestTask(t, s)
+		_, err := s.NewAttachment(model.Attachment{TaskID: taskID, Name: "note.txt"}, bytes.NewReader([]byte("текст")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return taskID
+	}
+
+	if err := s.DeleteTask(attach()); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, pool, "attachment_blobs"); n != 0 {
+		t.Errorf("после DeleteTask осталось частей содержимого %d, want 0", n)
+	}
+
+	taskID := attach()
+	err := s.WithTx(func(tx storage.Interface) error {
+		if err := tx.DeleteTask(taskID); err != nil {
+			return err
+		}
+		// До фиксации содержимое не удаляется: при откате вложение останется
+		if n := countRows(t, pool, "attachment_blobs"); n != 1 {
+			t.Errorf("до фиксации частей содержимого %d, want 1", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, pool, "attachment_blobs"); n != 0 {
+		t.Errorf("после WithTx осталось частей содержимого %d, want 0", n)
+	}
+	if n := countRows(t, pool, "blob_purge_queue"); n != 0 {
+		t.Errorf("осталось ключей в очереди удаления %d, want 0", n)
+	}
+}
@@ -0,0 +1,112 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// SetUserPassword сохраняет хеш пароля пользователя
+// Хеширование выполняется вызывающим кодом (пакет auth), в БД пароль в открытом виде не попадает
+// Если пользователь не найден, то возвращает ошибку
+func (s *Storage) SetUserPassword(userID int, hash string) error {
+	r, err := s.db.Exec(s.ctx, "UPDATE users SET password_hash = $1 WHERE id = $2;", hash, userID)
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Пользователь с ID %d не найден", userID)
+	}
+	return nil
+}
+
+// SelectUserPassword возвращает хеш пароля пользователя
+// Пустая строка - пароль не задан
+// Если пользователь не найден, то возвращает ошибку
+func (s *Storage) SelectUserPassword(userID int) (string, error) {
+	return retryValue(s, func() (string, error) {
+		var hash string
+		err := s.db.QueryRow(s.ctx, "SELECT password_hash FROM users WHERE id = $1;", userID).Scan(&hash)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", myerrors.NotFound("Пользователь с ID %d не найден", userID)
+		}
+		return hash, err
+	})
+}
+
+// NewToken сохраняет выданный токен и возвращает его ID
+func (s *Storage) NewToken(t model.Token) (int, error) {
+	var id int
+	err := s.db.QueryRow(s.ctx, `INSERT INTO auth_tokens(user_id, kind, name, token_hash, expires_at)
+								VALUES ($1, $2, $3, $4, $5) RETURNING id;`,
+		t.UserID, t.Kind, t.Name, t.Hash, nullTime(t.ExpiresAt)).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == ForeignKeyViolation {
+			return 0, myerrors.NotFound("Пользователь с ID %d не найден", t.UserID)
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// SelectTokenByHash возвращает токен по его хешу, в том числе отозванный или просроченный
+// Если токен не найден, то возвращает ошибку
+func (s *Storage) SelectTokenByHash(hash []byte) (model.Token, error) {
+	return retryValue(s, func() (model.Token, error) {
+		var t model.Token
+		var expires, revoked *time.Time
+		err := s.db.QueryRow(s.ctx, `SELECT id, user_id, kind, name, token_hash, created_at, expires_at, revoked_at
+									FROM auth_tokens WHERE token_hash = $1;`, hash).
+			Scan(&t.ID, &t.UserID, &t.Kind, &t.Name, &t.Hash, &t.CreatedAt, &expires, &revoked)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return t, myerrors.NotFound("Токен не найден")
+			}
+			return t, err
+		}
+		if expires != nil {
+			t.ExpiresAt = *expires
+		}
+		if revoked != nil {
+			t.RevokedAt = *revoked
+		}
+		return t, nil
+	})
+}
+
+// RevokeToken отзывает токен по ID
+// Повторный отзыв не меняет время отзыва
+// Если токен не найден, то возвращает ошибку
+func (s *Storage) RevokeToken(id int) error {
+	r, err := s.db.Exec(s.ctx, "UPDATE auth_tokens SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1;", id)
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Токен с ID %d не найден", id)
+	}
+	return nil
+}
+
+// RevokeUserTokens отзывает все действующие токены пользователя и возвращает их количество
+func (s *Storage) RevokeUserTokens(userID int) (int, error) {
+	r, err := s.db.Exec(s.ctx, `UPDATE auth_tokens SET revoked_at = now()
+								WHERE user_id = $1 AND revoked_at IS NULL;`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return int(r.RowsAffected()), nil
+}
+
+// nullTime возвращает nil для нулевого времени, чтобы в БД записался NULL
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
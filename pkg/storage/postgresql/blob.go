@@ -0,0 +1,141 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/myerrors"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Размер части содержимого в таблице attachment_blobs
+const blobChunkSize = 256 << 10
+
+// tableBlobs - хранилище содержимого вложений в таблице attachment_blobs, используется по умолчанию
+// Содержимое делится на части, чтобы загрузка и выдача не держали файл в памяти целиком
+type tableBlobs struct {
+	db querier
+}
+
+// Put сначала читает содержимое во временный файл: транзакция и соединение пула не занимаются,
+// пока клиент медленно передает файл
+// Затем содержимое записывается частями в одной транзакции, поэтому при ошибке ничего не сохраняется
+func (b tableBlobs) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	f, err := os.CreateTemp("", "blob-*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("Ошибка при создании временного файла содержимого: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return b.put(ctx, key, f)
+}
+
+// put записывает прочитанное содержимое частями в одной транзакции
+// Пустое содержимое хранится одной пустой частью, чтобы его можно было открыть
+func (b tableBlobs) put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	tx, err := b.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	buf := make([]byte, blobChunkSize)
+	var size int64
+	for seq := 0; ; seq++ {
+		n, readErr := io.ReadFull(r, buf)
+		if readErr == io.EOF && seq > 0 {
+			break
+		}
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return 0, readErr
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO attachment_blobs(blob_key, seq, data) VALUES ($1, $2, $3);`,
+			key, seq, buf[:n])
+		if err != nil {
+			return 0, fmt.Errorf("Ошибка при записи части %d содержимого: %w", seq, err)
+		}
+		size += int64(n)
+		if readErr != nil {
+			break
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+	}
+	return size, nil
+}
+
+// Open читает первую часть сразу, чтобы отсутствующее содержимое было ошибкой открытия, а не чтения
+func (b tableBlobs) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	r := &blobReader{ctx: ctx, db: b.db, key: key}
+	ok, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, myerrors.NotFound("Содержимое вложения %s не найдено", key)
+	}
+	return r, nil
+}
+
+func (b tableBlobs) Delete(ctx context.Context, key string) error {
+	_, err := b.db.Exec(ctx, `DELETE FROM attachment_blobs WHERE blob_key = $1;`, key)
+	return err
+}
+
+// blobReader читает содержимое из attachment_blobs по одной части за запрос
+type blobReader struct {
+	ctx  context.Context
+	db   querier
+	key  string
+	seq  int
+	buf  []byte
+	done bool
+}
+
+// next загружает следующую часть и возвращает false, если частей больше нет
+func (r *blobReader) next() (bool, error) {
+	var data []byte
+	err := r.db.QueryRow(r.ctx, `SELECT data FROM attachment_blobs WHERE blob_key = $1 AND seq = $2;`,
+		r.key, r.seq).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.done = true
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	r.buf = data
+	r.seq++
+	return true, nil
+}
+
+func (r *blobReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if _, err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *blobReader) Close() error {
+	r.done, r.buf = true, nil
+	return nil
+}
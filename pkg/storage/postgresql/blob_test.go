@@ -0,0 +1,107 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// drainReader сообщает, прочитано ли содержимое до конца
+type drainReader struct {
+	r       io.Reader
+	drained bool
+}
+
+func (r *drainReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err == io.EOF {
+		r.drained = true
+	}
+	return n, err
+}
+
+// blobTx запоминает записанные части содержимого
+type blobTx struct {
+	pgx.Tx
+	chunks    [][]byte
+	committed bool
+}
+
+func (tx *blobTx) Exec(_ context.Context, _ string, args ...any) (pgconn.CommandTag, error) {
+	tx.chunks = append(tx.chunks, bytes.Clone(args[2].([]byte)))
+	return nil, nil
+}
+
+func (tx *blobTx) Commit(context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *blobTx) Rollback(context.Context) error { return nil }
+
+// blobQuerier начинает транзакцию blobTx и проверяет, что содержимое к этому моменту уже прочитано
+type blobQuerier struct {
+	querier
+	tx    *blobTx
+	r     *drainReader
+	early bool
+}
+
+func (q *blobQuerier) Begin(context.Context) (pgx.Tx, error) {
+	if !q.r.drained {
+		q.early = true
+	}
+	return q.tx, nil
+}
+
+func TestTableBlobsPut(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		chunks int
+	}{
+		{name: "пустое содержимое", size: 0, chunks: 1},
+		{name: "одна часть", size: blobChunkSize, chunks: 1},
+		{name: "несколько частей", size: 2*blobChunkSize + 1, chunks: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := bytes.Repeat([]byte("x"), tt.size)
+			q := &blobQuerier{tx: &blobTx{}, r: &drainReader{r: bytes.NewReader(content)}}
+
+			n, err := tableBlobs{db: q}.Put(context.Background(), "key", q.r)
+			if err != nil || n != int64(tt.size) {
+				t.Fatalf("Put() = %d, %v, want %d, nil", n, err, tt.size)
+			}
+			if q.early {
+				t.Error("транзакция начата до того, как содержимое прочитано")
+			}
+			if len(q.tx.chunks) != tt.chunks || !q.tx.committed {
+				t.Errorf("записано частей %d, фиксация %v, want %d, true", len(q.tx.chunks), q.tx.committed, tt.chunks)
+			}
+			if got := bytes.Join(q.tx.chunks, nil); !bytes.Equal(got, content) {
+				t.Errorf("записано %d байт, want %d", len(got), len(content))
+			}
+		})
+	}
+}
+
+// Ошибка чтения содержимого не начинает транзакцию
+func TestTableBlobsPutReadErr(t *testing.T) {
+	readErr := errors.New("обрыв загрузки")
+	r := io.MultiReader(bytes.NewReader([]byte("x")), iotest.ErrReader(readErr))
+	q := &blobQuerier{tx: &blobTx{}, r: &drainReader{r: r}}
+
+	if _, err := (tableBlobs{db: q}).Put(context.Background(), "key", q.r); !errors.Is(err, readErr) {
+		t.Errorf("Put() error = %v, want %v", err, readErr)
+	}
+	if q.early || len(q.tx.chunks) != 0 {
+		t.Error("транзакция начата несмотря на ошибку чтения")
+	}
+}
@@ -0,0 +1,460 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Ошибки досок задач
+var (
+	BoardNameErr   = errors.New("Пустая строка не может быть названием доски")
+	BoardKindErr   = errors.New("Неизвестный вид доски")
+	BoardColumnErr = errors.New("Некорректная колонка доски")
+)
+
+// Задачи доски $1 проекта $2 (столбцы taskColumns, ID колонки, ранг), $3 - ID колонки или 0 для всех колонок
+// Задача попадает в колонку своего положения (board_cards), если колонка ей все еще соответствует,
+// иначе - в первую подходящую по состоянию или метке колонку без ранга
+const boardCardsSQL = `SELECT ` + taskColumns + `, col.id, CASE WHEN col.id = card.column_id THEN card.rank ELSE '' END
+	FROM tasks
+	LEFT JOIN board_cards card ON card.board_id = $1 AND card.task_id = tasks.id
+	CROSS JOIN LATERAL (
+		SELECT c.id, c.position FROM board_columns c
+		WHERE c.board_id = $1 AND (c.status = CASE WHEN tasks.closed IS NULL THEN 'open' ELSE 'closed' END
+			OR EXISTS(SELECT 1 FROM tasks_labels WHERE task_id = tasks.id AND label_id = c.label_id))
+		ORDER BY (c.id = card.column_id) IS TRUE DESC, c.position, c.id
+		LIMIT 1) col
+	WHERE tasks.project_id = $2 AND ($3 = 0 OR col.id = $3)
+	ORDER BY col.position, col.id, CASE WHEN col.id = card.column_id THEN card.rank END NULLS LAST, tasks.id;`
+
+// Столбцы колонки в порядке scanColumn
+const columnColumns = `id, board_id, name, COALESCE(status, ''), COALESCE(label_id, 0)`
+
+// NewBoard создает доску с колонками в проекте хранилища и возвращает ее ID
+// Метки колонок должны принадлежать проекту
+func (s *Storage) NewBoard(b model.Board) (int, error) {
+	if err := checkBoard(&b); err != nil {
+		return 0, err
+	}
+	var id int
+	err := s.withTxRetry(s.retry.MaxAttempts, func() error {
+		tx, err := s.db.Begin(s.ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(s.ctx)
+
+		err = tx.QueryRow(s.ctx, `INSERT INTO boards(project_id, name, kind) VALUES ($1, $2, $3) RETURNING id;`,
+			s.project, b.Name, b.Kind).Scan(&id)
+		if err != nil {
+			return err
+		}
+		if err := s.saveColumns(tx, id, b.Columns); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(s.ctx); err != nil {
+			return fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+		}
+		return nil
+	})
+	return id, err
+}
+
+// UpdateBoard изменяет название и колонки доски по b.ID, вид доски не меняется
+// Колонки с ID изменяются, без ID - добавляются, отсутствующие в b.Columns удаляются вместе с положениями задач в них
+// Если доска или колонка не найдены, то возвращает ошибку
+func (s *Storage) UpdateBoard(b model.Board) error {
+	return s.withTxRetry(s.retry.MaxAttempts, func() error {
+		tx, err := s.db.Begin(s.ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(s.ctx)
+
+		// Колонки проверяются по виду доски из БД
+		err = tx.QueryRow(s.ctx, `SELECT kind FROM boards WHERE id = $1 AND project_id = $2 FOR UPDATE;`,
+			b.ID, s.project).Scan(&b.Kind)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return myerrors.NotFound("Доска с ID %d не найдена", b.ID)
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkBoard(&b); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(s.ctx, `UPDATE boards SET name = $2 WHERE id = $1;`, b.ID, b.Name); err != nil {
+			return err
+		}
+
+		var keep []int
+		for _, c := range b.Columns {
+			if c.ID != 0 {
+				keep = append(keep, c.ID)
+			}
+		}
+		_, err = tx.Exec(s.ctx, `DELETE FROM board_columns WHERE board_id = $1 AND NOT (id = ANY($2::int[]));`,
+			b.ID, keep)
+		if err != nil {
+			return fmt.Errorf("Ошибка при удалении колонок доски %d: %w", b.ID, err)
+		}
+		if err := s.saveColumns(tx, b.ID, b.Columns); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(s.ctx); err != nil {
+			return fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+		}
+		return nil
+	})
+}
+
+// saveColumns проверяет метки колонок и сохраняет колонки доски boardID в порядке columns
+func (s *Storage) saveColumns(tx pgx.Tx, boardID int, columns []model.BoardColumn) error {
+	for i, c := range columns {
+		if c.LabelID != 0 {
+			var ok bool
+			err := tx.QueryRow(s.ctx, `SELECT EXISTS(SELECT 1 FROM labels WHERE id = $1 AND project_id = $2);`,
+				c.LabelID, s.project).Scan(&ok)
+			if err != nil {
+				return fmt.Errorf("Ошибка при проверке метки %d: %w", c.LabelID, err)
+			}
+			if !ok {
+				return myerrors.NotFound("Метка с ID %d не найдена", c.LabelID)
+			}
+		}
+
+		if c.ID == 0 {
+			_, err := tx.Exec(s.ctx, `INSERT INTO board_columns(board_id, name, position, status, label_id)
+				VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, 0));`, boardID, c.Name, i, string(c.Status), c.LabelID)
+			if err != nil {
+				return fmt.Errorf("Ошибка при добавлении колонки %s: %w", c.Name, err)
+			}
+			continue
+		}
+		r, err := tx.Exec(s.ctx, `UPDATE board_columns SET name = $3, position = $4, status = NULLIF($5, ''),
+			label_id = NULLIF($6, 0) WHERE id = $1 AND board_id = $2;`, c.ID, boardID, c.Name, i, string(c.Status), c.LabelID)
+		if err != nil {
+			return fmt.Errorf("Ошибка при изменении колонки %d: %w", c.ID, err)
+		}
+		if r.RowsAffected() == 0 {
+			return myerrors.NotFound("Колонка с ID %d не найдена на доске %d", c.ID, boardID)
+		}
+	}
+	return nil
+}
+
+// DeleteBoard удаляет доску вместе с колонками и положениями задач, задачи не меняются
+// Если доска не найдена, то возвращает ошибку
+func (s *Storage) DeleteBoard(id int) error {
+	r, err := s.db.Exec(s.ctx, `DELETE FROM boards WHERE id = $1 AND project_id = $2;`, id, s.project)
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Доска с ID %d не найдена", id)
+	}
+	return nil
+}
+
+// SelectBoards возвращает доски проекта с колонками, отсортированные по названию
+func (s *Storage) SelectBoards() ([]model.Board, error) {
+	return retryValue(s, func() ([]model.Board, error) {
+		boards, err := collect(s, func(rows pgx.Rows) (model.Board, error) {
+			var b model.Board
+			err := rows.Scan(&b.ID, &b.ProjectID, &b.Name, &b.Kind)
+			return b, err
+		}, `SELECT id, project_id, name, kind FROM boards WHERE project_id = $1 ORDER BY lower(name), id;`, s.project)
+		if err != nil {
+			return nil, err
+		}
+		columns, err := collect(s, scanColumnRows, "SELECT "+columnColumns+` FROM board_columns
+			WHERE board_id IN (SELECT id FROM boards WHERE project_id = $1) ORDER BY board_id, position, id;`, s.project)
+		if err != nil {
+			return nil, err
+		}
+		for i := range boards {
+			for _, c := range columns {
+				if c.BoardID == boards[i].ID {
+					boards[i].Columns = append(boards[i].Columns, c)
+				}
+			}
+		}
+		return boards, nil
+	})
+}
+
+// SelectBoardByID возвращает доску проекта с колонками по ID
+// Если доска не найдена, то возвращает ошибку
+func (s *Storage) SelectBoardByID(id int) (model.Board, error) {
+	return retryValue(s, func() (model.Board, error) {
+		var b model.Board
+		err := s.db.QueryRow(s.ctx, `SELECT id, project_id, name, kind FROM boards WHERE id = $1 AND project_id = $2;`,
+			id, s.project).Scan(&b.ID, &b.ProjectID, &b.Name, &b.Kind)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return b, myerrors.NotFound("Доска с ID %d не найдена", id)
+		}
+		if err != nil {
+			return b, err
+		}
+		b.Columns, err = collect(s, scanColumnRows, "SELECT "+columnColumns+` FROM board_columns
+			WHERE board_id = $1 ORDER BY position, id;`, id)
+		return b, err
+	})
+}
+
+// SelectBoardCards возвращает задачи доски по колонкам в порядке колонок и рангов
+// Задачи, которым не соответствует ни одна колонка, на доску не попадают
+// Если доска не найдена, то возвращает ошибку
+func (s *Storage) SelectBoardCards(boardID int) ([]model.BoardCard, error) {
+	return retryValue(s, func() ([]model.BoardCard, error) {
+		var ok bool
+		err := s.db.QueryRow(s.ctx, `SELECT EXISTS(SELECT 1 FROM boards WHERE id = $1 AND project_id = $2);`,
+			boardID, s.project).Scan(&ok)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, myerrors.NotFound("Доска с ID %d не найдена", boardID)
+		}
+		return collect(s, scanCard, boardCardsSQL, boardID, s.project, 0)
+	})
+}
+
+// MoveBoardTask перемещает задачу в колонку доски после задачи m.AfterID одной транзакцией:
+// задаче назначается состояние или метка колонки (метки других колонок доски снимаются) и ранг между соседями
+// Ранги остальных задач не меняются, кроме задач без ранга перед новым положением, которым ранг назначается по порядку
+// Если состояние или метки задачи изменились, то ее подписчики получают уведомление
+// Если доска, колонка, задача или задача m.AfterID в колонке не найдены, то возвращает ошибку
+func (s *Storage) MoveBoardTask(m model.BoardMove) error {
+	return s.withTxRetry(s.retry.MaxAttempts, func() error {
+		return s.moveBoardTask(m)
+	})
+}
+
+// moveBoardTask выполняет транзакцию MoveBoardTask без повторов
+func (s *Storage) moveBoardTask(m model.BoardMove) error {
+	tx, err := s.db.Begin(s.ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(s.ctx)
+
+	// Блокировка доски исключает одновременное назначение одинаковых рангов
+	var kind string
+	err = tx.QueryRow(s.ctx, `SELECT kind FROM boards WHERE id = $1 AND project_id = $2 FOR UPDATE;`,
+		m.BoardID, s.project).Scan(&kind)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return myerrors.NotFound("Доска с ID %d не найдена", m.BoardID)
+	}
+	if err != nil {
+		return err
+	}
+	column, err := scanColumn(tx.QueryRow(s.ctx, "SELECT "+columnColumns+` FROM board_columns
+		WHERE id = $1 AND board_id = $2;`, m.ColumnID, m.BoardID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return myerrors.NotFound("Колонка с ID %d не найдена на доске %d", m.ColumnID, m.BoardID)
+	}
+	if err != nil {
+		return err
+	}
+	task := model.Task{ID: m.TaskID, ProjectID: s.project}
+	err = tx.QueryRow(s.ctx, `SELECT title FROM tasks WHERE id = $1 AND project_id = $2 FOR UPDATE;`,
+		m.TaskID, s.project).Scan(&task.Title)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return myerrors.NotFound("Задача с ID %d не найдена", m.TaskID)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Число измененных строк задачи и ее меток: подписчики уведомляются, только если задача изменилась,
+	// а не просто переставлена внутри колонки
+	var changed int64
+	if column.Status != "" {
+		r, err := tx.Exec(s.ctx, `UPDATE tasks SET closed = CASE WHEN $2::text = 'closed' THEN COALESCE(closed, now()) END
+			WHERE id = $1 AND (closed IS NULL) = ($2::text = 'closed');`, m.TaskID, string(column.Status))
+		if err != nil {
+			return fmt.Errorf("Ошибка при изменении состояния задачи %d: %w", m.TaskID, err)
+		}
+		changed += r.RowsAffected()
+	} else {
+		r, err := tx.Exec(s.ctx, `DELETE FROM tasks_labels WHERE task_id = $1
+			AND label_id IN (SELECT label_id FROM board_columns WHERE board_id = $2 AND label_id <> $3);`,
+			m.TaskID, m.BoardID, column.LabelID)
+		if err != nil {
+			return fmt.Errorf("Ошибка при снятии меток задачи %d: %w", m.TaskID, err)
+		}
+		changed += r.RowsAffected()
+		r, err = tx.Exec(s.ctx, `INSERT INTO tasks_labels(task_id, label_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;`,
+			m.TaskID, column.LabelID)
+		if err != nil {
+			return fmt.Errorf("Ошибка при добавлении метки %d задаче %d: %w", column.LabelID, m.TaskID, err)
+		}
+		changed += r.RowsAffected()
+	}
+	if changed > 0 {
+		n := s.newNotifications(task, 0)
+		if err := s.addWatchers(tx, n, fmt.Sprintf("Задача перемещена в колонку %q", column.Name)); err != nil {
+			return err
+		}
+		if err := s.send(tx, n); err != nil {
+			return err
+		}
+	}
+
+	txStorage := *s
+	txStorage.db = tx
+	txStorage.tx = tx
+	cards, err := collect(&txStorage, scanCard, boardCardsSQL, m.BoardID, s.project, m.ColumnID)
+	if err != nil {
+		return err
+	}
+	cards = slices.DeleteFunc(cards, func(c model.BoardCard) bool { return c.Task.ID == m.TaskID })
+	after := -1
+	if m.AfterID != 0 {
+		after = slices.IndexFunc(cards, func(c model.BoardCard) bool { return c.Task.ID == m.AfterID })
+		if after < 0 {
+			return myerrors.NotFound("Задача с ID %d не найдена в колонке %d", m.AfterID, m.ColumnID)
+		}
+	}
+
+	prev := ""
+	for i := 0; i <= after; i++ {
+		if cards[i].Rank == "" {
+			cards[i].Rank = rankBetween(prev, "")
+			if err := s.saveCard(tx, m.BoardID, m.ColumnID, cards[i].Task.ID, cards[i].Rank); err != nil {
+				return err
+			}
+		}
+		prev = cards[i].Rank
+	}
+	next := ""
+	if after+1 < len(cards) {
+		next = cards[after+1].Rank
+	}
+	if err := s.saveCard(tx, m.BoardID, m.ColumnID, m.TaskID, rankBetween(prev, next)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(s.ctx); err != nil {
+		return fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+	}
+	return nil
+}
+
+// saveCard сохраняет положение задачи taskID на доске boardID
+func (s *Storage) saveCard(tx pgx.Tx, boardID, columnID, taskID int, rank string) error {
+	_, err := tx.Exec(s.ctx, `INSERT INTO board_cards(board_id, task_id, column_id, rank) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (board_id, task_id) DO UPDATE SET column_id = EXCLUDED.column_id, rank = EXCLUDED.rank;`,
+		boardID, taskID, columnID, rank)
+	if err != nil {
+		return fmt.Errorf("Ошибка при сохранении положения задачи %d на доске %d: %w", taskID, boardID, err)
+	}
+	return nil
+}
+
+// rankBetween возвращает дробный ранг строго между a и b (a < b) из букв a-z, пустые a и b - без ограничения
+// Ранг не оканчивается на 'a', поэтому перед любым рангом всегда есть место для нового
+// Если между a и b места нет (a >= b, например у задач одинаковые ранги, или b - это a с буквами 'a' в конце),
+// то возвращается ранг после a без верхней границы
+func rankBetween(a, b string) string {
+	const digits = int('z'-'a') + 1
+	var rank []byte
+	upper := b != "" && a < b
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(a) {
+			lo = rankDigit(a[i])
+		}
+		hi := digits
+		if upper && i < len(b) {
+			hi = rankDigit(b[i])
+		}
+		if upper && (i >= len(b) || lo > hi) {
+			// Все ранги с префиксом rank больше b: место есть только без верхней границы
+			upper = false
+			hi = digits
+		}
+		if lo == hi {
+			rank = append(rank, byte('a'+lo))
+			continue
+		}
+		if mid := (lo + hi) / 2; mid > lo {
+			return string(append(rank, byte('a'+mid)))
+		}
+		// Между соседними буквами места нет: берется lo и ранг продолжается без верхней границы
+		rank = append(rank, byte('a'+lo))
+		upper = false
+	}
+}
+
+// rankDigit возвращает номер буквы ранга, символы вне a-z приводятся к ближайшей букве
+func rankDigit(c byte) int {
+	switch {
+	case c < 'a':
+		return 0
+	case c > 'z':
+		return int('z' - 'a')
+	}
+	return int(c - 'a')
+}
+
+// checkBoard очищает название доски и колонок от лишних пробелов
+// и проверяет, что колонки соответствуют виду доски
+func checkBoard(b *model.Board) error {
+	b.Name = strings.Join(strings.Fields(b.Name), " ")
+	if b.Name == "" {
+		return BoardNameErr
+	}
+	if b.Kind != model.BoardByStatus && b.Kind != model.BoardByLabel {
+		return fmt.Errorf("%w: %q", BoardKindErr, b.Kind)
+	}
+	if len(b.Columns) == 0 {
+		return fmt.Errorf("%w: у доски должна быть хотя бы одна колонка", BoardColumnErr)
+	}
+	for i := range b.Columns {
+		c := &b.Columns[i]
+		c.Name = strings.Join(strings.Fields(c.Name), " ")
+		if c.Name == "" {
+			return fmt.Errorf("%w: пустое название колонки", BoardColumnErr)
+		}
+		switch b.Kind {
+		case model.BoardByStatus:
+			if (c.Status != model.StateOpen && c.Status != model.StateClosed) || c.LabelID != 0 {
+				return fmt.Errorf("%w: колонке %s должно соответствовать состояние open или closed", BoardColumnErr, c.Name)
+			}
+		case model.BoardByLabel:
+			if c.LabelID == 0 || c.Status != "" {
+				return fmt.Errorf("%w: колонке %s должна соответствовать метка", BoardColumnErr, c.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// scanColumn считывает строку со столбцами columnColumns в колонку доски
+func scanColumn(row pgx.Row) (model.BoardColumn, error) {
+	var c model.BoardColumn
+	err := row.Scan(&c.ID, &c.BoardID, &c.Name, &c.Status, &c.LabelID)
+	return c, err
+}
+
+func scanColumnRows(rows pgx.Rows) (model.BoardColumn, error) {
+	return scanColumn(rows)
+}
+
+// scanCard считывает строку запроса boardCardsSQL в задачу доски
+func scanCard(rows pgx.Rows) (model.BoardCard, error) {
+	var c model.BoardCard
+	task, err := scanTask(rows, &c.ColumnID, &c.Rank)
+	c.Task = task
+	return c, err
+}
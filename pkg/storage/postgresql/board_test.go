@@ -0,0 +1,107 @@
+package postgresql
+
+import (
+	"strings"
+	"testing"
+)
+
+// checkRank проверяет, что ранг r непустой, не оканчивается на 'a', состоит из букв a-z и больше a
+func checkRank(t *testing.T, a, b, r string) {
+	t.Helper()
+	if r == "" || strings.HasSuffix(r, "a") || strings.Trim(r, "abcdefghijklmnopqrstuvwxyz") != "" {
+		t.Errorf("rankBetween(%q, %q) = %q: некорректный ранг", a, b, r)
+	}
+	if r <= a {
+		t.Errorf("rankBetween(%q, %q) = %q, want больше %q", a, b, r, a)
+	}
+}
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{a: "", b: "", want: "n"},
+		{a: "n", b: "", want: "t"},
+		{a: "", b: "n", want: "g"},
+		{a: "a", b: "b", want: "an"},
+		{a: "an", b: "b", want: "at"},
+		{a: "n", b: "o", want: "nn"},
+		{a: "y", b: "z", want: "yn"},
+		{a: "z", b: "", want: "zn"},
+		{a: "zz", b: "", want: "zzn"},
+		{a: "", b: "b", want: "an"},
+		{a: "", b: "ab", want: "aan"},
+		{a: "a", b: "ab", want: "aan"},
+		{a: "b", b: "bn", want: "bg"},
+		{a: "bn", b: "c", want: "bt"},
+	}
+	for _, tt := range tests {
+		got := rankBetween(tt.a, tt.b)
+		if got != tt.want {
+			t.Errorf("rankBetween(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+		checkRank(t, tt.a, tt.b, got)
+		if tt.b != "" && got >= tt.b {
+			t.Errorf("rankBetween(%q, %q) = %q, want меньше %q", tt.a, tt.b, got, tt.b)
+		}
+	}
+}
+
+// Если места между границами нет, то ранг выбирается после a без верхней границы
+func TestRankBetweenNoRoom(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{name: "одинаковые ранги", a: "n", b: "n"},
+		{name: "обратный порядок", a: "t", b: "g"},
+		{name: "b - префикс a", a: "nt", b: "n"},
+		{name: "b - это a с 'a' в конце", a: "c", b: "ca"},
+		{name: "b из одних 'a'", a: "", b: "a"},
+		{name: "b из одних 'a' длиннее", a: "", b: "aaa"},
+		{name: "символы вне a-z", a: "n~", b: "n!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkRank(t, tt.a, tt.b, rankBetween(tt.a, tt.b))
+		})
+	}
+}
+
+// Многократная вставка в одно место дает строго упорядоченные ранги
+func TestRankBetweenRepeated(t *testing.T) {
+	// В начало колонки
+	first := rankBetween("", "")
+	for i := 0; i < 200; i++ {
+		r := rankBetween("", first)
+		checkRank(t, "", first, r)
+		if r >= first {
+			t.Fatalf("rankBetween(\"\", %q) = %q, want меньше", first, r)
+		}
+		first = r
+	}
+
+	// Между двумя соседними задачами
+	lo, hi := "n", "o"
+	for i := 0; i < 200; i++ {
+		r := rankBetween(lo, hi)
+		checkRank(t, lo, hi, r)
+		if r >= hi {
+			t.Fatalf("rankBetween(%q, %q) = %q, want меньше %q", lo, hi, r, hi)
+		}
+		if i%2 == 0 {
+			lo = r
+		} else {
+			hi = r
+		}
+	}
+
+	// В конец колонки
+	last := ""
+	for i := 0; i < 200; i++ {
+		r := rankBetween(last, "")
+		checkRank(t, last, "", r)
+		last = r
+	}
+}
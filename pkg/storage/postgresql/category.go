@@ -0,0 +1,71 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/names"
+	"DB_Apps/pkg/storage"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Категории ошибок хранилища для метрик и журналирования
+const (
+	CategoryValidation = "validation"
+	CategoryNotFound   = "not_found"
+	CategoryConstraint = "constraint"
+	CategoryConflict   = "conflict"
+	CategoryConnection = "connection"
+	CategoryTimeout    = "timeout"
+	CategoryOther      = "other"
+)
+
+// ErrorCategory возвращает категорию ошибки err, полученной от хранилища
+func ErrorCategory(err error) string {
+	var partialErr myerrors.TaskPartialErr
+	var pgErr *pgconn.PgError
+	switch {
+	case names.IsInvalid(err), errors.Is(err, LabelNameErr), errors.As(err, &partialErr),
+		errors.Is(err, UserLoginErr), errors.Is(err, UserEmailErr),
+		errors.Is(err, RecurringTitleErr), errors.Is(err, RecurringScheduleErr),
+		errors.Is(err, TemplateNameErr), errors.Is(err, TemplateTitleErr), errors.Is(err, TemplateVarErr),
+		errors.Is(err, ViewNameErr), errors.Is(err, ViewFilterErr),
+		errors.Is(err, BoardNameErr), errors.Is(err, BoardKindErr), errors.Is(err, BoardColumnErr),
+		errors.Is(err, TaskEstimateErr), errors.Is(err, WorklogDurationErr),
+		errors.Is(err, AttachmentNameErr), errors.Is(err, AttachmentTypeErr), errors.Is(err, AttachmentSizeErr):
+		return CategoryValidation
+	case errors.Is(err, myerrors.NotFoundErr):
+		return CategoryNotFound
+	case errors.Is(err, DuplicateLabelIDErr), errors.Is(err, LabelOrTaskNotExistErr),
+		errors.Is(err, DuplicateLoginErr), errors.Is(err, DuplicateEmailErr), errors.Is(err, DuplicateTemplateErr),
+		errors.Is(err, DuplicateViewErr):
+		return CategoryConstraint
+	case errors.Is(err, storage.RecurringLockedErr):
+		return CategoryConflict
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return CategoryTimeout
+	case errors.As(err, &pgErr):
+		switch {
+		case strings.HasPrefix(pgErr.Code, "23"):
+			return CategoryConstraint
+		case strings.HasPrefix(pgErr.Code, "40"):
+			return CategoryConflict
+		case strings.HasPrefix(pgErr.Code, "08"), strings.HasPrefix(pgErr.Code, "57P"):
+			return CategoryConnection
+		case pgErr.Code == "57014": // query_canceled
+			return CategoryTimeout
+		}
+		return CategoryOther
+	case isRetryable(err):
+		return CategoryConnection
+	}
+	return CategoryOther
+}
+
+// PoolStat возвращает статистику пула соединений
+func (s *Storage) PoolStat() *pgxpool.Stat {
+	return s.pool.Stat()
+}
@@ -0,0 +1,56 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/storage"
+	"fmt"
+	"time"
+)
+
+// Ping проверяет, что сервер БД доступен и отвечает на запросы
+func (s *Storage) Ping() error {
+	return s.pool.Ping(s.ctx)
+}
+
+// Diagnostics возвращает состояние пула соединений, версию сервера,
+// версию примененных миграций и отставание реплики
+func (s *Storage) Diagnostics() (storage.Diagnostics, error) {
+	st := s.pool.Stat()
+	d := storage.Diagnostics{
+		Pool: storage.PoolStats{
+			TotalConns:        st.TotalConns(),
+			IdleConns:         st.IdleConns(),
+			AcquiredConns:     st.AcquiredConns(),
+			MaxConns:          st.MaxConns(),
+			AcquireCount:      st.AcquireCount(),
+			AcquireDuration:   st.AcquireDuration(),
+			EmptyAcquireCount: st.EmptyAcquireCount(),
+		},
+	}
+
+	var lagSeconds float64
+	err := s.db.QueryRow(s.ctx, `SELECT current_setting('server_version'),
+										pg_is_in_recovery(),
+										COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)::float8;`).
+		Scan(&d.ServerVersion, &d.InRecovery, &lagSeconds)
+	if err != nil {
+		return d, fmt.Errorf("Ошибка при получении сведений о сервере: %w", err)
+	}
+	if d.InRecovery {
+		d.ReplicationLag = time.Duration(lagSeconds * float64(time.Second))
+	}
+
+	// Таблица миграций может отсутствовать, если схема создана вручную из schema.sql
+	var hasMigrations bool
+	err = s.db.QueryRow(s.ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL;`).Scan(&hasMigrations)
+	if err != nil {
+		return d, fmt.Errorf("Ошибка при проверке таблицы миграций: %w", err)
+	}
+	if hasMigrations {
+		err = s.db.QueryRow(s.ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations;`).Scan(&d.MigrationVersion)
+		if err != nil {
+			return d, fmt.Errorf("Ошибка при получении версии миграций: %w", err)
+		}
+	}
+
+	return d, nil
+}
@@ -0,0 +1,73 @@
+package postgresql
+
+import (
+	"iter"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// queryIter выполняет запрос и возвращает итератор по строкам результата
+// Строки читаются из pgx по мере поступления, без накопления в срезе
+// При досрочном выходе из цикла (break) курсор закрывается
+// Временная ошибка до получения первой строки приводит к повтору запроса по политике хранилища,
+// после первой строки запрос не повторяется, чтобы не отдать строки дважды
+// Ошибка запроса, сканирования или rows.Err() передается вторым значением, после чего итерация прекращается
+// В транзакции WithTx строки считываются полностью до первой итерации: у транзакции одно соединение,
+// и пока курсор открыт, вызовы хранилища из тела цикла завершались бы ошибкой conn busy
+func queryIter[T any](s *Storage, scan func(pgx.Rows) (T, error), sql string, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		started := false
+		var buffered []T
+		run := func() error {
+			rows, err := s.db.Query(s.ctx, sql, args...)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				v, err := scan(rows)
+				if err != nil {
+					return err
+				}
+				started = true
+				if s.tx != nil {
+					buffered = append(buffered, v)
+					continue
+				}
+				if !yield(v, nil) {
+					return nil
+				}
+			}
+			return rows.Err()
+		}
+
+		attempts := s.retry.MaxAttempts
+		if s.tx != nil {
+			attempts = 1
+		}
+		err := s.retryLoop(attempts, func(err error) bool {
+			return !started && isRetryable(err)
+		}, run)
+		for _, v := range buffered {
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// retryValue выполняет идемпотентный запрос fn по политике повторов хранилища и возвращает его результат
+func retryValue[T any](s *Storage, fn func() (T, error)) (T, error) {
+	var v T
+	err := s.withRetry(func() error {
+		var err error
+		v, err = fn()
+		return err
+	})
+	return v, err
+}
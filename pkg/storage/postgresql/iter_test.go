@@ -0,0 +1,204 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// fakeRows - результат запроса из заданных значений, после которых rows.Err() возвращает err
+type fakeRows struct {
+	pgx.Rows
+	vals   []int
+	err    error
+	next   int
+	closed bool
+}
+
+func (r *fakeRows) Next() bool {
+	if r.closed || r.next >= len(r.vals) {
+		return false
+	}
+	r.next++
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	*dest[0].(*int) = r.vals[r.next-1]
+	return nil
+}
+
+func (r *fakeRows) Err() error {
+	if r.next < len(r.vals) {
+		return nil
+	}
+	return r.err
+}
+
+func (r *fakeRows) Close() { r.closed = true }
+
+// fakeQuerier возвращает результаты запросов по очереди, ошибку вместо результата - если задана queryErrs
+type fakeQuerier struct {
+	querier
+	queryErrs []error
+	results   []*fakeRows
+	calls     int
+}
+
+func (q *fakeQuerier) Query(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+	q.calls++
+	if q.calls <= len(q.queryErrs) && q.queryErrs[q.calls-1] != nil {
+		return nil, q.queryErrs[q.calls-1]
+	}
+	return q.results[q.calls-1], nil
+}
+
+func scanInt(rows pgx.Rows) (int, error) {
+	var v int
+	err := rows.Scan(&v)
+	return v, err
+}
+
+func newIterStorage(q *fakeQuerier) *Storage {
+	s := newRetryStorage(noDelayPolicy)
+	s.db = q
+	return s
+}
+
+// collectIter читает все значения итератора до первой ошибки
+func collectIter(s *Storage) ([]int, error) {
+	var vals []int
+	for v, err := range queryIter(s, scanInt, "SELECT") {
+		if err != nil {
+			return vals, err
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+var transientErr = &pgconn.PgError{Code: SerializationFailure}
+
+func TestQueryIter(t *testing.T) {
+	rows := &fakeRows{vals: []int{1, 2, 3}}
+	q := &fakeQuerier{results: []*fakeRows{rows}}
+
+	vals, err := collectIter(newIterStorage(q))
+	if err != nil || !slices.Equal(vals, []int{1, 2, 3}) {
+		t.Errorf("queryIter() = %v, %v, want [1 2 3], nil", vals, err)
+	}
+	if !rows.closed {
+		t.Error("курсор не закрыт")
+	}
+}
+
+func TestQueryIterBreak(t *testing.T) {
+	rows := &fakeRows{vals: []int{1, 2, 3}}
+	q := &fakeQuerier{results: []*fakeRows{rows}}
+
+	for v, err := range queryIter(newIterStorage(q), scanInt, "SELECT") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v == 1 {
+			break
+		}
+	}
+	if !rows.closed {
+		t.Error("курсор не закрыт после break")
+	}
+	if rows.next != 1 {
+		t.Errorf("прочитано строк %d после break, want 1", rows.next)
+	}
+}
+
+// Ошибка после первой строки передается итератором без повтора запроса
+func TestQueryIterMidStreamErr(t *testing.T) {
+	rows := &fakeRows{vals: []int{1, 2}, err: transientErr}
+	q := &fakeQuerier{results: []*fakeRows{rows, {vals: []int{1, 2, 3}}}}
+
+	vals, err := collectIter(newIterStorage(q))
+	if !errors.Is(err, transientErr) || !slices.Equal(vals, []int{1, 2}) {
+		t.Errorf("queryIter() = %v, %v, want [1 2], ошибка сериализации", vals, err)
+	}
+	if q.calls != 1 {
+		t.Errorf("запросов %d, want 1", q.calls)
+	}
+	if !rows.closed {
+		t.Error("курсор не закрыт после ошибки")
+	}
+}
+
+// Временная ошибка до первой строки приводит к повтору запроса
+func TestQueryIterRetryBeforeFirstRow(t *testing.T) {
+	tests := []struct {
+		name string
+		q    *fakeQuerier
+	}{
+		{
+			name: "ошибка запроса",
+			q:    &fakeQuerier{queryErrs: []error{transientErr}, results: []*fakeRows{nil, {vals: []int{1, 2}}}},
+		},
+		{
+			name: "ошибка чтения пустого результата",
+			q:    &fakeQuerier{results: []*fakeRows{{err: transientErr}, {vals: []int{1, 2}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vals, err := collectIter(newIterStorage(tt.q))
+			if err != nil || !slices.Equal(vals, []int{1, 2}) {
+				t.Errorf("queryIter() = %v, %v, want [1 2], nil", vals, err)
+			}
+			if tt.q.calls != 2 {
+				t.Errorf("запросов %d, want 2", tt.q.calls)
+			}
+		})
+	}
+}
+
+func TestQueryIterPermanentErr(t *testing.T) {
+	permanentErr := &pgconn.PgError{Code: UniqueViolation}
+	q := &fakeQuerier{queryErrs: []error{permanentErr}}
+
+	vals, err := collectIter(newIterStorage(q))
+	if !errors.Is(err, permanentErr) || len(vals) != 0 {
+		t.Errorf("queryIter() = %v, %v, want нарушение уникальности", vals, err)
+	}
+	if q.calls != 1 {
+		t.Errorf("запросов %d, want 1", q.calls)
+	}
+}
+
+// В транзакции курсор закрывается до первой итерации, запрос не повторяется
+func TestQueryIterTx(t *testing.T) {
+	rows := &fakeRows{vals: []int{1, 2, 3}}
+	q := &fakeQuerier{results: []*fakeRows{rows}}
+	s := newIterStorage(q)
+	s.tx = fakeTx{}
+
+	var vals []int
+	for v, err := range queryIter(s, scanInt, "SELECT") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !rows.closed {
+			t.Fatal("курсор открыт в теле цикла")
+		}
+		vals = append(vals, v)
+	}
+	if !slices.Equal(vals, []int{1, 2, 3}) {
+		t.Errorf("queryIter() = %v, want [1 2 3]", vals)
+	}
+
+	q = &fakeQuerier{queryErrs: []error{transientErr}, results: []*fakeRows{nil, {vals: []int{1}}}}
+	s = newIterStorage(q)
+	s.tx = fakeTx{}
+	if _, err := collectIter(s); !errors.Is(err, transientErr) || q.calls != 1 {
+		t.Errorf("queryIter() в транзакции: %v после %d запросов, want ошибку без повтора", err, q.calls)
+	}
+}
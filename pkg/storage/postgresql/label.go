@@ -2,9 +2,9 @@ package postgresql
 
 import (
 	"DB_Apps/pkg/model"
-	"context"
+	"DB_Apps/pkg/myerrors"
 	"errors"
-	"fmt"
+	"iter"
 	"strings"
 
 	"github.com/jackc/pgx/v4"
@@ -13,7 +13,7 @@ import (
 // Ошибка: метка не может быть пустой
 var LabelNameErr = errors.New("Пустая строка не может быть меткой")
 
-// NewLabek создает новую метку в таблице Labels
+// NewLabek создает новую метку проекта в таблице Labels
 // Возвращает ID созданной метки
 // Если имя метки пустое значение, то возвращает ошибку LabelNameErr
 func (s *Storage) NewLabel(l model.Label) (int, error) {
@@ -22,7 +22,7 @@ func (s *Storage) NewLabel(l model.Label) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	err = s.db.QueryRow(context.Background(), "INSERT INTO labels(name) VALUES ($1) RETURNING id;", l.Name).Scan(&id)
+	err = s.db.QueryRow(s.ctx, "INSERT INTO labels(project_id, name) VALUES ($1, $2) RETURNING id;", s.project, l.Name).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -32,13 +32,13 @@ func (s *Storage) NewLabel(l model.Label) (int, error) {
 // DeleteLabel удаляет метку по ID
 // Если метка не найдена, то возвращает ошибку
 func (s *Storage) DeleteLabel(id int) error {
-	r, err := s.db.Exec(context.Background(), "DELETE FROM labels WHERE id = $1;", id)
+	r, err := s.db.Exec(s.ctx, "DELETE FROM labels WHERE id = $1 AND project_id = $2;", id, s.project)
 	if err != nil {
 		return err
 	}
 
 	if r.RowsAffected() == 0 {
-		return fmt.Errorf("Метка с ID: %d не найдена", id)
+		return myerrors.NotFound("Метка с ID: %d не найдена", id)
 	}
 
 	return nil
@@ -52,30 +52,35 @@ func (s *Storage) UpdateLabelName(id int, newName string) error {
 	if err != nil {
 		return err
 	}
-	r, err := s.db.Exec(context.Background(), "UPDATE labels SET name = $1 WHERE id = $2;", newName, id)
+	r, err := s.db.Exec(s.ctx, "UPDATE labels SET name = $1 WHERE id = $2 AND project_id = $3;", newName, id, s.project)
 	if err != nil {
 		return err
 	}
 
 	if r.RowsAffected() == 0 {
-		return fmt.Errorf("Метка с ID %d не найдена", id)
+		return myerrors.NotFound("Метка с ID %d не найдена", id)
 	}
 	return nil
 }
 
-// SelectLabels возвращает список всех меток в порядке возрастания ID
+// SelectLabels возвращает список всех меток проекта в порядке возрастания ID
 // Если меток нет, то возвращает пустой срез
 func (s *Storage) SelectLabels() ([]model.Label, error) {
+	return retryValue(s, s.selectLabels)
+}
+
+// selectLabels выполняет запрос SelectLabels без повторов
+func (s *Storage) selectLabels() ([]model.Label, error) {
 	var labels []model.Label
-	rows, err := s.db.Query(context.Background(), "SELECT id, name FROM labels ORDER BY id ASC;")
+	rows, err := s.db.Query(s.ctx, "SELECT id, project_id, name FROM labels WHERE project_id = $1 ORDER BY id ASC;",
+		s.project)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var label model.Label
-		err := rows.Scan(&label.ID, &label.Name)
+		label, err := scanLabel(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -88,14 +93,29 @@ func (s *Storage) SelectLabels() ([]model.Label, error) {
 	return labels, nil
 }
 
+// IterLabels возвращает итератор по всем меткам проекта в порядке возрастания ID
+// В отличие от SelectLabels не загружает всю таблицу в память
+func (s *Storage) IterLabels() iter.Seq2[model.Label, error] {
+	return queryIter(s, func(rows pgx.Rows) (model.Label, error) {
+		return scanLabel(rows)
+	}, "SELECT id, project_id, name FROM labels WHERE project_id = $1 ORDER BY id ASC;", s.project)
+}
+
 // SelectLabelByID возвращает метку по ее ID
 // Если метка не найдена, то возвращает ошибку
 func (s *Storage) SelectLabelByID(id int) (model.Label, error) {
-	var label model.Label
-	err := s.db.QueryRow(context.Background(), "SELECT id, name FROM labels WHERE id = $1", id).Scan(&label.ID, &label.Name)
+	return retryValue(s, func() (model.Label, error) {
+		return s.selectLabelByID(id)
+	})
+}
+
+// selectLabelByID выполняет запрос SelectLabelByID без повторов
+func (s *Storage) selectLabelByID(id int) (model.Label, error) {
+	label, err := scanLabel(s.db.QueryRow(s.ctx,
+		"SELECT id, project_id, name FROM labels WHERE id = $1 AND project_id = $2", id, s.project))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return label, fmt.Errorf("Пользователь с ID %d не найден", id)
+			return label, myerrors.NotFound("Метка с ID %d не найдена", id)
 		}
 		return label, err
 	}
@@ -103,6 +123,24 @@ func (s *Storage) SelectLabelByID(id int) (model.Label, error) {
 	return label, nil
 }
 
+// SelectLabelsByIDs возвращает метки проекта с ID из ids, отсортированные по ID
+// Несуществующие ID и метки других проектов пропускаются
+func (s *Storage) SelectLabelsByIDs(ids []int) ([]model.Label, error) {
+	return retryValue(s, func() ([]model.Label, error) {
+		return collect(s, func(rows pgx.Rows) (model.Label, error) {
+			return scanLabel(rows)
+		}, "SELECT id, project_id, name FROM labels WHERE id = ANY($1) AND project_id = $2 ORDER BY id ASC;",
+			ids, s.project)
+	})
+}
+
+// scanLabel считывает строку со столбцами id, project_id, name в метку
+func scanLabel(row pgx.Row) (model.Label, error) {
+	var label model.Label
+	err := row.Scan(&label.ID, &label.ProjectID, &label.Name)
+	return label, err
+}
+
 // checkLabelName проверяет корректность имени метки:
 // - Удаляет лишние пробелы спереди и сзади
 // - Сводит подряд идущие пробелы к одному
@@ -0,0 +1,111 @@
+package postgresql
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Файлы миграций схемы: <версия>_<название>.sql
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Ключ рекомендательной блокировки, чтобы миграции не выполнялись одновременно несколькими экземплярами
+const migrationLockKey = 7_221_001
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// Migrate применяет к БД миграции схемы, которые еще не были применены
+// Каждая миграция выполняется в отдельной транзакции и записывается в таблицу schema_migrations
+// БД, созданная из schema.sql, уже содержит записи о всех миграциях, поэтому они к ней не применяются
+// При добавлении миграции schema.sql обновляется вместе со списком версий в schema_migrations
+func (s *Storage) Migrate() error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	// Миграции работают со всеми проектами, поэтому соединение не ограничивается построчной защитой
+	conn, err := s.pool.Acquire(projectContext(s.ctx, allProjects))
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(s.ctx, `SELECT pg_advisory_lock($1);`, migrationLockKey); err != nil {
+		return fmt.Errorf("Ошибка при блокировке миграций: %w", err)
+	}
+	defer conn.Exec(s.ctx, `SELECT pg_advisory_unlock($1);`, migrationLockKey)
+
+	_, err = conn.Exec(s.ctx, `CREATE TABLE IF NOT EXISTS schema_migrations(
+								version INT NOT NULL PRIMARY KEY,
+								name TEXT NOT NULL,
+								applied_at TIMESTAMPTZ NOT NULL DEFAULT now());`)
+	if err != nil {
+		return fmt.Errorf("Ошибка при создании таблицы миграций: %w", err)
+	}
+
+	var current int
+	err = conn.QueryRow(s.ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations;`).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении версии миграций: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		tx, err := conn.Begin(s.ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(s.ctx, m.sql); err != nil {
+			tx.Rollback(s.ctx)
+			return fmt.Errorf("Ошибка при применении миграции %d_%s: %w", m.version, m.name, err)
+		}
+		if _, err := tx.Exec(s.ctx, `INSERT INTO schema_migrations(version, name) VALUES ($1, $2);`,
+			m.version, m.name); err != nil {
+			tx.Rollback(s.ctx)
+			return fmt.Errorf("Ошибка при записи миграции %d_%s: %w", m.version, m.name, err)
+		}
+		if err := tx.Commit(s.ctx); err != nil {
+			return fmt.Errorf("Ошибка при сохранении миграции %d_%s: %w", m.version, m.name, err)
+		}
+		s.logger.InfoContext(s.ctx, "Применена миграция", slog.Int("version", m.version), slog.String("name", m.name))
+	}
+	return nil
+}
+
+// loadMigrations читает встроенные файлы миграций в порядке возрастания версии
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []migration
+	for _, e := range entries {
+		base := strings.TrimSuffix(e.Name(), ".sql")
+		v, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(v)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("Некорректное имя файла миграции: %s", e.Name())
+		}
+		sql, err := migrationFiles.ReadFile("migrations/" + e.Name())
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: name, sql: string(sql)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
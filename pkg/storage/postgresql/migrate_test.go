@@ -0,0 +1,176 @@
+package postgresql
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Проверки миграций выполняются на настоящей БД, адрес которой задается переменной окружения
+// Все таблицы схемы public этой БД удаляются
+const testDatabaseEnv = "DB_APPS_TEST_DATABASE_URL"
+
+// testDatabase возвращает адрес пустой тестовой БД и пул соединений с ней
+// Если адрес не задан, то тест пропускается
+func testDatabase(t *testing.T) (string, *pgxpool.Pool) {
+	t.Helper()
+	url := os.Getenv(testDatabaseEnv)
+	if url == "" {
+		t.Skipf("%s не задана", testDatabaseEnv)
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.Connect(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	if _, err := pool.Exec(ctx, `DROP SCHEMA public CASCADE; CREATE SCHEMA public;`); err != nil {
+		t.Fatal(err)
+	}
+	return url, pool
+}
+
+// latestMigration возвращает номер последней встроенной миграции
+func latestMigration(t *testing.T) int {
+	t.Helper()
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrations[len(migrations)-1].version
+}
+
+func migrate(t *testing.T, url string) {
+	t.Helper()
+	s, err := New(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.Migrate(); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	// Повторный запуск ничего не меняет
+	if err := s.Migrate(); err != nil {
+		t.Fatalf("повторный Migrate() error = %v", err)
+	}
+}
+
+// testStorage возвращает хранилище с актуальной схемой в пустой тестовой БД и пул соединений с ней
+func testStorage(t *testing.T) (*Storage, *pgxpool.Pool) {
+	t.Helper()
+	url, pool := testDatabase(t)
+	migrate(t, url)
+	s, err := New(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	return s, pool
+}
+
+func schemaVersion(t *testing.T, pool *pgxpool.Pool) int {
+	t.Helper()
+	var v int
+	if err := pool.QueryRow(context.Background(), `SELECT MAX(version) FROM schema_migrations;`).Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// Обновление БД с исходной схемой, где время задачи хранится в секундах Unix (BIGINT)
+func TestMigrateFromInit(t *testing.T) {
+	url, pool := testDatabase(t)
+	ctx := context.Background()
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, migrations[0].sql); err != nil {
+		t.Fatal(err)
+	}
+	_, err = pool.Exec(ctx, `CREATE TABLE schema_migrations(
+								version INT NOT NULL PRIMARY KEY,
+								name TEXT NOT NULL,
+								applied_at TIMESTAMPTZ NOT NULL DEFAULT now());
+							INSERT INTO schema_migrations(version, name) VALUES (1, 'init');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	closed := opened.Add(26 * time.Hour)
+	_, err = pool.Exec(ctx, `INSERT INTO tasks(id, opened, closed, title) VALUES (1, $1, 0, 'Открытая'), (2, $1, $2, 'Закрытая');`,
+		opened.Unix(), closed.Unix())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrate(t, url)
+
+	if got, want := schemaVersion(t, pool), latestMigration(t); got != want {
+		t.Errorf("версия схемы %d, want %d", got, want)
+	}
+	for _, column := range []string{"opened", "closed"} {
+		var dataType string
+		err := pool.QueryRow(ctx, `SELECT data_type FROM information_schema.columns
+									WHERE table_schema = current_schema() AND table_name = 'tasks' AND column_name = $1;`,
+			column).Scan(&dataType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dataType != "timestamp with time zone" {
+			t.Errorf("тип столбца %s = %q, want timestamp with time zone", column, dataType)
+		}
+	}
+
+	s, err := New(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	open, err := s.SelectTaskByID(1)
+	if err != nil {
+		t.Fatalf("SelectTaskByID(1) error = %v", err)
+	}
+	if !open.Opened.Equal(opened) || open.Closed != nil {
+		t.Errorf("задача 1: opened %v, closed %v, want %v и nil", open.Opened, open.Closed, opened)
+	}
+	done, err := s.SelectTaskByID(2)
+	if err != nil {
+		t.Fatalf("SelectTaskByID(2) error = %v", err)
+	}
+	if done.Closed == nil || !done.Closed.Equal(closed) {
+		t.Errorf("задача 2: closed %v, want %v", done.Closed, closed)
+	}
+}
+
+// БД, созданная из schema.sql, уже соответствует последней миграции
+func TestMigrateSchemaSQL(t *testing.T) {
+	url, pool := testDatabase(t)
+	schema, err := os.ReadFile("../../../schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(context.Background(), string(schema)); err != nil {
+		t.Fatal(err)
+	}
+
+	migrate(t, url)
+
+	if got, want := schemaVersion(t, pool), latestMigration(t); got != want {
+		t.Errorf("версия схемы %d, want %d: schema.sql не соответствует последней миграции", got, want)
+	}
+}
+
+// Все миграции применяются к пустой БД
+func TestMigrateEmpty(t *testing.T) {
+	url, pool := testDatabase(t)
+	migrate(t, url)
+	if got, want := schemaVersion(t, pool), latestMigration(t); got != want {
+		t.Errorf("версия схемы %d, want %d", got, want)
+	}
+}
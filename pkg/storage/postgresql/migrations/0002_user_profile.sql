@@ -0,0 +1,10 @@
+-- Профиль пользователя: логин, email, отображаемое имя и признак активности
+-- Существующие пользователи получают пустые логин и email и остаются активными
+ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS login TEXT,
+	ADD COLUMN IF NOT EXISTS email TEXT,
+	ADD COLUMN IF NOT EXISTS display_name TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_login_key ON users (lower(login));
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (lower(email));
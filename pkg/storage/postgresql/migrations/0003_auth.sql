@@ -0,0 +1,23 @@
+-- Аутентификация: хеш пароля пользователя и выданные токены
+ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS password_hash TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS auth_tokens(
+id SERIAL NOT NULL UNIQUE,
+user_id INT NOT NULL,
+-- session - токен входа, api - долгоживущий токен для программного доступа
+kind TEXT NOT NULL,
+name TEXT NOT NULL DEFAULT '',
+-- SHA-256 токена, сам токен в БД не хранится
+token_hash BYTEA NOT NULL UNIQUE,
+created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+expires_at TIMESTAMPTZ,
+revoked_at TIMESTAMPTZ,
+
+PRIMARY KEY(id),
+FOREIGN KEY(user_id)
+	REFERENCES users(id)
+	ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS auth_tokens_user_id_idx ON auth_tokens (user_id);
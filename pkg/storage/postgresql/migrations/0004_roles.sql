@@ -0,0 +1,4 @@
+-- Роли пользователей для разграничения доступа
+ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'member'
+	CONSTRAINT users_role_check CHECK (role IN ('admin', 'member', 'viewer'));
@@ -0,0 +1,50 @@
+-- Проекты: задачи и метки принадлежат проекту, пользователи участвуют в проектах
+CREATE TABLE IF NOT EXISTS projects(
+id SERIAL NOT NULL UNIQUE,
+name TEXT NOT NULL,
+description TEXT NOT NULL DEFAULT '',
+
+PRIMARY KEY(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS projects_name_key ON projects (lower(name));
+
+-- Проект по умолчанию: в него попадают все существующие задачи и метки
+INSERT INTO projects(id, name)
+VALUES (0, 'default')
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS project_members(
+project_id INT NOT NULL,
+user_id INT NOT NULL,
+
+PRIMARY KEY(project_id, user_id),
+FOREIGN KEY(project_id)
+	REFERENCES projects(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(user_id)
+	REFERENCES users(id)
+	ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS project_members_user_id_idx ON project_members (user_id);
+
+ALTER TABLE tasks
+	ADD COLUMN IF NOT EXISTS project_id INT NOT NULL DEFAULT 0 REFERENCES projects(id);
+ALTER TABLE labels
+	ADD COLUMN IF NOT EXISTS project_id INT NOT NULL DEFAULT 0 REFERENCES projects(id);
+
+CREATE INDEX IF NOT EXISTS tasks_project_id_idx ON tasks (project_id);
+CREATE INDEX IF NOT EXISTS labels_project_id_idx ON labels (project_id);
+
+-- Политики построчной защиты. Действуют только после включения RLS (Storage.SetRowLevelSecurity)
+-- Проект задается параметром app.project_id соединения, пустое значение - без ограничений
+DROP POLICY IF EXISTS tasks_project ON tasks;
+CREATE POLICY tasks_project ON tasks
+	USING (COALESCE(current_setting('app.project_id', true), '') = ''
+		OR project_id = NULLIF(current_setting('app.project_id', true), '')::int);
+
+DROP POLICY IF EXISTS labels_project ON labels;
+CREATE POLICY labels_project ON labels
+	USING (COALESCE(current_setting('app.project_id', true), '') = ''
+		OR project_id = NULLIF(current_setting('app.project_id', true), '')::int);
@@ -0,0 +1,24 @@
+-- Время открытия и закрытия задачи: TIMESTAMPTZ вместо секунд Unix
+-- Закрытие 0 (задача не закрыта) становится NULL
+-- Столбцы преобразуются, только если они еще целочисленные: в БД из schema.sql они сразу TIMESTAMPTZ
+DO $$
+BEGIN
+	IF EXISTS (SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'tasks'
+			AND column_name = 'opened' AND data_type IN ('bigint', 'integer')) THEN
+		ALTER TABLE tasks
+			ALTER COLUMN opened DROP DEFAULT,
+			ALTER COLUMN opened TYPE TIMESTAMPTZ USING COALESCE(to_timestamp(opened), now()),
+			ALTER COLUMN opened SET DEFAULT now(),
+			ALTER COLUMN opened SET NOT NULL;
+	END IF;
+
+	IF EXISTS (SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'tasks'
+			AND column_name = 'closed' AND data_type IN ('bigint', 'integer')) THEN
+		ALTER TABLE tasks
+			ALTER COLUMN closed DROP DEFAULT,
+			ALTER COLUMN closed TYPE TIMESTAMPTZ USING CASE WHEN closed = 0 THEN NULL ELSE to_timestamp(closed) END;
+	END IF;
+END
+$$;
@@ -0,0 +1,43 @@
+-- Шаблоны повторяющихся задач: по расписанию schedule планировщик создает задачи проекта
+CREATE TABLE IF NOT EXISTS recurring_tasks(
+id SERIAL NOT NULL UNIQUE,
+project_id INT NOT NULL DEFAULT 0,
+schedule TEXT NOT NULL,
+author_id INT NOT NULL DEFAULT 0,
+assigned_id INT NOT NULL DEFAULT 0,
+title TEXT NOT NULL,
+content TEXT NOT NULL DEFAULT '',
+label_ids INT[] NOT NULL DEFAULT '{}',
+next_run TIMESTAMPTZ NOT NULL,
+last_run TIMESTAMPTZ,
+active BOOLEAN NOT NULL DEFAULT TRUE,
+
+PRIMARY KEY(id),
+FOREIGN KEY(project_id)
+	REFERENCES projects(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(author_id)
+	REFERENCES users(id)
+	ON DELETE SET DEFAULT,
+FOREIGN KEY(assigned_id)
+	REFERENCES users(id)
+	ON DELETE SET DEFAULT
+);
+
+CREATE INDEX IF NOT EXISTS recurring_tasks_next_run_idx ON recurring_tasks (next_run) WHERE active;
+
+-- Выполненные запуски шаблонов: первичный ключ не дает создать задачу дважды за один запуск
+CREATE TABLE IF NOT EXISTS recurring_runs(
+recurring_id INT NOT NULL,
+run_at TIMESTAMPTZ NOT NULL,
+task_id INT,
+created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+PRIMARY KEY(recurring_id, run_at),
+FOREIGN KEY(recurring_id)
+	REFERENCES recurring_tasks(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(task_id)
+	REFERENCES tasks(id)
+	ON DELETE SET NULL
+);
@@ -0,0 +1,20 @@
+-- Шаблоны задач проекта: заголовок с переменными, заготовка описания, исполнитель и метки по умолчанию
+CREATE TABLE IF NOT EXISTS task_templates(
+id SERIAL NOT NULL UNIQUE,
+project_id INT NOT NULL DEFAULT 0,
+name TEXT NOT NULL,
+title_pattern TEXT NOT NULL,
+content TEXT NOT NULL DEFAULT '',
+assigned_id INT NOT NULL DEFAULT 0,
+label_ids INT[] NOT NULL DEFAULT '{}',
+
+PRIMARY KEY(id),
+FOREIGN KEY(project_id)
+	REFERENCES projects(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(assigned_id)
+	REFERENCES users(id)
+	ON DELETE SET DEFAULT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS task_templates_name_key ON task_templates (project_id, lower(name));
@@ -0,0 +1,20 @@
+-- Сохраненные представления: именованные фильтры задач проекта (JSON), личные или общие
+CREATE TABLE IF NOT EXISTS saved_views(
+id SERIAL NOT NULL UNIQUE,
+project_id INT NOT NULL DEFAULT 0,
+owner_id INT NOT NULL,
+name TEXT NOT NULL,
+filter JSONB NOT NULL DEFAULT '{}',
+shared BOOLEAN NOT NULL DEFAULT FALSE,
+
+PRIMARY KEY(id),
+FOREIGN KEY(project_id)
+	REFERENCES projects(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(owner_id)
+	REFERENCES users(id)
+	ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS saved_views_name_key ON saved_views (project_id, owner_id, lower(name));
+CREATE INDEX IF NOT EXISTS saved_views_shared_idx ON saved_views (project_id) WHERE shared;
@@ -0,0 +1,54 @@
+-- Доски задач проекта: колонки соответствуют состоянию задачи (status) или метке (label)
+CREATE TABLE IF NOT EXISTS boards(
+id SERIAL NOT NULL UNIQUE,
+project_id INT NOT NULL DEFAULT 0,
+name TEXT NOT NULL,
+kind TEXT NOT NULL CHECK (kind IN ('status', 'label')),
+
+PRIMARY KEY(id),
+FOREIGN KEY(project_id)
+	REFERENCES projects(id)
+	ON DELETE CASCADE
+);
+
+-- Колонки доски в порядке position, у колонки задано либо состояние, либо метка
+CREATE TABLE IF NOT EXISTS board_columns(
+id SERIAL NOT NULL UNIQUE,
+board_id INT NOT NULL,
+name TEXT NOT NULL,
+position INT NOT NULL,
+status TEXT CHECK (status IN ('open', 'closed')),
+label_id INT,
+
+PRIMARY KEY(id),
+CHECK ((status IS NULL) <> (label_id IS NULL)),
+FOREIGN KEY(board_id)
+	REFERENCES boards(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(label_id)
+	REFERENCES labels(id)
+	ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS board_columns_board_idx ON board_columns (board_id, position);
+
+-- Положение задачи на доске: колонка и дробный ранг, задачи колонки упорядочены по рангу
+CREATE TABLE IF NOT EXISTS board_cards(
+board_id INT NOT NULL,
+task_id INT NOT NULL,
+column_id INT NOT NULL,
+rank TEXT COLLATE "C" NOT NULL,
+
+PRIMARY KEY(board_id, task_id),
+FOREIGN KEY(board_id)
+	REFERENCES boards(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(task_id)
+	REFERENCES tasks(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(column_id)
+	REFERENCES board_columns(id)
+	ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS board_cards_column_idx ON board_cards (column_id, rank);
@@ -0,0 +1,24 @@
+-- Оценка трудоемкости задачи в секундах, 0 - не задана
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS estimate_s INT NOT NULL DEFAULT 0 CHECK (estimate_s >= 0);
+
+-- Записи о времени, затраченном пользователями на задачи
+-- При удалении пользователя записи передаются пользователю по умолчанию, чтобы не терять учтенное время
+CREATE TABLE IF NOT EXISTS worklogs(
+id SERIAL NOT NULL UNIQUE,
+task_id INT NOT NULL,
+user_id INT NOT NULL DEFAULT 0,
+started TIMESTAMPTZ NOT NULL,
+duration_s INT NOT NULL CHECK (duration_s > 0),
+comment TEXT NOT NULL DEFAULT '',
+
+PRIMARY KEY(id),
+FOREIGN KEY(task_id)
+	REFERENCES tasks(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(user_id)
+	REFERENCES users(id)
+	ON DELETE SET DEFAULT
+);
+
+CREATE INDEX IF NOT EXISTS worklogs_task_id_idx ON worklogs (task_id);
+CREATE INDEX IF NOT EXISTS worklogs_started_idx ON worklogs (started);
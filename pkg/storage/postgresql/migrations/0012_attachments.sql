@@ -0,0 +1,56 @@
+-- Вложения задач: метаданные файлов, содержимое хранится в хранилище содержимого под ключом blob_key
+-- При удалении пользователя вложения передаются пользователю по умолчанию
+CREATE TABLE IF NOT EXISTS attachments(
+id SERIAL NOT NULL UNIQUE,
+task_id INT NOT NULL,
+uploader_id INT NOT NULL DEFAULT 0,
+name TEXT NOT NULL CHECK (name <> ''),
+size BIGINT NOT NULL CHECK (size >= 0),
+mime_type TEXT NOT NULL,
+sha256 BYTEA NOT NULL CHECK (length(sha256) = 32),
+blob_key TEXT NOT NULL UNIQUE,
+created TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+PRIMARY KEY(id),
+FOREIGN KEY(task_id)
+	REFERENCES tasks(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(uploader_id)
+	REFERENCES users(id)
+	ON DELETE SET DEFAULT
+);
+
+CREATE INDEX IF NOT EXISTS attachments_task_id_idx ON attachments (task_id);
+
+-- Очередь удаления содержимого: ключи удаленных вложений и незавершенных загрузок
+-- Содержимое удаляется из хранилища после purge_after
+CREATE TABLE IF NOT EXISTS blob_purge_queue(
+blob_key TEXT NOT NULL,
+purge_after TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+PRIMARY KEY(blob_key)
+);
+
+CREATE INDEX IF NOT EXISTS blob_purge_queue_purge_after_idx ON blob_purge_queue (purge_after);
+
+-- Удаление вложения, в том числе каскадное вместе с задачей или проектом, ставит его содержимое в очередь
+CREATE OR REPLACE FUNCTION attachments_enqueue_blob() RETURNS trigger AS $$
+BEGIN
+	INSERT INTO blob_purge_queue(blob_key) VALUES (OLD.blob_key)
+	ON CONFLICT (blob_key) DO UPDATE SET purge_after = now();
+	RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS attachments_enqueue_blob ON attachments;
+CREATE TRIGGER attachments_enqueue_blob AFTER DELETE ON attachments
+FOR EACH ROW EXECUTE FUNCTION attachments_enqueue_blob();
+
+-- Содержимое вложений для хранилища содержимого в БД: частями по 256 КиБ
+CREATE TABLE IF NOT EXISTS attachment_blobs(
+blob_key TEXT NOT NULL,
+seq INT NOT NULL CHECK (seq >= 0),
+data BYTEA NOT NULL,
+
+PRIMARY KEY(blob_key, seq)
+);
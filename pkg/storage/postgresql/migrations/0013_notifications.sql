@@ -0,0 +1,55 @@
+-- Подписки пользователей на изменения задач
+CREATE TABLE IF NOT EXISTS task_watchers(
+task_id INT NOT NULL,
+user_id INT NOT NULL,
+
+PRIMARY KEY(task_id, user_id),
+FOREIGN KEY(task_id)
+	REFERENCES tasks(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(user_id)
+	REFERENCES users(id)
+	ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS task_watchers_user_id_idx ON task_watchers (user_id);
+
+-- Авторы и исполнители существующих задач подписываются на них, как и при создании новых
+INSERT INTO task_watchers(task_id, user_id)
+SELECT id, author_id FROM tasks WHERE author_id <> 0
+UNION
+SELECT id, assigned_id FROM tasks WHERE assigned_id <> 0
+ON CONFLICT DO NOTHING;
+
+-- Входящие уведомления пользователей: назначения, упоминания и изменения задач, на которые они подписаны
+-- Заголовок задачи сохраняется на момент уведомления
+CREATE TABLE IF NOT EXISTS notifications(
+id SERIAL NOT NULL UNIQUE,
+user_id INT NOT NULL,
+project_id INT NOT NULL,
+task_id INT NOT NULL,
+actor_id INT NOT NULL DEFAULT 0,
+kind TEXT NOT NULL CHECK (kind IN ('assigned', 'mentioned', 'task_changed')),
+task_title TEXT NOT NULL,
+text TEXT NOT NULL,
+created TIMESTAMPTZ NOT NULL DEFAULT now(),
+read_at TIMESTAMPTZ,
+
+PRIMARY KEY(id),
+FOREIGN KEY(user_id)
+	REFERENCES users(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(project_id)
+	REFERENCES projects(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(task_id)
+	REFERENCES tasks(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(actor_id)
+	REFERENCES users(id)
+	ON DELETE SET DEFAULT
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_idx ON notifications (user_id, id);
+CREATE INDEX IF NOT EXISTS notifications_unread_idx ON notifications (user_id, id) WHERE read_at IS NULL;
+CREATE INDEX IF NOT EXISTS notifications_task_id_idx ON notifications (task_id);
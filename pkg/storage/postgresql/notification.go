@@ -0,0 +1,325 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/storage"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// Количество уведомлений в выборке по умолчанию и наибольшее
+	DefaultNotificationLimit = 50
+	MaxNotificationLimit     = 500
+)
+
+// Столбцы уведомления в порядке scanNotification
+const notificationColumns = `id, user_id, project_id, task_id, actor_id, kind, task_title, text, created, read_at`
+
+// mentionPattern находит упоминания @Имя или @Имя Фамилия: слова из букв, дефиса и апострофа
+// Перед @ не должно быть буквы или цифры, чтобы адреса вида user@example.com не считались упоминаниями
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@])@(\p{L}[\p{L}\p{M}'-]*)(?:[ \t]+(\p{L}[\p{L}\p{M}'-]*))?`)
+
+// mention - упоминание: одно слово и, если за ним следует второе, два слова через пробел
+type mention struct {
+	word, words string
+}
+
+// WatchTask подписывает пользователя на изменения задачи проекта, повторная подписка ничего не меняет
+// Пользователь должен состоять в проекте, если задача не найдена - возвращает ошибку
+func (s *Storage) WatchTask(taskID, userID int) error {
+	return s.withTxRetry(s.retry.MaxAttempts, func() error {
+		tx, err := s.db.Begin(s.ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(s.ctx)
+
+		r, err := tx.Exec(s.ctx, `SELECT 1 FROM tasks WHERE id = $1 AND project_id = $2 FOR SHARE;`, taskID, s.project)
+		if err != nil {
+			return err
+		}
+		if r.RowsAffected() == 0 {
+			return myerrors.NotFound("Задача с ID %d не найдена", taskID)
+		}
+		if err := s.checkMembers(tx, userID); err != nil {
+			return err
+		}
+		_, err = tx.Exec(s.ctx, `INSERT INTO task_watchers(task_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;`,
+			taskID, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(s.ctx); err != nil {
+			return fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+		}
+		return nil
+	})
+}
+
+// UnwatchTask отменяет подписку пользователя на изменения задачи проекта
+// Если подписка не найдена, то возвращает ошибку
+func (s *Storage) UnwatchTask(taskID, userID int) error {
+	r, err := s.db.Exec(s.ctx, `DELETE FROM task_watchers USING tasks
+		WHERE task_watchers.task_id = $1 AND task_watchers.user_id = $2
+			AND tasks.id = task_watchers.task_id AND tasks.project_id = $3;`, taskID, userID, s.project)
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Пользователь с ID %d не подписан на задачу с ID %d", userID, taskID)
+	}
+	return nil
+}
+
+// SelectTaskWatchers возвращает пользователей, подписанных на изменения задачи проекта
+func (s *Storage) SelectTaskWatchers(taskID int) ([]model.User, error) {
+	return retryValue(s, func() ([]model.User, error) {
+		return collect(s, func(rows pgx.Rows) (model.User, error) {
+			return scanUser(rows)
+		}, "SELECT "+userColumns+` FROM users
+			WHERE id IN (SELECT task_watchers.user_id FROM task_watchers
+				JOIN tasks ON tasks.id = task_watchers.task_id
+				WHERE task_watchers.task_id = $1 AND tasks.project_id = $2)
+			ORDER BY id ASC;`, taskID, s.project)
+	})
+}
+
+// SelectNotifications возвращает уведомления пользователя q.UserID по всем проектам, начиная с новых
+// Количество ограничивается q.Limit: 0 - DefaultNotificationLimit, не больше MaxNotificationLimit
+func (s *Storage) SelectNotifications(q model.NotificationQuery) ([]model.Notification, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultNotificationLimit
+	}
+	q.Limit = min(q.Limit, MaxNotificationLimit)
+	return retryValue(s, func() ([]model.Notification, error) {
+		return collect(s, func(rows pgx.Rows) (model.Notification, error) {
+			return scanNotification(rows)
+		}, "SELECT "+notificationColumns+` FROM notifications
+			WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+			ORDER BY id DESC LIMIT $3;`, q.UserID, q.UnreadOnly, q.Limit)
+	})
+}
+
+// MarkNotificationRead отмечает уведомление пользователя прочитанным, повторная отметка время не меняет
+// Если уведомление пользователя не найдено, то возвращает ошибку
+func (s *Storage) MarkNotificationRead(userID, id int) error {
+	r, err := s.db.Exec(s.ctx, `UPDATE notifications SET read_at = COALESCE(read_at, now())
+		WHERE id = $1 AND user_id = $2;`, id, userID)
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Уведомление с ID %d не найдено", id)
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead отмечает все непрочитанные уведомления пользователя прочитанными
+// и возвращает их количество
+func (s *Storage) MarkAllNotificationsRead(userID int) (int, error) {
+	r, err := s.db.Exec(s.ctx, `UPDATE notifications SET read_at = now() WHERE user_id = $1 AND read_at IS NULL;`,
+		userID)
+	if err != nil {
+		return 0, err
+	}
+	return int(r.RowsAffected()), nil
+}
+
+// notifications собирает уведомления об одном изменении задачи и сохраняет их в транзакции изменения
+// Каждый пользователь получает не больше одного уведомления - по первой причине, автор изменения
+// и пользователь по умолчанию - ни одного
+type notifications struct {
+	task  model.Task
+	actor int
+	users []int
+	kinds []string
+	texts []string
+}
+
+// newNotifications начинает уведомления об изменении задачи task
+// Автор изменения берется из контекста хранилища (storage.WithActor), без него - actor
+func (s *Storage) newNotifications(task model.Task, actor int) *notifications {
+	if id, ok := storage.ActorFromContext(s.ctx); ok {
+		actor = id
+	}
+	return &notifications{task: task, actor: actor}
+}
+
+// add добавляет уведомления пользователям userIDs, которые еще не уведомлены об этом изменении
+func (n *notifications) add(kind model.NotificationKind, text string, userIDs ...int) {
+	for _, id := range userIDs {
+		if id == 0 || id == n.actor || slices.Contains(n.users, id) {
+			continue
+		}
+		n.users = append(n.users, id)
+		n.kinds = append(n.kinds, string(kind))
+		n.texts = append(n.texts, text)
+	}
+}
+
+// addMentions уведомляет участников проекта, упомянутых в text, но не в previous (прежней версии текста)
+func (s *Storage) addMentions(q querier, n *notifications, text, previous, about string) error {
+	ids, err := s.resolveMentions(q, parseMentions(text))
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске упомянутых пользователей: %w", err)
+	}
+	old, err := s.resolveMentions(q, parseMentions(previous))
+	if err != nil {
+		return fmt.Errorf("Ошибка при поиске упомянутых пользователей: %w", err)
+	}
+	ids = slices.DeleteFunc(ids, func(id int) bool { return slices.Contains(old, id) })
+	n.add(model.NotifyMentioned, about, ids...)
+	return nil
+}
+
+// addWatchers уведомляет подписчиков задачи об ее изменении text
+func (s *Storage) addWatchers(q querier, n *notifications, text string) error {
+	rows, err := q.Query(s.ctx, `SELECT user_id FROM task_watchers WHERE task_id = $1 ORDER BY user_id;`, n.task.ID)
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении подписчиков задачи %d: %w", n.task.ID, err)
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	n.add(model.NotifyTaskChanged, text, ids...)
+	return nil
+}
+
+// send сохраняет собранные уведомления одним запросом
+func (s *Storage) send(q querier, n *notifications) error {
+	if len(n.users) == 0 {
+		return nil
+	}
+	_, err := q.Exec(s.ctx, `INSERT INTO notifications(user_id, project_id, task_id, actor_id, kind, task_title, text)
+		SELECT u.user_id, $1, $2, $3, u.kind, $4, u.text
+		FROM unnest($5::int[], $6::text[], $7::text[]) AS u(user_id, kind, text);`,
+		s.project, n.task.ID, n.actor, n.task.Title, n.users, n.kinds, n.texts)
+	if err != nil {
+		return fmt.Errorf("Ошибка при сохранении уведомлений по задаче %d: %w", n.task.ID, err)
+	}
+	return nil
+}
+
+// watch подписывает пользователей на задачу, пользователь по умолчанию не подписывается
+func (s *Storage) watch(q querier, taskID int, userIDs ...int) error {
+	_, err := q.Exec(s.ctx, `INSERT INTO task_watchers(task_id, user_id)
+		SELECT $1, user_id FROM unnest($2::int[]) AS user_id WHERE user_id <> 0
+		ON CONFLICT DO NOTHING;`, taskID, userIDs)
+	if err != nil {
+		return fmt.Errorf("Ошибка при подписке на задачу %d: %w", taskID, err)
+	}
+	return nil
+}
+
+// resolveMentions возвращает ID участников проекта, упомянутых в mentions
+// Упоминание из двух слов сначала сравнивается с именем целиком, затем первое слово - с именем,
+// первым словом имени, отображаемым именем и логином без учета регистра
+// Упоминание, подходящее нескольким пользователям, не учитывается
+func (s *Storage) resolveMentions(q querier, mentions []mention) ([]int, error) {
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+	var keys []string
+	for _, m := range mentions {
+		keys = append(keys, strings.ToLower(m.word))
+		if m.words != "" {
+			keys = append(keys, strings.ToLower(m.words))
+		}
+	}
+
+	type candidate struct {
+		id                   int
+		name, display, login string
+	}
+	rows, err := q.Query(s.ctx, `SELECT id, name, display_name, COALESCE(login, '') FROM users
+		WHERE id <> 0 AND (lower(name) = ANY($1) OR split_part(lower(name), ' ', 1) = ANY($1)
+				OR lower(display_name) = ANY($1) OR lower(login) = ANY($1))
+			AND ($2 = 0 OR id IN (SELECT user_id FROM project_members WHERE project_id = $2));`, keys, s.project)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var candidates []candidate
+	for rows.Next() {
+		var c candidate
+		if err := rows.Scan(&c.id, &c.name, &c.display, &c.login); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// match возвращает единственного пользователя, подходящего под key, или 0
+	match := func(key string, fields func(candidate) []string) int {
+		found := 0
+		for _, c := range candidates {
+			if slices.ContainsFunc(fields(c), func(f string) bool { return f != "" && strings.EqualFold(f, key) }) {
+				if found != 0 && found != c.id {
+					return 0
+				}
+				found = c.id
+			}
+		}
+		return found
+	}
+	var ids []int
+	for _, m := range mentions {
+		id := 0
+		if m.words != "" {
+			id = match(m.words, func(c candidate) []string { return []string{c.name, c.display} })
+		}
+		if id == 0 {
+			id = match(m.word, func(c candidate) []string {
+				first, _, _ := strings.Cut(c.name, " ")
+				return []string{c.name, first, c.display, c.login}
+			})
+		}
+		if id != 0 && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// parseMentions возвращает упоминания @Имя в тексте без повторов
+// Дефисы и апострофы в конце слова, как в "@Иван-", к имени не относятся
+func parseMentions(text string) []mention {
+	var mentions []mention
+	for _, sub := range mentionPattern.FindAllStringSubmatch(norm.NFC.String(text), -1) {
+		m := mention{word: strings.TrimRight(sub[1], "'-")}
+		if second := strings.TrimRight(sub[2], "'-"); second != "" {
+			m.words = m.word + " " + second
+		}
+		if !slices.Contains(mentions, m) {
+			mentions = append(mentions, m)
+		}
+	}
+	return mentions
+}
+
+// scanNotification считывает строку со столбцами notificationColumns в уведомление
+func scanNotification(row pgx.Row) (model.Notification, error) {
+	var n model.Notification
+	err := row.Scan(&n.ID, &n.UserID, &n.ProjectID, &n.TaskID, &n.ActorID, &n.Kind, &n.TaskTitle, &n.Text,
+		&n.Created, &n.Read)
+	return n, err
+}
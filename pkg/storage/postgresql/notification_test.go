@@ -0,0 +1,164 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []mention
+	}{
+		{name: "без упоминаний", text: "Обычный текст"},
+		{name: "одно слово", text: "@Иван", want: []mention{{word: "Иван"}}},
+		{
+			name: "имя и фамилия",
+			text: "Проверь, @Иван Петров",
+			want: []mention{{word: "Иван", words: "Иван Петров"}},
+		},
+		{
+			name: "следующее слово не обязательно фамилия",
+			text: "@Иван посмотри",
+			want: []mention{{word: "Иван", words: "Иван посмотри"}},
+		},
+		{name: "второе слово на новой строке", text: "@Иван\nПетров", want: []mention{{word: "Иван"}}},
+		{
+			name: "несколько упоминаний",
+			text: "@Иван @Мария Сидорова и @ivan",
+			want: []mention{{word: "Иван"}, {word: "Мария", words: "Мария Сидорова"}, {word: "ivan"}},
+		},
+		{name: "адрес e-mail", text: "Пишите на ivan@example.com или иван@почта.рф"},
+		{name: "двойной знак", text: "@@Иван"},
+		{name: "слитно с другим упоминанием", text: "@Иван@Петр", want: []mention{{word: "Иван"}}},
+		{
+			name: "знаки препинания",
+			text: "(@Иван), @Мария. @Петр! «@Анна»",
+			want: []mention{{word: "Иван"}, {word: "Мария"}, {word: "Петр"}, {word: "Анна"}},
+		},
+		{
+			name: "дефис и апостроф",
+			text: "@Анна-Мария @О'Нил @Иван- ",
+			want: []mention{{word: "Анна-Мария"}, {word: "О'Нил"}, {word: "Иван"}},
+		},
+		{name: "цифры не имя", text: "@123 @_admin"},
+		{name: "повторы", text: "@Иван, @Иван. @Иван Петров", want: []mention{{word: "Иван"}, {word: "Иван", words: "Иван Петров"}}},
+		// "й" из "и" и комбинируемого знака приводится к одному символу
+		{name: "разложенная буква", text: "@Андре\u0438\u0306", want: []mention{{word: "Андрей"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseMentions(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("parseMentions(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// mentionUser - пользователь для поиска упоминаний
+type mentionUser struct {
+	id                   int
+	name, display, login string
+}
+
+// usersRows - результат запроса пользователей в resolveMentions
+type usersRows struct {
+	pgx.Rows
+	users []mentionUser
+	next  int
+}
+
+func (r *usersRows) Next() bool {
+	r.next++
+	return r.next <= len(r.users)
+}
+
+func (r *usersRows) Scan(dest ...any) error {
+	u := r.users[r.next-1]
+	*dest[0].(*int), *dest[1].(*string), *dest[2].(*string), *dest[3].(*string) = u.id, u.name, u.display, u.login
+	return nil
+}
+
+func (r *usersRows) Err() error { return nil }
+func (r *usersRows) Close()     {}
+
+// usersQuerier отбирает пользователей по ключам так же, как запрос resolveMentions
+type usersQuerier struct {
+	querier
+	users []mentionUser
+}
+
+func (q usersQuerier) Query(_ context.Context, _ string, args ...any) (pgx.Rows, error) {
+	keys := args[0].([]string)
+	var found []mentionUser
+	for _, u := range q.users {
+		first, _, _ := strings.Cut(strings.ToLower(u.name), " ")
+		for _, f := range []string{strings.ToLower(u.name), first, strings.ToLower(u.display), strings.ToLower(u.login)} {
+			if slices.Contains(keys, f) {
+				found = append(found, u)
+				break
+			}
+		}
+	}
+	return &usersRows{users: found}, nil
+}
+
+func TestAddMentions(t *testing.T) {
+	q := usersQuerier{users: []mentionUser{
+		{id: 1, name: "Иван Петров", login: "ivan"},
+		{id: 2, name: "Иван Сидоров", display: "Ваня"},
+		{id: 3, name: "Мария Петрова"},
+		{id: 4, name: "Анна-Мария Ким"},
+	}}
+	tests := []struct {
+		name           string
+		text, previous string
+		actor          int
+		want           []int
+	}{
+		{name: "имя и фамилия", text: "@Иван Петров, посмотри", want: []int{1}},
+		{name: "неоднозначное имя", text: "@Иван, посмотри"},
+		{name: "имя без учета регистра", text: "@мария", want: []int{3}},
+		{name: "логин и отображаемое имя", text: "@ivan и @Ваня", want: []int{1, 2}},
+		{name: "имя с дефисом", text: "@Анна-Мария", want: []int{4}},
+		{name: "e-mail", text: "ivan@example.com"},
+		{name: "повтор упоминания", text: "@Мария @мария @Мария Петрова", want: []int{3}},
+		{name: "упомянут в прежнем тексте", text: "@Мария и @ivan", previous: "@Мария", want: []int{1}},
+		{name: "прежнее упоминание другими словами", text: "@Мария Петрова", previous: "@мария"},
+		{name: "автор изменения", text: "@Мария и @ivan", actor: 3, want: []int{1}},
+		{name: "неизвестный пользователь", text: "@Петр"},
+	}
+	s := &Storage{ctx: context.Background()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &notifications{task: model.Task{ID: 1}, actor: tt.actor}
+			if err := s.addMentions(q, n, tt.text, tt.previous, "Упоминание"); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(n.users, tt.want) {
+				t.Errorf("уведомлены %v, want %v", n.users, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotificationsAdd(t *testing.T) {
+	s := &Storage{ctx: storage.WithActor(context.Background(), 2)}
+	n := s.newNotifications(model.Task{ID: 1}, 5)
+	n.add(model.NotifyAssigned, "Назначена", 0, 1, 2)
+	n.add(model.NotifyTaskChanged, "Изменена", 1, 3)
+
+	if want := []int{1, 3}; !slices.Equal(n.users, want) {
+		t.Errorf("уведомлены %v, want %v: без автора изменения и пользователя по умолчанию", n.users, want)
+	}
+	if want := []string{string(model.NotifyAssigned), string(model.NotifyTaskChanged)}; !slices.Equal(n.kinds, want) {
+		t.Errorf("причины %v, want %v: каждому пользователю - первая причина", n.kinds, want)
+	}
+}
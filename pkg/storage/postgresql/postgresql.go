@@ -1,23 +1,160 @@
 package postgresql
 
 import (
+	"DB_Apps/pkg/names"
+	"DB_Apps/pkg/storage"
 	"context"
+	"log/slog"
+	"time"
 
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// querier - общие методы пула соединений и транзакции
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
 type Storage struct {
-	db *pgxpool.Pool
+	db   querier
+	pool *pgxpool.Pool
+	// Текущая транзакция, если хранилище получено через WithTx
+	tx pgx.Tx
+	// Контекст запросов, задается через WithContext
+	ctx context.Context
+	// Проект, которым ограничены задачи и метки, задается через WithProject
+	project int
+	// Признак удаления вложений во внешней транзакции WithTx: их содержимое удаляется после ее фиксации
+	purgeAfterTx *bool
+
+	// Хранилище содержимого вложений и наибольший размер вложения
+	blobs             storage.BlobStore
+	maxAttachmentSize int64
+
+	retry  RetryPolicy
+	stats  *retryCounters
+	logger *slog.Logger
+	names  names.Validator
+	// Часовой пояс дат, которые хранилище подставляет в текст
+	loc *time.Location
+}
+
+// Options - дополнительные параметры подключения
+type Options struct {
+	// Обработчики, которые pgx вызывает после выполнения каждого запроса
+	// (время выполнения, число строк, ошибка). Используются для трассировки и журналирования
+	QueryHooks []pgx.Logger
+	// Журнал хранилища. Если задан, то каждый запрос записывается через QueryLogger,
+	// а повторы операций - с уровнем Warn. По умолчанию журнал отключен
+	Logger *slog.Logger
+	// Порог медленного запроса для QueryLogger, 0 - DefaultSlowQueryThreshold
+	SlowQueryThreshold time.Duration
+	// Правило проверки и нормализации имен пользователей, по умолчанию names.CyrillicStrict
+	NameValidator names.Validator
+	// Режим построчной защиты: перед выдачей соединения из пула ему задается параметр app.project_id
+	// по проекту хранилища (WithProject), который используют политики RLS таблиц tasks и labels
+	// Сами политики включаются методом SetRowLevelSecurity
+	RowLevelSecurity bool
+	// Хранилище содержимого вложений, по умолчанию - таблица attachment_blobs этой БД
+	Blobs storage.BlobStore
+	// Наибольший размер вложения в байтах, 0 - DefaultMaxAttachmentSize
+	MaxAttachmentSize int64
+	// Часовой пояс переменной {{date}} шаблонов задач, по умолчанию - местный
+	Location *time.Location
 }
 
 func New(connString string) (*Storage, error) {
-	db, err := pgxpool.Connect(context.Background(), connString)
+	return NewWithOptions(connString, Options{})
+}
+
+// NewWithOptions создает хранилище с дополнительными параметрами подключения
+func NewWithOptions(connString string, opts Options) (*Storage, error) {
+	cfg, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, err
+	}
+	hooks := append([]pgx.Logger(nil), opts.QueryHooks...)
+	logger := opts.Logger
+	if logger != nil {
+		hooks = append(hooks, NewQueryLogger(logger, opts.SlowQueryThreshold))
+	} else {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	if len(hooks) > 0 {
+		cfg.ConnConfig.Logger = queryHooks(hooks)
+		cfg.ConnConfig.LogLevel = pgx.LogLevelInfo
+	}
+
+	if opts.RowLevelSecurity {
+		cfg.BeforeAcquire = setProject
+	}
+
+	validator := opts.NameValidator
+	if validator == nil {
+		validator = names.CyrillicStrict()
+	}
+
+	db, err := pgxpool.ConnectConfig(context.Background(), cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &Storage{db: db}, nil
+	blobs := opts.Blobs
+	if blobs == nil {
+		blobs = tableBlobs{db: db}
+	}
+	maxAttachmentSize := opts.MaxAttachmentSize
+	if maxAttachmentSize <= 0 {
+		maxAttachmentSize = DefaultMaxAttachmentSize
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	return &Storage{
+		db:     db,
+		pool:   db,
+		ctx:    projectContext(context.Background(), DefaultProjectID),
+		retry:  DefaultRetryPolicy,
+		stats:  &retryCounters{},
+		logger: logger,
+		names:  validator,
+		loc:    loc,
+
+		blobs:             blobs,
+		maxAttachmentSize: maxAttachmentSize,
+	}, nil
 }
 
+// WithContext возвращает хранилище, запросы которого выполняются с контекстом ctx
+// Отмена ctx прерывает выполняемый запрос и ожидание перед повтором
+func (s *Storage) WithContext(ctx context.Context) storage.Interface {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c := *s
+	c.ctx = projectContext(ctx, s.project)
+	return &c
+}
+
+// Close закрывает пул соединений
+// Для хранилища внутри WithTx ничего не делает: транзакцией управляет WithTx
 func (s *Storage) Close() {
-	s.db.Close()
+	if s.tx != nil {
+		return
+	}
+	s.pool.Close()
+}
+
+// queryHooks передает событие pgx всем обработчикам по очереди
+type queryHooks []pgx.Logger
+
+func (h queryHooks) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	for _, l := range h {
+		l.Log(ctx, level, msg, data)
+	}
 }
@@ -0,0 +1,296 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/storage"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// DefaultProjectID - проект по умолчанию, с ним работает хранилище без WithProject
+// Все пользователи считаются его участниками
+const DefaultProjectID = 0
+
+// Ошибки проектов
+var (
+	ProjectNameErr      = errors.New("Пустая строка не может быть названием проекта")
+	DuplicateProjectErr = errors.New("Проект с таким названием уже существует")
+	DefaultProjectErr   = errors.New("Проект по умолчанию нельзя удалить")
+	NotProjectMemberErr = errors.New("Пользователь не состоит в проекте")
+)
+
+// Значение контекста, при котором соединение получает пустой app.project_id (без ограничения RLS)
+const allProjects = -1
+
+type projectKey struct{}
+
+// projectContext возвращает контекст, по которому BeforeAcquire задает app.project_id соединения
+func projectContext(ctx context.Context, projectID int) context.Context {
+	return context.WithValue(ctx, projectKey{}, projectID)
+}
+
+// setProject задает соединению параметр app.project_id по проекту из контекста запроса
+// Используется пулом перед выдачей соединения, если включен режим Options.RowLevelSecurity
+func setProject(ctx context.Context, conn *pgx.Conn) bool {
+	value := ""
+	if id, ok := ctx.Value(projectKey{}).(int); ok && id != allProjects {
+		value = strconv.Itoa(id)
+	}
+	_, err := conn.Exec(ctx, `SELECT set_config('app.project_id', $1, false);`, value)
+	return err == nil
+}
+
+// WithProject возвращает хранилище, задачи и метки которого ограничены проектом projectID
+// Все запросы к задачам и меткам фильтруются по проекту, новые задачи и метки создаются в нем
+func (s *Storage) WithProject(projectID int) storage.Interface {
+	c := *s
+	c.project = projectID
+	c.ctx = projectContext(s.ctx, projectID)
+	return &c
+}
+
+// ProjectID возвращает проект, которым ограничено хранилище
+func (s *Storage) ProjectID() int {
+	return s.project
+}
+
+// SetRowLevelSecurity включает или выключает построчную защиту (RLS) таблиц tasks и labels
+// При включенной защите PostgreSQL сам скрывает строки других проектов, даже если запрос их не фильтрует,
+// поэтому вместе с ней хранилище должно быть создано с Options.RowLevelSecurity
+func (s *Storage) SetRowLevelSecurity(enabled bool) error {
+	action := "DISABLE"
+	force := "NO FORCE"
+	if enabled {
+		action, force = "ENABLE", "FORCE"
+	}
+	for _, table := range []string{"tasks", "labels"} {
+		_, err := s.db.Exec(s.ctx, fmt.Sprintf(`ALTER TABLE %s %s ROW LEVEL SECURITY, %s ROW LEVEL SECURITY;`,
+			table, action, force))
+		if err != nil {
+			return fmt.Errorf("Ошибка при настройке построчной защиты таблицы %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// NewProject создает проект и возвращает его ID
+// Если название пустое, то возвращает ProjectNameErr, если уже занято - DuplicateProjectErr
+func (s *Storage) NewProject(p model.Project) (int, error) {
+	p.Name = strings.Join(strings.Fields(p.Name), " ")
+	if p.Name == "" {
+		return 0, ProjectNameErr
+	}
+	var id int
+	err := s.db.QueryRow(s.ctx, `INSERT INTO projects(name, description) VALUES ($1, $2) RETURNING id;`,
+		p.Name, strings.TrimSpace(p.Description)).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == UniqueViolation {
+			return 0, fmt.Errorf("%w: %s", DuplicateProjectErr, p.Name)
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// DeleteProject удаляет проект вместе с его задачами, вложениями задач, метками и участниками
+// Проект по умолчанию удалить нельзя (DefaultProjectErr), если проект не найден - возвращает ошибку
+func (s *Storage) DeleteProject(id int) error {
+	if id == DefaultProjectID {
+		return DefaultProjectErr
+	}
+	err := s.withTxRetry(s.retry.MaxAttempts, func() error {
+		return s.deleteProject(id)
+	})
+	if err == nil {
+		s.blobsReleased()
+	}
+	return err
+}
+
+// deleteProject выполняет транзакцию DeleteProject без повторов
+func (s *Storage) deleteProject(id int) error {
+	tx, err := s.db.Begin(s.ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(s.ctx)
+
+	_, err = tx.Exec(s.ctx, `DELETE FROM tasks_labels
+		WHERE task_id IN (SELECT id FROM tasks WHERE project_id = $1);`, id)
+	if err != nil {
+		return fmt.Errorf("Ошибка при удалении меток задач проекта %d: %w", id, err)
+	}
+	if _, err := tx.Exec(s.ctx, `DELETE FROM tasks WHERE project_id = $1;`, id); err != nil {
+		return fmt.Errorf("Ошибка при удалении задач проекта %d: %w", id, err)
+	}
+	if _, err := tx.Exec(s.ctx, `DELETE FROM labels WHERE project_id = $1;`, id); err != nil {
+		return fmt.Errorf("Ошибка при удалении меток проекта %d: %w", id, err)
+	}
+	r, err := tx.Exec(s.ctx, `DELETE FROM projects WHERE id = $1;`, id)
+	if err != nil {
+		return fmt.Errorf("Ошибка при удалении проекта %d: %w", id, err)
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Проект с ID %d не найден", id)
+	}
+
+	if err := tx.Commit(s.ctx); err != nil {
+		return fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+	}
+	return nil
+}
+
+// SelectProjects возвращает все проекты, отсортированные по ID
+func (s *Storage) SelectProjects() ([]model.Project, error) {
+	return retryValue(s, func() ([]model.Project, error) {
+		return s.selectProjects(`SELECT id, name, description FROM projects ORDER BY id ASC;`)
+	})
+}
+
+// SelectUserProjects возвращает проекты, в которых состоит пользователь, включая проект по умолчанию
+func (s *Storage) SelectUserProjects(userID int) ([]model.Project, error) {
+	return retryValue(s, func() ([]model.Project, error) {
+		return s.selectProjects(`SELECT id, name, description FROM projects
+			WHERE id = $1 OR id IN (SELECT project_id FROM project_members WHERE user_id = $2)
+			ORDER BY id ASC;`, DefaultProjectID, userID)
+	})
+}
+
+// selectProjects выполняет запрос списка проектов без повторов
+func (s *Storage) selectProjects(sql string, args ...any) ([]model.Project, error) {
+	rows, err := s.db.Query(s.ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []model.Project
+	for rows.Next() {
+		var p model.Project
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description); err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// SelectProjectByID возвращает проект по ID
+// Если проект не найден, то возвращает ошибку
+func (s *Storage) SelectProjectByID(id int) (model.Project, error) {
+	return retryValue(s, func() (model.Project, error) {
+		var p model.Project
+		err := s.db.QueryRow(s.ctx, `SELECT id, name, description FROM projects WHERE id = $1;`, id).
+			Scan(&p.ID, &p.Name, &p.Description)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return p, myerrors.NotFound("Проект с ID %d не найден", id)
+		}
+		return p, err
+	})
+}
+
+// AddProjectMember добавляет пользователя в проект
+// Повторное добавление не считается ошибкой, если проект или пользователь не найдены - возвращает ошибку
+func (s *Storage) AddProjectMember(projectID, userID int) error {
+	_, err := s.db.Exec(s.ctx, `INSERT INTO project_members(project_id, user_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING;`, projectID, userID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == ForeignKeyViolation {
+			return myerrors.NotFound("Проект с ID %d или пользователь с ID %d не найден", projectID, userID)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteProjectMember исключает пользователя из проекта
+// Если пользователь не состоит в проекте, то возвращает ошибку
+func (s *Storage) DeleteProjectMember(projectID, userID int) error {
+	r, err := s.db.Exec(s.ctx, `DELETE FROM project_members WHERE project_id = $1 AND user_id = $2;`,
+		projectID, userID)
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Пользователь с ID %d не состоит в проекте с ID %d", userID, projectID)
+	}
+	return nil
+}
+
+// SelectProjectMembers возвращает участников проекта, отсортированных по ID
+// Для проекта по умолчанию возвращает всех пользователей
+func (s *Storage) SelectProjectMembers(projectID int) ([]model.User, error) {
+	return retryValue(s, func() ([]model.User, error) {
+		if projectID == DefaultProjectID {
+			return s.selectUsers()
+		}
+		rows, err := s.db.Query(s.ctx, "SELECT "+userColumns+` FROM users
+			WHERE id IN (SELECT user_id FROM project_members WHERE project_id = $1)
+			ORDER BY id ASC;`, projectID)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		var users []model.User
+		for rows.Next() {
+			user, err := scanUser(rows)
+			if err != nil {
+				return nil, err
+			}
+			users = append(users, user)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return users, nil
+	})
+}
+
+// IsProjectMember сообщает, состоит ли пользователь в проекте
+func (s *Storage) IsProjectMember(projectID, userID int) (bool, error) {
+	return retryValue(s, func() (bool, error) {
+		return s.isProjectMember(s.db, projectID, userID)
+	})
+}
+
+// isProjectMember проверяет участие в проекте через q (пул или транзакцию)
+func (s *Storage) isProjectMember(q querier, projectID, userID int) (bool, error) {
+	if projectID == DefaultProjectID {
+		return true, nil
+	}
+	var ok bool
+	err := q.QueryRow(s.ctx, `SELECT EXISTS(SELECT 1 FROM project_members
+		WHERE project_id = $1 AND user_id = $2);`, projectID, userID).Scan(&ok)
+	return ok, err
+}
+
+// checkMembers проверяет, что пользователи ids состоят в проекте хранилища
+// Пользователь по умолчанию (ID 0) допускается в любом проекте
+func (s *Storage) checkMembers(q querier, ids ...int) error {
+	for _, id := range ids {
+		if id == 0 {
+			continue
+		}
+		ok, err := s.isProjectMember(q, s.project, id)
+		if err != nil {
+			return fmt.Errorf("Ошибка при проверке участника проекта: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: пользователь с ID %d, проект с ID %d", NotProjectMemberErr, id, s.project)
+		}
+	}
+	return nil
+}
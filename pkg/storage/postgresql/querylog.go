@@ -0,0 +1,118 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// Значение, которым заменяются скрытые аргументы запроса
+const redacted = "[скрыто]"
+
+// Порог медленного запроса по умолчанию
+const DefaultSlowQueryThreshold = 200 * time.Millisecond
+
+// SensitiveColumns - столбцы, значения которых не выводятся в журнал
+var SensitiveColumns = []string{"password", "password_hash", "token", "token_hash", "secret", "email"}
+
+// QueryLogger записывает в журнал каждый SQL-запрос: текст, время выполнения, число строк и ошибку
+// Обычные запросы пишутся на уровне Debug, ошибки - на уровне Error
+// Запросы дольше порога пишутся на уровне Warn вместе с аргументами,
+// Если запрос упоминает чувствительный столбец (SensitiveColumns), то строковые и прочие
+// нечисловые аргументы заменяются на "[скрыто]"
+// Подключается к pgx через Options.QueryHooks или Options.Logger
+type QueryLogger struct {
+	logger    *slog.Logger
+	threshold time.Duration
+	sensitive *regexp.Regexp
+}
+
+// NewQueryLogger создает журнал запросов с порогом медленного запроса threshold
+// Если threshold не больше 0, то используется DefaultSlowQueryThreshold
+func NewQueryLogger(logger *slog.Logger, threshold time.Duration) *QueryLogger {
+	if threshold <= 0 {
+		threshold = DefaultSlowQueryThreshold
+	}
+	columns := make([]string, len(SensitiveColumns))
+	for i, c := range SensitiveColumns {
+		columns[i] = regexp.QuoteMeta(c)
+	}
+	sensitive := regexp.MustCompile(`(?i)\b(` + strings.Join(columns, "|") + `)\b`)
+	return &QueryLogger{logger: logger, threshold: threshold, sensitive: sensitive}
+}
+
+func (l *QueryLogger) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	sql, ok := data["sql"].(string)
+	if !ok {
+		return
+	}
+	statement := strings.Join(strings.Fields(sql), " ")
+	d, _ := data["time"].(time.Duration)
+
+	attrs := []slog.Attr{
+		slog.String("statement", statement),
+		slog.Duration("duration", d),
+	}
+	if n, ok := data["rowCount"].(int); ok {
+		attrs = append(attrs, slog.Int("rows", n))
+	}
+	if tag, ok := data["commandTag"].(pgconn.CommandTag); ok {
+		attrs = append(attrs, slog.Int64("rows", tag.RowsAffected()))
+	}
+
+	err, _ := data["err"].(error)
+	slow := d >= l.threshold
+	if slow || err != nil {
+		args, _ := data["args"].([]interface{})
+		attrs = append(attrs, slog.Any("args", l.redact(statement, args)))
+	}
+
+	switch {
+	case err != nil:
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			attrs = append(attrs, slog.String("code", pgErr.Code))
+		}
+		attrs = append(attrs, slog.Any("error", err))
+		l.logger.LogAttrs(ctx, slog.LevelError, "Ошибка SQL-запроса", attrs...)
+	case slow:
+		attrs = append(attrs, slog.Duration("threshold", l.threshold))
+		l.logger.LogAttrs(ctx, slog.LevelWarn, "Медленный SQL-запрос", attrs...)
+	default:
+		l.logger.LogAttrs(ctx, slog.LevelDebug, "SQL-запрос", attrs...)
+	}
+}
+
+// redact возвращает копию args для журнала
+// Если запрос упоминает чувствительный столбец (SensitiveColumns), то скрываются все аргументы,
+// кроме чисел, логических значений, времени и NULL: по тексту запроса нельзя надежно определить,
+// к какому столбцу относится параметр (lower(email) = lower($1), IN, COALESCE и т.п.)
+func (l *QueryLogger) redact(statement string, args []interface{}) []interface{} {
+	out := make([]interface{}, len(args))
+	copy(out, args)
+	if !l.sensitive.MatchString(statement) {
+		return out
+	}
+	for i, arg := range out {
+		if !safeArg(arg) {
+			out[i] = redacted
+		}
+	}
+	return out
+}
+
+// safeArg сообщает, что значение аргумента не может содержать чувствительные данные
+func safeArg(arg interface{}) bool {
+	switch arg.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		float32, float64, time.Time, time.Duration, []int, []int32, []int64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+func TestQueryLoggerRedact(t *testing.T) {
+	l := NewQueryLogger(slog.Default(), 0)
+	tests := []struct {
+		name      string
+		statement string
+		args      []interface{}
+		want      []interface{}
+	}{
+		{
+			name:      "сравнение с функцией",
+			statement: `SELECT id FROM users WHERE lower(email) = lower($1);`,
+			args:      []interface{}{"User@Example.com"},
+			want:      []interface{}{redacted},
+		},
+		{
+			name:      "простое сравнение",
+			statement: `SELECT id FROM users WHERE email = $1 AND id <> $2;`,
+			args:      []interface{}{"user@example.com", 7},
+			want:      []interface{}{redacted, 7},
+		},
+		{
+			name:      "IN и COALESCE",
+			statement: `SELECT id FROM users WHERE COALESCE(email, '') IN ($1, $2) AND name = $3;`,
+			args:      []interface{}{"a@example.com", "b@example.com", "Иван"},
+			want:      []interface{}{redacted, redacted, redacted},
+		},
+		{
+			name:      "вставка",
+			statement: `INSERT INTO auth_tokens(user_id, kind, token_hash) VALUES ($1, $2, $3);`,
+			args:      []interface{}{1, "session", []byte{1, 2, 3}},
+			want:      []interface{}{1, redacted, redacted},
+		},
+		{
+			name:      "регистр столбца",
+			statement: `UPDATE users SET PASSWORD_HASH = $2 WHERE id = $1;`,
+			args:      []interface{}{1, "hash"},
+			want:      []interface{}{1, redacted},
+		},
+		{
+			name:      "без чувствительных столбцов",
+			statement: `SELECT id FROM tasks WHERE title = $1 AND author_id = $2;`,
+			args:      []interface{}{"Задача", 3},
+			want:      []interface{}{"Задача", 3},
+		},
+		{
+			name:      "часть имени таблицы",
+			statement: `SELECT id FROM auth_tokens WHERE kind = $1;`,
+			args:      []interface{}{"api"},
+			want:      []interface{}{"api"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := l.redact(tt.statement, tt.args)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryLoggerRedactKeepsArgs(t *testing.T) {
+	l := NewQueryLogger(slog.Default(), 0)
+	args := []interface{}{"user@example.com"}
+	l.redact(`SELECT id FROM users WHERE email = $1;`, args)
+	if args[0] != "user@example.com" {
+		t.Errorf("redact() изменил исходные аргументы: %v", args)
+	}
+}
+
+func TestQueryLoggerLog(t *testing.T) {
+	const email = "User@Example.com"
+	statement := `SELECT id
+		FROM users
+		WHERE lower(email) = lower($1);`
+
+	tests := []struct {
+		name  string
+		data  map[string]interface{}
+		level string
+		msg   string
+	}{
+		{
+			name:  "медленный запрос",
+			data:  map[string]interface{}{"sql": statement, "args": []interface{}{email}, "time": time.Second},
+			level: "WARN",
+			msg:   "Медленный SQL-запрос",
+		},
+		{
+			name: "ошибка",
+			data: map[string]interface{}{"sql": statement, "args": []interface{}{email}, "time": time.Millisecond,
+				"err": errors.New("сбой")},
+			level: "ERROR",
+			msg:   "Ошибка SQL-запроса",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			NewQueryLogger(logger, 100*time.Millisecond).Log(context.Background(), pgx.LogLevelInfo, "Query", tt.data)
+
+			out := buf.String()
+			if strings.Contains(out, email) {
+				t.Errorf("email попал в журнал: %s", out)
+			}
+			if !strings.Contains(out, "level="+tt.level) || !strings.Contains(out, tt.msg) {
+				t.Errorf("запись %q, want уровень %s и сообщение %q", out, tt.level, tt.msg)
+			}
+			if !strings.Contains(out, redacted) {
+				t.Errorf("в записи нет %q: %s", redacted, out)
+			}
+			if !strings.Contains(out, "SELECT id FROM users WHERE lower(email) = lower($1);") {
+				t.Errorf("текст запроса не нормализован: %s", out)
+			}
+		})
+	}
+}
+
+func TestQueryLoggerLogFastQuery(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	NewQueryLogger(logger, time.Second).Log(context.Background(), pgx.LogLevelInfo, "Query", map[string]interface{}{
+		"sql": `SELECT id FROM users WHERE email = $1;`, "args": []interface{}{"user@example.com"}, "time": time.Millisecond,
+	})
+
+	out := buf.String()
+	if !strings.Contains(out, "level=DEBUG") {
+		t.Errorf("запись %q, want уровень DEBUG", out)
+	}
+	if strings.Contains(out, "args=") {
+		t.Errorf("аргументы быстрого запроса попали в журнал: %s", out)
+	}
+}
@@ -0,0 +1,235 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/storage"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Ошибки шаблонов повторяющихся задач
+var (
+	RecurringTitleErr    = errors.New("Пустая строка не может быть заголовком повторяющейся задачи")
+	RecurringScheduleErr = errors.New("Не задано расписание или время следующего запуска повторяющейся задачи")
+)
+
+// Класс advisory lock шаблонов повторяющихся задач, второй ключ блокировки - ID шаблона
+const recurringLockClass = 1001
+
+// Столбцы шаблона в порядке scanRecurring
+const recurringColumns = `id, project_id, schedule, author_id, assigned_id, title, content, label_ids,
+	next_run, last_run, active`
+
+// NewRecurringTask создает активный шаблон повторяющейся задачи в проекте хранилища и возвращает его ID
+// Расписание не разбирается хранилищем: время первого запуска rt.NextRun вычисляет вызывающий код (пакет scheduler)
+// Автор, исполнитель и метки проверяются так же, как в NewTask
+func (s *Storage) NewRecurringTask(rt model.RecurringTask) (int, error) {
+	rt.Title = strings.TrimSpace(rt.Title)
+	rt.Content = strings.TrimSpace(rt.Content)
+	rt.Schedule = strings.TrimSpace(rt.Schedule)
+	if rt.Title == "" {
+		return 0, RecurringTitleErr
+	}
+	if rt.Schedule == "" || rt.NextRun.IsZero() {
+		return 0, RecurringScheduleErr
+	}
+
+	var id int
+	err := s.withTxRetry(s.retry.MaxAttempts, func() error {
+		var err error
+		id, err = s.newRecurringTask(rt)
+		return err
+	})
+	return id, err
+}
+
+// newRecurringTask выполняет транзакцию NewRecurringTask без повторов
+func (s *Storage) newRecurringTask(rt model.RecurringTask) (int, error) {
+	tx, err := s.db.Begin(s.ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(s.ctx)
+
+	if err := s.checkMembers(tx, rt.AuthorID, rt.AssignedID); err != nil {
+		return 0, err
+	}
+	if errs := s.checkLabels(tx, rt.LabelsID); len(errs.Errs) > 0 {
+		return 0, fmt.Errorf("Ошибка создания повторяющейся задачи: %w", errs)
+	}
+
+	var id int
+	err = tx.QueryRow(s.ctx, `INSERT INTO recurring_tasks(project_id, schedule, author_id, assigned_id, title, content,
+			label_ids, next_run)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7::int[], '{}'), $8) RETURNING id;`,
+		s.project, rt.Schedule, rt.AuthorID, rt.AssignedID, rt.Title, rt.Content, rt.LabelsID, rt.NextRun).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(s.ctx); err != nil {
+		return 0, fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+	}
+	return id, nil
+}
+
+// SelectRecurringTasks возвращает шаблоны повторяющихся задач проекта, отсортированные по ID
+func (s *Storage) SelectRecurringTasks() ([]model.RecurringTask, error) {
+	return retryValue(s, func() ([]model.RecurringTask, error) {
+		return collect(s, scanRecurringRows, "SELECT "+recurringColumns+` FROM recurring_tasks
+			WHERE project_id = $1 ORDER BY id ASC;`, s.project)
+	})
+}
+
+// SelectRecurringTaskByID возвращает шаблон повторяющейся задачи проекта по ID
+// Если шаблон не найден, то возвращает ошибку
+func (s *Storage) SelectRecurringTaskByID(id int) (model.RecurringTask, error) {
+	return retryValue(s, func() (model.RecurringTask, error) {
+		rt, err := scanRecurring(s.db.QueryRow(s.ctx, "SELECT "+recurringColumns+` FROM recurring_tasks
+			WHERE id = $1 AND project_id = $2;`, id, s.project))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return rt, myerrors.NotFound("Повторяющаяся задача с ID %d не найдена", id)
+		}
+		return rt, err
+	})
+}
+
+// SetRecurringTaskActive приостанавливает (active = false) или возобновляет создание задач по шаблону
+// Если шаблон не найден, то возвращает ошибку
+func (s *Storage) SetRecurringTaskActive(id int, active bool) error {
+	r, err := s.db.Exec(s.ctx, `UPDATE recurring_tasks SET active = $1 WHERE id = $2 AND project_id = $3;`,
+		active, id, s.project)
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Повторяющаяся задача с ID %d не найдена", id)
+	}
+	return nil
+}
+
+// DeleteRecurringTask удаляет шаблон повторяющейся задачи и историю его запусков
+// Созданные по шаблону задачи не удаляются, если шаблон не найден - возвращает ошибку
+func (s *Storage) DeleteRecurringTask(id int) error {
+	r, err := s.db.Exec(s.ctx, `DELETE FROM recurring_tasks WHERE id = $1 AND project_id = $2;`, id, s.project)
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Повторяющаяся задача с ID %d не найдена", id)
+	}
+	return nil
+}
+
+// SelectDueRecurringTasks возвращает не более limit активных шаблонов всех проектов,
+// время запуска которых не позже now, начиная с самых просроченных
+func (s *Storage) SelectDueRecurringTasks(now time.Time, limit int) ([]model.RecurringTask, error) {
+	return retryValue(s, func() ([]model.RecurringTask, error) {
+		return collect(s, scanRecurringRows, "SELECT "+recurringColumns+` FROM recurring_tasks
+			WHERE active AND next_run <= $1 ORDER BY next_run ASC, id ASC LIMIT $2;`, now, limit)
+	})
+}
+
+// RunRecurringTask создает задачу по шаблону rt за запуск rt.NextRun и переносит следующий запуск на next
+// Задача создается в проекте шаблона по его текущему состоянию в БД, ее ID возвращается
+// Шаблон захватывается advisory lock транзакции: если его обрабатывает другой экземпляр планировщика,
+// то сразу возвращается storage.RecurringLockedErr
+// Запуск идемпотентен: если запуск rt.NextRun уже выполнен, шаблон удален или приостановлен,
+// то задача не создается и возвращается 0
+func (s *Storage) RunRecurringTask(rt model.RecurringTask, next time.Time) (int, error) {
+	p := s.WithProject(rt.ProjectID).(*Storage)
+	var id int
+	err := p.withTxRetry(p.retry.MaxAttempts, func() error {
+		var err error
+		id, err = p.runRecurringTask(rt, next)
+		return err
+	})
+	return id, err
+}
+
+// runRecurringTask выполняет транзакцию RunRecurringTask без повторов
+func (s *Storage) runRecurringTask(rt model.RecurringTask, next time.Time) (int, error) {
+	tx, err := s.db.Begin(s.ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(s.ctx)
+
+	var locked bool
+	err = tx.QueryRow(s.ctx, `SELECT pg_try_advisory_xact_lock($1, $2);`, recurringLockClass, rt.ID).Scan(&locked)
+	if err != nil {
+		return 0, fmt.Errorf("Ошибка при блокировке повторяющейся задачи %d: %w", rt.ID, err)
+	}
+	if !locked {
+		return 0, fmt.Errorf("%w: ID %d", storage.RecurringLockedErr, rt.ID)
+	}
+
+	current, err := scanRecurring(tx.QueryRow(s.ctx, "SELECT "+recurringColumns+` FROM recurring_tasks
+		WHERE id = $1 AND project_id = $2 FOR UPDATE;`, rt.ID, s.project))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("Ошибка при получении повторяющейся задачи %d: %w", rt.ID, err)
+	}
+	if !current.Active || !current.NextRun.Equal(rt.NextRun) {
+		return 0, nil
+	}
+
+	// Запуск записывается до создания задачи: повтор того же запуска не создаст вторую задачу
+	r, err := tx.Exec(s.ctx, `INSERT INTO recurring_runs(recurring_id, run_at) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING;`, current.ID, current.NextRun)
+	if err != nil {
+		return 0, fmt.Errorf("Ошибка при записи запуска повторяющейся задачи %d: %w", rt.ID, err)
+	}
+	var id int
+	if r.RowsAffected() > 0 {
+		txStorage := *s
+		txStorage.db = tx
+		txStorage.tx = tx
+		id, err = txStorage.newTask(model.Task{
+			AuthorID:   current.AuthorID,
+			AssignedID: current.AssignedID,
+			Title:      current.Title,
+			Content:    current.Content,
+			LabelsID:   current.LabelsID,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("Ошибка создания задачи по шаблону %d: %w", rt.ID, err)
+		}
+		_, err = tx.Exec(s.ctx, `UPDATE recurring_runs SET task_id = $3 WHERE recurring_id = $1 AND run_at = $2;`,
+			current.ID, current.NextRun, id)
+		if err != nil {
+			return 0, fmt.Errorf("Ошибка при записи запуска повторяющейся задачи %d: %w", rt.ID, err)
+		}
+	}
+
+	_, err = tx.Exec(s.ctx, `UPDATE recurring_tasks SET next_run = $2, last_run = $3 WHERE id = $1;`,
+		current.ID, next, current.NextRun)
+	if err != nil {
+		return 0, fmt.Errorf("Ошибка при переносе запуска повторяющейся задачи %d: %w", rt.ID, err)
+	}
+
+	if err := tx.Commit(s.ctx); err != nil {
+		return 0, fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+	}
+	return id, nil
+}
+
+// scanRecurring считывает строку со столбцами recurringColumns в шаблон
+func scanRecurring(row pgx.Row) (model.RecurringTask, error) {
+	var rt model.RecurringTask
+	err := row.Scan(&rt.ID, &rt.ProjectID, &rt.Schedule, &rt.AuthorID, &rt.AssignedID, &rt.Title, &rt.Content,
+		&rt.LabelsID, &rt.NextRun, &rt.LastRun, &rt.Active)
+	return rt, err
+}
+
+// scanRecurringRows считывает текущую строку результата в шаблон
+func scanRecurringRows(rows pgx.Rows) (model.RecurringTask, error) {
+	return scanRecurring(rows)
+}
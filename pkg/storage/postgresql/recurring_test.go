@@ -0,0 +1,108 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestRecurring(t *testing.T, s *Storage, nextRun time.Time) model.RecurringTask {
+	t.Helper()
+	rt := model.RecurringTask{Schedule: "@daily", Title: "Ежедневная", NextRun: nextRun}
+	id, err := s.NewRecurringTask(rt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	due, err := s.SelectDueRecurringTasks(nextRun, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rt := range due {
+		if rt.ID == id {
+			return rt
+		}
+	}
+	t.Fatalf("шаблон %d не найден среди готовых к запуску: %+v", id, due)
+	return model.RecurringTask{}
+}
+
+func countTasks(t *testing.T, s *Storage) int {
+	t.Helper()
+	tasks, err := s.SelectTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(tasks)
+}
+
+// Повторный запуск шаблона за то же время (например вторым экземпляром планировщика) задачу не дублирует
+func TestRunRecurringTaskRepeated(t *testing.T) {
+	s, _ := testStorage(t)
+	nextRun := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	rt := newTestRecurring(t, s, nextRun)
+	next := nextRun.Add(24 * time.Hour)
+
+	id, err := s.RunRecurringTask(rt, next)
+	if err != nil || id == 0 {
+		t.Fatalf("RunRecurringTask() = %d, %v, want ID задачи", id, err)
+	}
+	task, err := s.SelectTaskByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Title != rt.Title {
+		t.Errorf("название задачи %q, want %q", task.Title, rt.Title)
+	}
+
+	id, err = s.RunRecurringTask(rt, next)
+	if err != nil || id != 0 {
+		t.Errorf("повторный RunRecurringTask() = %d, %v, want 0, nil", id, err)
+	}
+	if n := countTasks(t, s); n != 1 {
+		t.Errorf("создано задач %d, want 1", n)
+	}
+
+	due, err := s.SelectDueRecurringTasks(nextRun, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 0 {
+		t.Errorf("шаблон остался готовым к запуску: %+v", due)
+	}
+}
+
+// Шаблон, захваченный другой транзакцией, сразу возвращает storage.RecurringLockedErr
+func TestRunRecurringTaskLocked(t *testing.T) {
+	s, pool := testStorage(t)
+	ctx := context.Background()
+	nextRun := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	rt := newTestRecurring(t, s, nextRun)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, $2);`, recurringLockClass, rt.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := s.RunRecurringTask(rt, nextRun.Add(24*time.Hour))
+	if !errors.Is(err, storage.RecurringLockedErr) || id != 0 {
+		t.Errorf("RunRecurringTask() = %d, %v, want 0, RecurringLockedErr", id, err)
+	}
+	if n := countTasks(t, s); n != 0 {
+		t.Errorf("создано задач %d, want 0", n)
+	}
+
+	// После освобождения шаблона запуск выполняется
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := s.RunRecurringTask(rt, nextRun.Add(24*time.Hour)); err != nil || id == 0 {
+		t.Errorf("RunRecurringTask() после освобождения = %d, %v, want ID задачи", id, err)
+	}
+}
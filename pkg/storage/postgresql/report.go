@@ -0,0 +1,121 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// ReportByAssignee возвращает количество открытых и закрытых задач проекта по исполнителям
+func (s *Storage) ReportByAssignee() ([]model.AssigneeStats, error) {
+	return retryValue(s, func() ([]model.AssigneeStats, error) {
+		return collect(s, func(rows pgx.Rows) (model.AssigneeStats, error) {
+			var r model.AssigneeStats
+			err := rows.Scan(&r.UserID, &r.Name, &r.Open, &r.Closed)
+			return r, err
+		}, `SELECT users.id, users.name,
+				count(*) FILTER (WHERE tasks.closed IS NULL),
+				count(*) FILTER (WHERE tasks.closed IS NOT NULL)
+			FROM tasks JOIN users ON users.id = tasks.assigned_id
+			WHERE tasks.project_id = $1
+			GROUP BY users.id, users.name
+			ORDER BY users.id ASC;`, s.project)
+	})
+}
+
+// ReportByLabel возвращает количество открытых и закрытых задач по меткам проекта
+// Метки без задач входят в отчет с нулевыми значениями
+func (s *Storage) ReportByLabel() ([]model.LabelStats, error) {
+	return retryValue(s, func() ([]model.LabelStats, error) {
+		return collect(s, func(rows pgx.Rows) (model.LabelStats, error) {
+			var r model.LabelStats
+			err := rows.Scan(&r.LabelID, &r.Name, &r.Open, &r.Closed)
+			return r, err
+		}, `SELECT labels.id, labels.name,
+				count(tasks.id) FILTER (WHERE tasks.closed IS NULL),
+				count(tasks.id) FILTER (WHERE tasks.closed IS NOT NULL)
+			FROM labels
+				LEFT JOIN tasks_labels ON tasks_labels.label_id = labels.id
+				LEFT JOIN tasks ON tasks.id = tasks_labels.task_id
+			WHERE labels.project_id = $1
+			GROUP BY labels.id, labels.name
+			ORDER BY labels.id ASC;`, s.project)
+	})
+}
+
+// ReportTimeToClose возвращает количество закрытых задач проекта, среднее и максимальное время до закрытия
+func (s *Storage) ReportTimeToClose() (model.CloseTimeStats, error) {
+	return retryValue(s, func() (model.CloseTimeStats, error) {
+		var r model.CloseTimeStats
+		var avg, max float64
+		err := s.db.QueryRow(s.ctx, `SELECT count(*),
+				COALESCE(EXTRACT(EPOCH FROM avg(closed - opened)), 0)::float8,
+				COALESCE(EXTRACT(EPOCH FROM max(closed - opened)), 0)::float8
+			FROM tasks WHERE project_id = $1 AND closed IS NOT NULL;`, s.project).Scan(&r.Closed, &avg, &max)
+		r.Average = time.Duration(avg * float64(time.Second))
+		r.Max = time.Duration(max * float64(time.Second))
+		return r, err
+	})
+}
+
+// ReportFlow возвращает количество задач проекта, открытых и закрытых за каждый день или неделю
+// интервала [q.From, q.To). Периоды без задач входят в отчет с нулевыми значениями
+func (s *Storage) ReportFlow(q model.FlowQuery) ([]model.FlowPoint, error) {
+	return retryValue(s, func() ([]model.FlowPoint, error) {
+		return collect(s, func(rows pgx.Rows) (model.FlowPoint, error) {
+			var p model.FlowPoint
+			err := rows.Scan(&p.Start, &p.Opened, &p.Closed)
+			return p, err
+		}, `WITH periods AS (
+				SELECT generate_series(
+					date_trunc($1, $2::timestamptz AT TIME ZONE $4),
+					date_trunc($1, ($3::timestamptz - interval '1 microsecond') AT TIME ZONE $4),
+					('1 ' || $1)::interval) AS start
+			), opened AS (
+				SELECT date_trunc($1, opened AT TIME ZONE $4) AS start, count(*) AS n
+				FROM tasks WHERE project_id = $5 AND opened >= $2 AND opened < $3
+				GROUP BY 1
+			), closed AS (
+				SELECT date_trunc($1, closed AT TIME ZONE $4) AS start, count(*) AS n
+				FROM tasks WHERE project_id = $5 AND closed >= $2 AND closed < $3
+				GROUP BY 1
+			)
+			SELECT periods.start AT TIME ZONE $4, COALESCE(opened.n, 0), COALESCE(closed.n, 0)
+			FROM periods
+				LEFT JOIN opened USING (start)
+				LEFT JOIN closed USING (start)
+			ORDER BY periods.start ASC;`, string(q.Period), q.From, q.To, q.TimeZone, s.project)
+	})
+}
+
+// ReportOldestOpen возвращает не более limit открытых задач проекта, начиная с самых старых
+func (s *Storage) ReportOldestOpen(limit int) ([]model.Task, error) {
+	return retryValue(s, func() ([]model.Task, error) {
+		return s.queryTasks("SELECT "+taskColumns+` FROM tasks
+			WHERE project_id = $1 AND closed IS NULL
+			ORDER BY opened ASC, id ASC LIMIT $2;`, s.project, limit)
+	})
+}
+
+// collect выполняет запрос и считывает все строки результата функцией scan
+func collect[T any](s *Storage, scan func(pgx.Rows) (T, error), sql string, args ...any) ([]T, error) {
+	rows, err := s.db.Query(s.ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []T
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,155 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgconn"
+)
+
+// RetryPolicy задает правила повторного выполнения запросов при временных ошибках PostgreSQL
+type RetryPolicy struct {
+	// Максимальное число попыток, включая первую. Значение меньше 1 отключает повторы
+	MaxAttempts int
+	// Базовая пауза перед повтором, удваивается с каждой попыткой
+	BaseDelay time.Duration
+	// Верхняя граница паузы
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy используется хранилищем, созданным через New
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   10 * time.Millisecond,
+	MaxDelay:    time.Second,
+}
+
+// RetryStats - счетчики повторных попыток хранилища
+type RetryStats struct {
+	// Количество выполненных повторов
+	Retries uint64
+	// Количество операций, завершившихся ошибкой после исчерпания всех попыток
+	Exhausted uint64
+}
+
+type retryCounters struct {
+	retries   atomic.Uint64
+	exhausted atomic.Uint64
+}
+
+// Коды ошибок PostgreSQL, после которых операцию можно повторить
+var retryableCodes = map[string]bool{
+	SerializationFailure: true,
+	DeadlockDetected:     true,
+	"53300":              true, // too_many_connections
+	"57P01":              true, // admin_shutdown
+	"57P02":              true, // crash_shutdown
+	"57P03":              true, // cannot_connect_now
+}
+
+// SetRetryPolicy заменяет политику повторов хранилища
+func (s *Storage) SetRetryPolicy(p RetryPolicy) {
+	s.retry = p
+}
+
+// RetryStats возвращает текущие значения счетчиков повторов
+func (s *Storage) RetryStats() RetryStats {
+	return RetryStats{
+		Retries:   s.stats.retries.Load(),
+		Exhausted: s.stats.exhausted.Load(),
+	}
+}
+
+// withRetry выполняет идемпотентную операцию fn, повторяя ее по политике хранилища
+// Внутри WithTx повторы не выполняются: после ошибки транзакция уже прервана
+func (s *Storage) withRetry(fn func() error) error {
+	if s.tx != nil {
+		return fn()
+	}
+	return s.retryLoop(s.retry.MaxAttempts, isRetryable, fn)
+}
+
+// withTxRetry выполняет транзакцию fn целиком, повторяя ее по политике хранилища
+// В отличие от withRetry повторяет ошибки соединения, только если запрос не был отправлен серверу:
+// иначе фиксация могла пройти и повтор создаст дубликаты
+func (s *Storage) withTxRetry(attempts int, fn func() error) error {
+	if s.tx != nil {
+		return fn()
+	}
+	return s.retryLoop(attempts, isTxRetryable, fn)
+}
+
+func (s *Storage) retryLoop(attempts int, retryable func(error) bool, fn func() error) error {
+	var err error
+	for i := 1; ; i++ {
+		err = fn()
+		if err == nil || !retryable(err) {
+			return err
+		}
+		if i >= attempts {
+			break
+		}
+		s.stats.retries.Add(1)
+		delay := s.retry.backoff(i)
+		s.logger.WarnContext(s.ctx, "Повтор операции после временной ошибки",
+			slog.Int("attempt", i), slog.Int("max_attempts", attempts),
+			slog.Duration("delay", delay), slog.Any("error", err))
+		select {
+		case <-time.After(delay):
+		case <-s.ctx.Done():
+			return err
+		}
+	}
+	if attempts > 1 {
+		s.stats.exhausted.Add(1)
+		s.logger.ErrorContext(s.ctx, "Операция не выполнена после всех попыток",
+			slog.Int("attempts", attempts), slog.Any("error", err))
+	}
+	return err
+}
+
+// backoff возвращает паузу перед повтором после attempt-й попытки
+// Экспоненциальный рост со случайным разбросом в пределах [0, пауза]
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	return rand.N(d + 1)
+}
+
+// isRetryable сообщает, можно ли повторить идемпотентный запрос после ошибки err
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Класс 08 - ошибки соединения
+		return retryableCodes[pgErr.Code] || strings.HasPrefix(pgErr.Code, "08")
+	}
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isTxRetryable сообщает, можно ли повторить транзакцию целиком после ошибки err
+func isTxRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return retryableCodes[pgErr.Code]
+	}
+	return pgconn.SafeToRetry(err)
+}
@@ -0,0 +1,234 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// faultyOp - операция, которая возвращает заданные ошибки по очереди, а затем выполняется успешно
+type faultyOp struct {
+	errs  []error
+	calls int
+}
+
+func (f *faultyOp) run() error {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return f.errs[f.calls-1]
+	}
+	return nil
+}
+
+// fails возвращает операцию, которая n раз подряд завершается ошибкой err
+func fails(err error, n int) *faultyOp {
+	f := &faultyOp{}
+	for i := 0; i < n; i++ {
+		f.errs = append(f.errs, err)
+	}
+	return f
+}
+
+// fakeTx - внешняя транзакция WithTx, повторы в которой не выполняются
+type fakeTx struct {
+	pgx.Tx
+}
+
+func newRetryStorage(p RetryPolicy) *Storage {
+	return &Storage{
+		ctx:    context.Background(),
+		retry:  p,
+		stats:  &retryCounters{},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+var noDelayPolicy = RetryPolicy{MaxAttempts: 3}
+
+func TestWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{name: "ошибка сериализации", err: &pgconn.PgError{Code: SerializationFailure}, wantCalls: 2},
+		{name: "взаимоблокировка", err: &pgconn.PgError{Code: DeadlockDetected}, wantCalls: 2},
+		{name: "ошибка соединения PostgreSQL", err: &pgconn.PgError{Code: "08006"}, wantCalls: 2},
+		{name: "сетевая ошибка", err: &net.OpError{Op: "read", Err: errors.New("connection reset")}, wantCalls: 2},
+		{name: "обрыв соединения", err: fmt.Errorf("чтение ответа: %w", io.ErrUnexpectedEOF), wantCalls: 2},
+		{name: "нарушение уникальности", err: &pgconn.PgError{Code: UniqueViolation}, wantCalls: 1},
+		{name: "нарушение внешнего ключа", err: &pgconn.PgError{Code: ForeignKeyViolation}, wantCalls: 1},
+		{name: "запись не найдена", err: pgx.ErrNoRows, wantCalls: 1},
+		{name: "отмена контекста", err: context.Canceled, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRetryStorage(noDelayPolicy)
+			op := fails(tt.err, 1)
+			err := s.withRetry(op.run)
+			if op.calls != tt.wantCalls {
+				t.Errorf("вызовов %d, want %d", op.calls, tt.wantCalls)
+			}
+			if tt.wantCalls > 1 && err != nil {
+				t.Errorf("withRetry() error = %v, want nil после повтора", err)
+			}
+			if tt.wantCalls == 1 && !errors.Is(err, tt.err) {
+				t.Errorf("withRetry() error = %v, want %v", err, tt.err)
+			}
+			if got := s.RetryStats().Retries; got != uint64(tt.wantCalls-1) {
+				t.Errorf("RetryStats().Retries = %d, want %d", got, tt.wantCalls-1)
+			}
+		})
+	}
+}
+
+func TestWithRetryMaxAttempts(t *testing.T) {
+	for _, attempts := range []int{-1, 0, 1, 2, 5} {
+		t.Run(fmt.Sprint(attempts), func(t *testing.T) {
+			s := newRetryStorage(RetryPolicy{MaxAttempts: attempts})
+			serialization := &pgconn.PgError{Code: SerializationFailure}
+			op := fails(serialization, 10)
+
+			err := s.withRetry(op.run)
+			if !errors.Is(err, serialization) {
+				t.Errorf("withRetry() error = %v, want %v", err, serialization)
+			}
+			want := attempts
+			if want < 1 {
+				want = 1
+			}
+			if op.calls != want {
+				t.Errorf("вызовов %d, want %d", op.calls, want)
+			}
+			stats := s.RetryStats()
+			if stats.Retries != uint64(want-1) {
+				t.Errorf("RetryStats().Retries = %d, want %d", stats.Retries, want-1)
+			}
+			wantExhausted := uint64(0)
+			if want > 1 {
+				wantExhausted = 1
+			}
+			if stats.Exhausted != wantExhausted {
+				t.Errorf("RetryStats().Exhausted = %d, want %d", stats.Exhausted, wantExhausted)
+			}
+		})
+	}
+}
+
+func TestWithRetryInsideTx(t *testing.T) {
+	s := newRetryStorage(noDelayPolicy)
+	s.tx = fakeTx{}
+	serialization := &pgconn.PgError{Code: SerializationFailure}
+
+	op := fails(serialization, 1)
+	if err := s.withRetry(op.run); !errors.Is(err, serialization) {
+		t.Errorf("withRetry() error = %v, want %v", err, serialization)
+	}
+	if op.calls != 1 {
+		t.Errorf("withRetry(): вызовов %d внутри транзакции, want 1", op.calls)
+	}
+
+	op = fails(serialization, 1)
+	if err := s.withTxRetry(3, op.run); !errors.Is(err, serialization) {
+		t.Errorf("withTxRetry() error = %v, want %v", err, serialization)
+	}
+	if op.calls != 1 {
+		t.Errorf("withTxRetry(): вызовов %d внутри транзакции, want 1", op.calls)
+	}
+	if got := s.RetryStats().Retries; got != 0 {
+		t.Errorf("RetryStats().Retries = %d, want 0", got)
+	}
+}
+
+func TestWithTxRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{name: "ошибка сериализации", err: &pgconn.PgError{Code: SerializationFailure}, wantCalls: 2},
+		{name: "взаимоблокировка", err: &pgconn.PgError{Code: DeadlockDetected}, wantCalls: 2},
+		// Фиксация могла пройти, поэтому транзакция не повторяется
+		{name: "обрыв соединения", err: io.ErrUnexpectedEOF, wantCalls: 1},
+		{name: "нарушение уникальности", err: &pgconn.PgError{Code: UniqueViolation}, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRetryStorage(noDelayPolicy)
+			op := fails(tt.err, 1)
+			s.withTxRetry(3, op.run)
+			if op.calls != tt.wantCalls {
+				t.Errorf("вызовов %d, want %d", op.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWithRetryCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := newRetryStorage(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour})
+	s.ctx = ctx
+	serialization := &pgconn.PgError{Code: SerializationFailure}
+	op := fails(serialization, 10)
+
+	done := make(chan error, 1)
+	go func() { done <- s.withRetry(op.run) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, serialization) {
+			t.Errorf("withRetry() error = %v, want %v", err, serialization)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("withRetry() не прервал ожидание после отмены контекста")
+	}
+	if op.calls != 1 {
+		t.Errorf("вызовов %d, want 1", op.calls)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 100 * time.Millisecond}
+	for attempt := 1; attempt <= 70; attempt++ {
+		limit := p.BaseDelay << (attempt - 1)
+		if limit <= 0 || limit > p.MaxDelay {
+			limit = p.MaxDelay
+		}
+		for i := 0; i < 200; i++ {
+			if d := p.backoff(attempt); d < 0 || d > limit {
+				t.Fatalf("backoff(%d) = %v, want [0, %v]", attempt, d, limit)
+			}
+		}
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		seen[p.backoff(3)] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("backoff(3) без случайного разброса: %v", seen)
+	}
+}
+
+func TestBackoffZero(t *testing.T) {
+	if d := (RetryPolicy{MaxDelay: time.Second}).backoff(3); d != 0 {
+		t.Errorf("backoff() без BaseDelay = %v, want 0", d)
+	}
+	p := RetryPolicy{BaseDelay: time.Millisecond}
+	for attempt := 1; attempt <= 70; attempt++ {
+		if d := p.backoff(attempt); d < 0 {
+			t.Fatalf("backoff(%d) без MaxDelay = %v, want >= 0", attempt, d)
+		}
+	}
+}
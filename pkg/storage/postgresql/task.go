@@ -3,10 +3,11 @@ package postgresql
 import (
 	"DB_Apps/pkg/model"
 	"DB_Apps/pkg/myerrors"
-	"context"
 	"errors"
 	"fmt"
+	"iter"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
@@ -20,44 +21,54 @@ const (
 // Ошибка при добавление к существующей задаче
 var LabelOrTaskNotExistErr = errors.New("Задачи или метки не существует")
 
+// Столбцы задачи в порядке scanTask
+const taskColumns = `tasks.id, tasks.project_id, tasks.opened, tasks.closed, tasks.author_id, tasks.assigned_id,
+	tasks.title, tasks.content, tasks.estimate_s`
+
 // Ошибка при добавлении дубликата метки к задаче
 var DuplicateLabelIDErr = errors.New("Метка уже существует")
 
 // NewTask создает новую задачу и возвращает е ID
 // Перед вставкой очищает поля title и content от лишних пробелов
+// Автор и исполнитель подписываются на задачу, исполнитель и упомянутые как @Имя участники получают уведомления
 func (s *Storage) NewTask(task model.Task) (int, error) {
+	var id int
+	err := s.withTxRetry(s.retry.MaxAttempts, func() error {
+		var err error
+		id, err = s.newTask(task)
+		return err
+	})
+	return id, err
+}
+
+// newTask выполняет транзакцию NewTask без повторов
+func (s *Storage) newTask(task model.Task) (int, error) {
 	var id int
 	task.Title = strings.TrimSpace(task.Title)
 	task.Content = strings.TrimSpace(task.Content)
+	if task.Estimate < 0 {
+		return 0, TaskEstimateErr
+	}
 
-	tx, err := s.db.Begin(context.Background())
+	tx, err := s.db.Begin(s.ctx)
 	if err != nil {
 		return 0, err
 	}
-	defer tx.Rollback(context.Background())
-
-	var errs myerrors.TaskPartialErr
+	defer tx.Rollback(s.ctx)
 
-	// Проверка сущестовавания меток
-	for _, labelID := range task.LabelsID {
-		var exists bool
-		err := tx.QueryRow(context.Background(),
-			`SELECT EXISTS(SELECT 1 FROM labels WHERE id = $1)`, labelID).Scan(&exists)
-		if err != nil {
-			return 0, fmt.Errorf("Ошибка при проверке метки %d: %w", labelID, err)
-		}
-		if !exists {
-			errs.Errs = append(errs.Errs, fmt.Errorf("Метка с ID:%d не существует", labelID))
-		}
+	if err := s.checkMembers(tx, task.AuthorID, task.AssignedID); err != nil {
+		return 0, err
 	}
 
-	if len(errs.Errs) > 0 {
+	// Проверка сущестовавания меток в проекте
+	if errs := s.checkLabels(tx, task.LabelsID); len(errs.Errs) > 0 {
 		return 0, fmt.Errorf("Ошибка создания задачи: %w", errs)
 	}
 
-	err = tx.QueryRow(context.Background(),
-		`INSERT INTO tasks(author_id, assigned_id, title, content) VALUES ($1, $2, $3, $4) RETURNING id;`,
-		task.AuthorID, task.AssignedID, task.Title, task.Content).Scan(&id)
+	err = tx.QueryRow(s.ctx,
+		`INSERT INTO tasks(project_id, author_id, assigned_id, title, content, estimate_s)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;`,
+		s.project, task.AuthorID, task.AssignedID, task.Title, task.Content, int64(task.Estimate/time.Second)).Scan(&id)
 
 	if err != nil {
 		if e, ok := err.(*pgconn.PgError); ok && e.Code == ForeignKeyViolation {
@@ -74,86 +85,128 @@ func (s *Storage) NewTask(task model.Task) (int, error) {
 		}
 	}
 
-	if err = tx.Commit(context.Background()); err != nil {
+	// Автор и исполнитель подписываются на задачу, исполнитель и упомянутые участники получают уведомления
+	task.ID, task.ProjectID = id, s.project
+	if err := s.watch(tx, id, task.AuthorID, task.AssignedID); err != nil {
+		return 0, err
+	}
+	n := s.newNotifications(task, task.AuthorID)
+	n.add(model.NotifyAssigned, "Вам назначена задача", task.AssignedID)
+	if err := s.addMentions(tx, n, task.Title+"\n"+task.Content, "", "Вас упомянули в задаче"); err != nil {
+		return 0, err
+	}
+	if err := s.send(tx, n); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(s.ctx); err != nil {
 		return 0, fmt.Errorf("ошибка при коммите транзакции: %w", err)
 	}
 
 	return id, nil
 }
 
-// SelectTasks возвращает список всех задач, отсортированных по ID
+// SelectTasks возвращает список всех задач проекта, отсортированных по ID
 func (s *Storage) SelectTasks() ([]model.Task, error) {
-	var tasks []model.Task
-	rows, err := s.db.Query(context.Background(), `SELECT id, opened, closed, author_id, assigned_id,
-														title, content FROM tasks ORDER BY id ASC;`)
+	return retryValue(s, s.selectTasks)
+}
+
+// selectTasks выполняет запрос SelectTasks без повторов
+func (s *Storage) selectTasks() ([]model.Task, error) {
+	return s.queryTasks("SELECT "+taskColumns+" FROM tasks WHERE project_id = $1 ORDER BY id ASC;", s.project)
+}
+
+// SelectTaskByID возвращает задачу проекта по ID вместе с ID ее меток
+// Если задача не найдена, то возвращает ошибку
+func (s *Storage) SelectTaskByID(id int) (model.Task, error) {
+	return retryValue(s, func() (model.Task, error) {
+		return s.selectTaskByID(id)
+	})
+}
+
+// selectTaskByID выполняет запрос SelectTaskByID без повторов
+func (s *Storage) selectTaskByID(id int) (model.Task, error) {
+	var labels []int
+	task, err := scanTask(s.db.QueryRow(s.ctx, "SELECT "+taskColumns+`,
+			ARRAY(SELECT label_id FROM tasks_labels WHERE task_id = tasks.id ORDER BY label_id)
+		FROM tasks WHERE id = $1 AND project_id = $2;`, id, s.project), &labels)
+	task.LabelsID = labels
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	for rows.Next() {
-		var task model.Task
-		err = rows.Scan(
-			&task.ID,
-			&task.Opened,
-			&task.Closed,
-			&task.AuthorID,
-			&task.AssignedID,
-			&task.Title,
-			&task.Content,
-		)
-		if err != nil {
-			return nil, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return task, myerrors.NotFound("Задача с ID %d не найдена", id)
 		}
-		tasks = append(tasks, task)
+		return task, fmt.Errorf("Ошибка при получении задачи %d: %w", id, err)
 	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-	return tasks, nil
+	return task, nil
 }
 
-// SelectTasksByAuthorID возвращает все задачи, созданные конкретным автором
+// SelectTasksByAuthorID возвращает все задачи проекта, созданные конкретным автором
 func (s *Storage) SelectTasksByAuthorID(authorID int) ([]model.Task, error) {
-	rows, err := s.db.Query(context.Background(), `SELECT id, opened, closed, author_id, assigned_id,
-														title, content FROM tasks WHERE author_id = $1 ORDER BY id ASC;`, authorID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+	return retryValue(s, func() ([]model.Task, error) {
+		return s.selectTasksByAuthorID(authorID)
+	})
+}
 
-	var tasks []model.Task
-	for rows.Next() {
-		var task model.Task
-		err = rows.Scan(
-			&task.ID,
-			&task.Opened,
-			&task.Closed,
-			&task.AuthorID,
-			&task.AssignedID,
-			&task.Title,
-			&task.Content,
-		)
+// selectTasksByAuthorID выполняет запрос SelectTasksByAuthorID без повторов
+func (s *Storage) selectTasksByAuthorID(authorID int) ([]model.Task, error) {
+	return s.queryTasks("SELECT "+taskColumns+` FROM tasks
+		WHERE author_id = $1 AND project_id = $2 ORDER BY id ASC;`, authorID, s.project)
+}
+
+// SelectTasksByLabelID возвращает все задачи проекта, связанные с конкретной меткой
+func (s *Storage) SelectTasksByLabelID(labelID int) ([]model.Task, error) {
+	return retryValue(s, func() ([]model.Task, error) {
+		return s.selectTasksByLabelID(labelID)
+	})
+}
+
+// selectTasksByLabelID выполняет запрос SelectTasksByLabelID без повторов
+func (s *Storage) selectTasksByLabelID(labelID int) ([]model.Task, error) {
+	return s.queryTasks("SELECT "+taskColumns+` FROM tasks JOIN tasks_labels
+		ON tasks.id = tasks_labels.task_id
+		WHERE tasks_labels.label_id = $1 AND tasks.project_id = $2 ORDER BY tasks.id ASC;`, labelID, s.project)
+}
+
+// SelectTasksPage возвращает не более q.Limit задач проекта с ID больше q.AfterID, отсортированных по ID
+// Задачи фильтруются по автору, исполнителю и метке, если они заданы
+func (s *Storage) SelectTasksPage(q model.TaskPageQuery) ([]model.Task, error) {
+	return retryValue(s, func() ([]model.Task, error) {
+		return s.queryTasks("SELECT "+taskColumns+` FROM tasks
+			WHERE project_id = $1 AND id > $2
+				AND ($3 = 0 OR author_id = $3)
+				AND ($4 = 0 OR assigned_id = $4)
+				AND ($5 = 0 OR EXISTS(SELECT 1 FROM tasks_labels WHERE task_id = tasks.id AND label_id = $5))
+			ORDER BY id ASC LIMIT $6;`, s.project, q.AfterID, q.AuthorID, q.AssignedID, q.LabelID, q.Limit)
+	})
+}
+
+// SelectTaskLabelIDs возвращает ID меток задач проекта с ID из taskIDs
+// В результат попадают только задачи, у которых есть метки
+func (s *Storage) SelectTaskLabelIDs(taskIDs []int) (map[int][]int, error) {
+	return retryValue(s, func() (map[int][]int, error) {
+		type pair struct{ taskID, labelID int }
+		pairs, err := collect(s, func(rows pgx.Rows) (pair, error) {
+			var p pair
+			err := rows.Scan(&p.taskID, &p.labelID)
+			return p, err
+		}, `SELECT tasks_labels.task_id, tasks_labels.label_id FROM tasks_labels
+			JOIN tasks ON tasks.id = tasks_labels.task_id
+			WHERE tasks_labels.task_id = ANY($1) AND tasks.project_id = $2
+			ORDER BY tasks_labels.task_id, tasks_labels.label_id;`, taskIDs, s.project)
 		if err != nil {
 			return nil, err
 		}
-		tasks = append(tasks, task)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-	return tasks, nil
+		labels := make(map[int][]int)
+		for _, p := range pairs {
+			labels[p.taskID] = append(labels[p.taskID], p.labelID)
+		}
+		return labels, nil
+	})
 }
 
-// SelectTasksByLabelID возвращает все задачи, связанные с конкретной меткой
-func (s *Storage) SelectTasksByLabelID(labelID int) ([]model.Task, error) {
-	rows, err := s.db.Query(context.Background(), `SELECT tasks.id, tasks.opened, 
-														tasks.closed, tasks.author_id, 
-														tasks.assigned_id, tasks.title, tasks.content
-														FROM tasks JOIN tasks_labels 
-														ON tasks.id = tasks_labels.task_id 
-														WHERE tasks_labels.label_id = $1;`, labelID)
+// queryTasks выполняет запрос со столбцами taskColumns и возвращает все задачи результата
+func (s *Storage) queryTasks(sql string, args ...any) ([]model.Task, error) {
+	rows, err := s.db.Query(s.ctx, sql, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -161,16 +214,7 @@ func (s *Storage) SelectTasksByLabelID(labelID int) ([]model.Task, error) {
 
 	var tasks []model.Task
 	for rows.Next() {
-		var task model.Task
-		err = rows.Scan(
-			&task.ID,
-			&task.Opened,
-			&task.Closed,
-			&task.AuthorID,
-			&task.AssignedID,
-			&task.Title,
-			&task.Content,
-		)
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -183,29 +227,87 @@ func (s *Storage) SelectTasksByLabelID(labelID int) ([]model.Task, error) {
 	return tasks, nil
 }
 
-// DeleteTask удаляет задачу по ID
+// IterTasks возвращает итератор по всем задачам проекта, отсортированным по ID
+// В отличие от SelectTasks не загружает всю таблицу в память
+func (s *Storage) IterTasks() iter.Seq2[model.Task, error] {
+	return queryIter(s, scanTaskRows, "SELECT "+taskColumns+" FROM tasks WHERE project_id = $1 ORDER BY id ASC;",
+		s.project)
+}
+
+// IterTasksByAuthorID возвращает итератор по задачам проекта автора authorID
+func (s *Storage) IterTasksByAuthorID(authorID int) iter.Seq2[model.Task, error] {
+	return queryIter(s, scanTaskRows, "SELECT "+taskColumns+` FROM tasks
+		WHERE author_id = $1 AND project_id = $2 ORDER BY id ASC;`, authorID, s.project)
+}
+
+// IterTasksByLabelID возвращает итератор по задачам проекта с меткой labelID
+func (s *Storage) IterTasksByLabelID(labelID int) iter.Seq2[model.Task, error] {
+	return queryIter(s, scanTaskRows, "SELECT "+taskColumns+` FROM tasks JOIN tasks_labels
+		ON tasks.id = tasks_labels.task_id
+		WHERE tasks_labels.label_id = $1 AND tasks.project_id = $2 ORDER BY tasks.id ASC;`, labelID, s.project)
+}
+
+// scanTask считывает строку со столбцами taskColumns в задачу
+// Значения дополнительных столбцов после taskColumns записываются в extra
+func scanTask(row pgx.Row, extra ...any) (model.Task, error) {
+	var task model.Task
+	var estimate int64
+	dest := []any{
+		&task.ID,
+		&task.ProjectID,
+		&task.Opened,
+		&task.Closed,
+		&task.AuthorID,
+		&task.AssignedID,
+		&task.Title,
+		&task.Content,
+		&estimate,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	task.Estimate = time.Duration(estimate) * time.Second
+	return task, err
+}
+
+// scanTaskRows считывает текущую строку результата в задачу
+func scanTaskRows(rows pgx.Rows) (model.Task, error) {
+	return scanTask(rows)
+}
+
+// DeleteTask удаляет задачу по ID вместе с ее вложениями
 // Возвращает ошибку, если задача не найдена
 func (s *Storage) DeleteTask(id int) error {
-	tx, err := s.db.Begin(context.Background())
+	err := s.withTxRetry(s.retry.MaxAttempts, func() error {
+		return s.deleteTask(id)
+	})
+	if err == nil {
+		s.blobsReleased()
+	}
+	return err
+}
+
+// deleteTask выполняет транзакцию DeleteTask без повторов
+func (s *Storage) deleteTask(id int) error {
+	tx, err := s.db.Begin(s.ctx)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback(context.Background())
-	_, err = tx.Exec(context.Background(), "DELETE FROM tasks_labels WHERE task_id = $1;", id)
+	defer tx.Rollback(s.ctx)
+	_, err = tx.Exec(s.ctx, `DELETE FROM tasks_labels WHERE task_id = $1
+		AND EXISTS(SELECT 1 FROM tasks WHERE id = $1 AND project_id = $2);`, id, s.project)
 	if err != nil {
 		return fmt.Errorf("Ошибка при удалении связей задачи %d: %w", id, err)
 	}
 
-	r, err := tx.Exec(context.Background(), "DELETE FROM tasks WHERE id = $1;", id)
+	r, err := tx.Exec(s.ctx, "DELETE FROM tasks WHERE id = $1 AND project_id = $2;", id, s.project)
 	if err != nil {
 		return fmt.Errorf("Ошибка при удалении задачи %d: %w", id, err)
 	}
 
 	if r.RowsAffected() == 0 {
-		return fmt.Errorf("Задача с ID %d не найдена", id)
+		return myerrors.NotFound("Задача с ID %d не найдена", id)
 	}
 
-	if err := tx.Commit(context.Background()); err != nil {
+	if err := tx.Commit(s.ctx); err != nil {
 		return fmt.Errorf("Ошибка при созранении результатов транзакции: %w", err)
 	}
 
@@ -213,23 +315,35 @@ func (s *Storage) DeleteTask(id int) error {
 }
 
 // UpdateTaskByID обновляет поля задачи (автора, исполнителя, заголовок, описание)
+// Оценка задачи не меняется, для этого используется SetTaskEstimate
 // Перед обновлением очищает текстовые поля от пробелов
+// Новый исполнитель подписывается на задачу и получает уведомление, как и впервые упомянутые в ней участники,
+// остальные подписчики уведомляются об изменении
 // Возвращает ошибку, если задача с указанным ID не найдена
 func (s *Storage) UpdateTaskByID(task model.Task) error {
-	tx, err := s.db.Begin(context.Background())
+	return s.withTxRetry(s.retry.MaxAttempts, func() error {
+		return s.updateTaskByID(task)
+	})
+}
+
+// updateTaskByID выполняет транзакцию UpdateTaskByID без повторов
+func (s *Storage) updateTaskByID(task model.Task) error {
+	tx, err := s.db.Begin(s.ctx)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback(context.Background())
+	defer tx.Rollback(s.ctx)
 
 	var currentAuthorID int
-	err = tx.QueryRow(context.Background(),
-		`SELECT author_id FROM tasks WHERE id = $1;`, task.ID).Scan(&currentAuthorID)
+	var current model.Task
+	err = tx.QueryRow(s.ctx, `SELECT author_id, assigned_id, title, content FROM tasks
+		WHERE id = $1 AND project_id = $2 FOR UPDATE;`, task.ID, s.project).
+		Scan(&currentAuthorID, &current.AssignedID, &current.Title, &current.Content)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return fmt.Errorf("Задача с ID %d не найдена", task.ID)
+			return myerrors.NotFound("Задача с ID %d не найдена", task.ID)
 		}
-		return fmt.Errorf("Ошибка при получении задачи: %w", task.ID, err)
+		return fmt.Errorf("Ошибка при получении задачи %d: %w", task.ID, err)
 	}
 
 	if currentAuthorID != 0 && currentAuthorID != task.AuthorID {
@@ -237,7 +351,7 @@ func (s *Storage) UpdateTaskByID(task model.Task) error {
 	}
 
 	var assignedExists bool
-	err = tx.QueryRow(context.Background(),
+	err = tx.QueryRow(s.ctx,
 		`SELECT EXISTS(SELECT 1 FROM users WHERE id = $1);`, task.AssignedID).Scan(&assignedExists)
 	if err != nil {
 		return fmt.Errorf("Ошибка при проверке исполнителя: %w", err)
@@ -245,43 +359,33 @@ func (s *Storage) UpdateTaskByID(task model.Task) error {
 	if !assignedExists {
 		return fmt.Errorf("Исполнитель с ID %d не существует", task.AssignedID)
 	}
-
-	var errs myerrors.TaskPartialErr
-	for _, labelID := range task.LabelsID {
-		var labelExists bool
-		err := tx.QueryRow(context.Background(),
-			`SELECT EXISTS(SELECT 1 FROM labels WHERE id = $1)`, labelID).Scan(&labelExists)
-		if err != nil {
-			return fmt.Errorf("Ошибка при проверке метки %d: %w", labelID, err)
-		}
-		if !labelExists {
-			errs.Errs = append(errs.Errs, fmt.Errorf("Метка с ID:%d не существует", labelID))
-		}
+	if err := s.checkMembers(tx, task.AssignedID); err != nil {
+		return err
 	}
 
-	if len(errs.Errs) > 0 {
+	if errs := s.checkLabels(tx, task.LabelsID); len(errs.Errs) > 0 {
 		return errs
 	}
 
 	task.Title = strings.TrimSpace(task.Title)
 	task.Content = strings.TrimSpace(task.Content)
 
-	r, err := tx.Exec(context.Background(), `UPDATE tasks
+	r, err := tx.Exec(s.ctx, `UPDATE tasks
 		SET assigned_id = $1,
 			title = $2,
 			content = $3
-		WHERE id = $4;`,
-		task.AssignedID, task.Title, task.Content, task.ID)
+		WHERE id = $4 AND project_id = $5;`,
+		task.AssignedID, task.Title, task.Content, task.ID, s.project)
 	if err != nil {
 		return fmt.Errorf("Ошибка при обновлении задачи: %w", err)
 	}
 
 	if r.RowsAffected() == 0 {
-		return fmt.Errorf("Задача с ID %d не найдена", task.ID)
+		return myerrors.NotFound("Задача с ID %d не найдена", task.ID)
 	}
 
 	// Удаление старых меток
-	_, err = tx.Exec(context.Background(),
+	_, err = tx.Exec(s.ctx,
 		`DELETE FROM tasks_labels WHERE task_id = $1;`, task.ID)
 	if err != nil {
 		return fmt.Errorf("Ошибка при удалении старых меток: %w", err)
@@ -294,18 +398,107 @@ func (s *Storage) UpdateTaskByID(task model.Task) error {
 		}
 	}
 
-	if err := tx.Commit(context.Background()); err != nil {
+	task.ProjectID = s.project
+	n := s.newNotifications(task, 0)
+	if task.AssignedID != current.AssignedID {
+		if err := s.watch(tx, task.ID, task.AssignedID); err != nil {
+			return err
+		}
+		n.add(model.NotifyAssigned, "Вам назначена задача", task.AssignedID)
+	}
+	err = s.addMentions(tx, n, task.Title+"\n"+task.Content, current.Title+"\n"+current.Content, "Вас упомянули в задаче")
+	if err != nil {
+		return err
+	}
+	if err := s.addWatchers(tx, n, "Задача изменена"); err != nil {
+		return err
+	}
+	if err := s.send(tx, n); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(s.ctx); err != nil {
 		return fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
 	}
 
 	return nil
 }
 
+// CloseTask закрывает задачу проекта, время закрытия задает БД, подписчики задачи получают уведомление
+// Повторное закрытие не меняет время закрытия и не уведомляет, если задача не найдена - возвращает ошибку
+func (s *Storage) CloseTask(id int) error {
+	return s.withTxRetry(s.retry.MaxAttempts, func() error {
+		return s.closeTask(id)
+	})
+}
+
+// closeTask выполняет транзакцию CloseTask без повторов
+func (s *Storage) closeTask(id int) error {
+	tx, err := s.db.Begin(s.ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(s.ctx)
+
+	task := model.Task{ID: id, ProjectID: s.project}
+	err = tx.QueryRow(s.ctx, `SELECT title, closed FROM tasks WHERE id = $1 AND project_id = $2 FOR UPDATE;`,
+		id, s.project).Scan(&task.Title, &task.Closed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return myerrors.NotFound("Задача с ID %d не найдена", id)
+	}
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении задачи %d: %w", id, err)
+	}
+	if task.Closed != nil {
+		return nil
+	}
+	if _, err := tx.Exec(s.ctx, `UPDATE tasks SET closed = now() WHERE id = $1;`, id); err != nil {
+		return fmt.Errorf("Ошибка при закрытии задачи %d: %w", id, err)
+	}
+	n := s.newNotifications(task, 0)
+	if err := s.addWatchers(tx, n, "Задача закрыта"); err != nil {
+		return err
+	}
+	if err := s.send(tx, n); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(s.ctx); err != nil {
+		return fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+	}
+	return nil
+}
+
+// checkLabels проверяет, что метки labelIDs существуют в проекте хранилища
+// Ошибки по всем отсутствующим меткам собираются в TaskPartialErr, ошибка запроса записывается туда же
+func (s *Storage) checkLabels(tx pgx.Tx, labelIDs []int) myerrors.TaskPartialErr {
+	var errs myerrors.TaskPartialErr
+	for _, labelID := range labelIDs {
+		var exists bool
+		err := tx.QueryRow(s.ctx,
+			`SELECT EXISTS(SELECT 1 FROM labels WHERE id = $1 AND project_id = $2)`, labelID, s.project).Scan(&exists)
+		if err != nil {
+			errs.Errs = append(errs.Errs, fmt.Errorf("Ошибка при проверке метки %d: %w", labelID, err))
+			return errs
+		}
+		if !exists {
+			errs.Errs = append(errs.Errs, fmt.Errorf("Метка с ID:%d не существует", labelID))
+		}
+	}
+	return errs
+}
+
 // AddLabelToTask добавляет метку к задаче
+// Задача и метка должны принадлежать проекту хранилища, иначе возвращается LabelOrTaskNotExistErr
 // Если такая связь уже существует, то возвращает ошибку
 func (s *Storage) AddLabelToTask(id_label, id_task int) error {
-	_, err := s.db.Exec(context.Background(), `INSERT INTO tasks_labels (task_id, label_id) 
-												VALUES ($1, $2);`, id_task, id_label)
+	r, err := s.db.Exec(s.ctx, `INSERT INTO tasks_labels (task_id, label_id)
+		SELECT $1, $2
+		WHERE EXISTS(SELECT 1 FROM tasks WHERE id = $1 AND project_id = $3)
+			AND EXISTS(SELECT 1 FROM labels WHERE id = $2 AND project_id = $3);`, id_task, id_label, s.project)
+	if err == nil && r.RowsAffected() == 0 {
+		return fmt.Errorf("Ошибка связи для задачи с ID:%d и метки с ID:%d: %w ", id_task, id_label, LabelOrTaskNotExistErr)
+	}
 	if err != nil {
 		if pgErr, ok := err.(*pgconn.PgError); ok {
 			switch pgErr.Code {
@@ -325,7 +518,7 @@ func (s *Storage) AddLabelToTask(id_label, id_task int) error {
 // syncLabelToTask добавляет метку к задаче используя транзакцию
 // Если такая связь уже существует, то возвращает ошибку
 func (s *Storage) syncLabelToTask(tx pgx.Tx, id_label, id_task int) error {
-	_, err := tx.Exec(context.Background(),
+	_, err := tx.Exec(s.ctx,
 		`INSERT INTO tasks_labels (task_id, label_id) VALUES ($1, $2);`,
 		id_task, id_label)
 	if err != nil {
@@ -346,14 +539,15 @@ func (s *Storage) syncLabelToTask(tx pgx.Tx, id_label, id_task int) error {
 // DeleteLabelToTask удаляет связь между задачей и меткой
 // Если связь отсутствует, то возвращает ошибку
 func (s *Storage) DeleteLabelToTask(id_label, id_task int) error {
-	r, err := s.db.Exec(context.Background(), `DELETE FROM tasks_labels  
-												WHERE task_id = $1 AND label_id = $2`,
-		id_task, id_label)
+	r, err := s.db.Exec(s.ctx, `DELETE FROM tasks_labels
+		WHERE task_id = $1 AND label_id = $2
+			AND EXISTS(SELECT 1 FROM tasks WHERE id = $1 AND project_id = $3)`,
+		id_task, id_label, s.project)
 	if err != nil {
 		return err
 	}
 	if r.RowsAffected() == 0 {
-		return fmt.Errorf("Метка с ID:%d не существовало для задачи с ID:%d", id_label, id_task)
+		return myerrors.NotFound("Метка с ID:%d не существовало для задачи с ID:%d", id_label, id_task)
 	}
 	return nil
 }
@@ -0,0 +1,247 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// Ошибки шаблонов задач
+var (
+	TemplateNameErr      = errors.New("Пустая строка не может быть названием шаблона")
+	TemplateTitleErr     = errors.New("Пустая строка не может быть заголовком шаблона")
+	DuplicateTemplateErr = errors.New("Шаблон с таким названием уже существует в проекте")
+	TemplateVarErr       = errors.New("Не заданы переменные шаблона")
+)
+
+// Переменная шаблона: {{имя}}, имя из букв, цифр и _
+var templateVarRe = regexp.MustCompile(`\{\{\s*([\p{L}\p{N}_]+)\s*\}\}`)
+
+// Столбцы шаблона в порядке scanTemplate
+const templateColumns = `id, project_id, name, title_pattern, content, assigned_id, label_ids`
+
+// NewTaskTemplate создает шаблон задачи в проекте хранилища и возвращает его ID
+// Исполнитель и метки проверяются так же, как в NewTask
+// Если название уже занято в проекте, то возвращает DuplicateTemplateErr
+func (s *Storage) NewTaskTemplate(t model.TaskTemplate) (int, error) {
+	if err := checkTemplate(&t); err != nil {
+		return 0, err
+	}
+	var id int
+	err := s.withTxRetry(s.retry.MaxAttempts, func() error {
+		var err error
+		id, err = s.saveTemplate(t, `INSERT INTO task_templates(project_id, name, title_pattern, content,
+				assigned_id, label_ids)
+			VALUES ($1, $2, $3, $4, $5, COALESCE($6::int[], '{}')) RETURNING id;`,
+			s.project, t.Name, t.TitlePattern, t.Content, t.AssignedID, t.LabelsID)
+		return err
+	})
+	return id, err
+}
+
+// UpdateTaskTemplate изменяет название, заголовок, описание, исполнителя и метки шаблона по t.ID
+// Если шаблон не найден, то возвращает ошибку
+func (s *Storage) UpdateTaskTemplate(t model.TaskTemplate) error {
+	if err := checkTemplate(&t); err != nil {
+		return err
+	}
+	return s.withTxRetry(s.retry.MaxAttempts, func() error {
+		_, err := s.saveTemplate(t, `UPDATE task_templates
+			SET name = $3, title_pattern = $4, content = $5, assigned_id = $6, label_ids = COALESCE($7::int[], '{}')
+			WHERE id = $1 AND project_id = $2 RETURNING id;`,
+			t.ID, s.project, t.Name, t.TitlePattern, t.Content, t.AssignedID, t.LabelsID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return myerrors.NotFound("Шаблон задачи с ID %d не найден", t.ID)
+		}
+		return err
+	})
+}
+
+// saveTemplate проверяет исполнителя и метки шаблона и выполняет запрос сохранения sql, возвращающий ID
+func (s *Storage) saveTemplate(t model.TaskTemplate, sql string, args ...any) (int, error) {
+	tx, err := s.db.Begin(s.ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(s.ctx)
+
+	if err := s.checkMembers(tx, t.AssignedID); err != nil {
+		return 0, err
+	}
+	if errs := s.checkLabels(tx, t.LabelsID); len(errs.Errs) > 0 {
+		return 0, fmt.Errorf("Ошибка сохранения шаблона задачи: %w", errs)
+	}
+
+	var id int
+	if err := tx.QueryRow(s.ctx, sql, args...).Scan(&id); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == UniqueViolation {
+			return 0, fmt.Errorf("%w: %s", DuplicateTemplateErr, t.Name)
+		}
+		if errors.As(err, &pgErr) && pgErr.Code == ForeignKeyViolation {
+			return 0, myerrors.NotFound("Исполнитель с ID %d не найден", t.AssignedID)
+		}
+		return 0, err
+	}
+
+	if err := tx.Commit(s.ctx); err != nil {
+		return 0, fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+	}
+	return id, nil
+}
+
+// DeleteTaskTemplate удаляет шаблон задачи, созданные по нему задачи не меняются
+// Если шаблон не найден, то возвращает ошибку
+func (s *Storage) DeleteTaskTemplate(id int) error {
+	r, err := s.db.Exec(s.ctx, `DELETE FROM task_templates WHERE id = $1 AND project_id = $2;`, id, s.project)
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Шаблон задачи с ID %d не найден", id)
+	}
+	return nil
+}
+
+// SelectTaskTemplates возвращает шаблоны задач проекта, отсортированные по названию
+func (s *Storage) SelectTaskTemplates() ([]model.TaskTemplate, error) {
+	return retryValue(s, func() ([]model.TaskTemplate, error) {
+		return collect(s, func(rows pgx.Rows) (model.TaskTemplate, error) {
+			return scanTemplate(rows)
+		}, "SELECT "+templateColumns+` FROM task_templates WHERE project_id = $1 ORDER BY lower(name), id;`,
+			s.project)
+	})
+}
+
+// SelectTaskTemplateByID возвращает шаблон задачи проекта по ID
+// Если шаблон не найден, то возвращает ошибку
+func (s *Storage) SelectTaskTemplateByID(id int) (model.TaskTemplate, error) {
+	return retryValue(s, func() (model.TaskTemplate, error) {
+		return s.selectTemplate(s.db, id)
+	})
+}
+
+// selectTemplate выполняет запрос SelectTaskTemplateByID через q (пул или транзакцию)
+func (s *Storage) selectTemplate(q querier, id int) (model.TaskTemplate, error) {
+	t, err := scanTemplate(q.QueryRow(s.ctx, "SELECT "+templateColumns+` FROM task_templates
+		WHERE id = $1 AND project_id = $2;`, id, s.project))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return t, myerrors.NotFound("Шаблон задачи с ID %d не найден", id)
+	}
+	return t, err
+}
+
+// NewTaskFromTemplate создает задачу автора authorID по шаблону templateID и возвращает ее ID
+// Переменные {{имя}} в заголовке и описании заменяются значениями из vars,
+// встроенные переменные: date - текущая дата (ГГГГ-ММ-ДД), author - имя автора
+// Если для переменной нет значения, то возвращает TemplateVarErr со списком таких переменных
+// Задача создается тем же путем, что и в NewTask: отсутствующие метки шаблона дают TaskPartialErr
+func (s *Storage) NewTaskFromTemplate(templateID, authorID int, vars map[string]string) (int, error) {
+	var id int
+	err := s.withTxRetry(s.retry.MaxAttempts, func() error {
+		var err error
+		id, err = s.newTaskFromTemplate(templateID, authorID, vars)
+		return err
+	})
+	return id, err
+}
+
+// newTaskFromTemplate выполняет транзакцию NewTaskFromTemplate без повторов
+func (s *Storage) newTaskFromTemplate(templateID, authorID int, vars map[string]string) (int, error) {
+	tx, err := s.db.Begin(s.ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(s.ctx)
+
+	t, err := s.selectTemplate(tx, templateID)
+	if err != nil {
+		return 0, err
+	}
+	var author string
+	err = tx.QueryRow(s.ctx, `SELECT name FROM users WHERE id = $1;`, authorID).Scan(&author)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, myerrors.NotFound("Автор с ID %d не найден", authorID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("Ошибка при получении автора %d: %w", authorID, err)
+	}
+
+	values := templateValues(time.Now().In(s.loc), author, vars)
+	var missing []string
+	title := renderTemplate(t.TitlePattern, values, &missing)
+	content := renderTemplate(t.Content, values, &missing)
+	if len(missing) > 0 {
+		return 0, fmt.Errorf("%w: %s", TemplateVarErr, strings.Join(missing, ", "))
+	}
+
+	txStorage := *s
+	txStorage.db = tx
+	txStorage.tx = tx
+	id, err := txStorage.newTask(model.Task{AuthorID: authorID, AssignedID: t.AssignedID, Title: title,
+		Content: content, LabelsID: t.LabelsID})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(s.ctx); err != nil {
+		return 0, fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+	}
+	return id, nil
+}
+
+// templateValues возвращает значения переменных шаблона: встроенные date (дата now) и author и переданные vars
+// Переданные значения заменяют встроенные
+func templateValues(now time.Time, author string, vars map[string]string) map[string]string {
+	values := map[string]string{"date": now.Format(time.DateOnly), "author": author}
+	for k, v := range vars {
+		values[k] = v
+	}
+	return values
+}
+
+// renderTemplate заменяет переменные {{имя}} в pattern значениями из values
+// Имена переменных без значения добавляются в missing без повторов
+func renderTemplate(pattern string, values map[string]string, missing *[]string) string {
+	return templateVarRe.ReplaceAllStringFunc(pattern, func(m string) string {
+		name := templateVarRe.FindStringSubmatch(m)[1]
+		if v, ok := values[name]; ok {
+			return v
+		}
+		for _, n := range *missing {
+			if n == name {
+				return m
+			}
+		}
+		*missing = append(*missing, name)
+		return m
+	})
+}
+
+// checkTemplate очищает текстовые поля шаблона от лишних пробелов и проверяет название и заголовок
+func checkTemplate(t *model.TaskTemplate) error {
+	t.Name = strings.Join(strings.Fields(t.Name), " ")
+	t.TitlePattern = strings.TrimSpace(t.TitlePattern)
+	t.Content = strings.TrimSpace(t.Content)
+	if t.Name == "" {
+		return TemplateNameErr
+	}
+	if t.TitlePattern == "" {
+		return TemplateTitleErr
+	}
+	return nil
+}
+
+// scanTemplate считывает строку со столбцами templateColumns в шаблон
+func scanTemplate(row pgx.Row) (model.TaskTemplate, error) {
+	var t model.TaskTemplate
+	err := row.Scan(&t.ID, &t.ProjectID, &t.Name, &t.TitlePattern, &t.Content, &t.AssignedID, &t.LabelsID)
+	return t, err
+}
@@ -0,0 +1,69 @@
+package postgresql
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	values := map[string]string{"date": "2024-05-01", "author": "Иван", "версия": "1.2", "host_1": "db1"}
+	tests := []struct {
+		name        string
+		pattern     string
+		want        string
+		wantMissing []string
+	}{
+		{name: "без переменных", pattern: "Проверка", want: "Проверка"},
+		{name: "встроенные переменные", pattern: "Отчет {{date}} ({{author}})", want: "Отчет 2024-05-01 (Иван)"},
+		{name: "пробелы внутри скобок", pattern: "Выпуск {{ версия }}", want: "Выпуск 1.2"},
+		{name: "цифры и подчеркивание", pattern: "Сервер {{host_1}}", want: "Сервер db1"},
+		{name: "повтор переменной", pattern: "{{author}} и {{author}}", want: "Иван и Иван"},
+		{
+			name:        "нет значения",
+			pattern:     "{{host}}: {{author}} {{задача}}, {{host}}",
+			want:        "{{host}}: Иван {{задача}}, {{host}}",
+			wantMissing: []string{"host", "задача"},
+		},
+		{name: "не переменная", pattern: "{{}} {{a-b}} {author} {{author", want: "{{}} {{a-b}} {author} {{author"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var missing []string
+			if got := renderTemplate(tt.pattern, values, &missing); got != tt.want {
+				t.Errorf("renderTemplate(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+			if !slices.Equal(missing, tt.wantMissing) {
+				t.Errorf("missing = %v, want %v", missing, tt.wantMissing)
+			}
+		})
+	}
+}
+
+// Недостающие переменные заголовка и описания собираются в один список без повторов
+func TestRenderTemplateMissingAcrossFields(t *testing.T) {
+	var missing []string
+	renderTemplate("{{a}} {{b}}", nil, &missing)
+	renderTemplate("{{b}} {{c}}", nil, &missing)
+	if want := []string{"a", "b", "c"}; !slices.Equal(missing, want) {
+		t.Errorf("missing = %v, want %v", missing, want)
+	}
+}
+
+func TestTemplateValues(t *testing.T) {
+	// 22:30 по UTC 30 апреля - уже 1 мая в Москве
+	now := time.Date(2024, 4, 30, 22, 30, 0, 0, time.UTC)
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	values := templateValues(now.In(moscow), "Иван", map[string]string{"версия": "1.2"})
+	if values["date"] != "2024-05-01" || values["author"] != "Иван" || values["версия"] != "1.2" {
+		t.Errorf("templateValues() = %v", values)
+	}
+	if got := templateValues(now, "Иван", nil)["date"]; got != "2024-04-30" {
+		t.Errorf("date в UTC = %q, want 2024-04-30", got)
+	}
+	// Переданное значение заменяет встроенное
+	if got := templateValues(now, "Иван", map[string]string{"date": "завтра"})["date"]; got != "завтра" {
+		t.Errorf("date = %q, want переданное значение", got)
+	}
+}
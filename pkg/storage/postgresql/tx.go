@@ -0,0 +1,74 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/storage"
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	SerializationFailure = "40001"
+	DeadlockDetected     = "40P01"
+)
+
+// WithTx выполняет fn в одной транзакции с параметрами по умолчанию
+// Подробнее в WithTxOptions
+func (s *Storage) WithTx(fn func(storage.Interface) error) error {
+	return s.WithTxOptions(storage.TxOptions{}, fn)
+}
+
+// WithTxOptions выполняет fn в одной транзакции
+// Все методы storage.Interface, вызванные через переданное в fn хранилище, выполняются в этой транзакции,
+// а их внутренние транзакции (NewTask, UpdateTaskByID, DeleteTask) становятся точками сохранения
+// Если fn возвращает ошибку, то транзакция откатывается и ошибка возвращается вызывающему
+// При ошибке сериализации, взаимоблокировке или обрыве соединения до отправки запроса
+// вся транзакция (и fn) выполняется заново по политике повторов хранилища,
+// поэтому fn не должна иметь побочных эффектов вне БД
+// Вложенный вызов выполняется в точке сохранения внешней транзакции без повторов
+// Содержимое вложений, удаленных в транзакции, удаляется из хранилища содержимого после ее фиксации
+func (s *Storage) WithTxOptions(opts storage.TxOptions, fn func(storage.Interface) error) error {
+	if s.tx != nil {
+		return s.runTx(s.tx.Begin, fn)
+	}
+
+	attempts := opts.MaxAttempts
+	if attempts < 1 {
+		attempts = s.retry.MaxAttempts
+	}
+	begin := func(ctx context.Context) (pgx.Tx, error) {
+		return s.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.TxIsoLevel(opts.IsoLevel)})
+	}
+
+	c := *s
+	c.purgeAfterTx = new(bool)
+	err := s.withTxRetry(attempts, func() error {
+		return c.runTx(begin, fn)
+	})
+	if err == nil && *c.purgeAfterTx {
+		s.purgeBlobs()
+	}
+	return err
+}
+
+// runTx открывает транзакцию через begin, выполняет в ней fn и фиксирует результат
+func (s *Storage) runTx(begin func(context.Context) (pgx.Tx, error), fn func(storage.Interface) error) error {
+	tx, err := begin(s.ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(s.ctx)
+
+	txStorage := *s
+	txStorage.db = tx
+	txStorage.tx = tx
+	if err := fn(&txStorage); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(s.ctx); err != nil {
+		return fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+	}
+	return nil
+}
@@ -2,61 +2,228 @@ package postgresql
 
 import (
 	"DB_Apps/pkg/model"
-	"context"
+	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/names"
+	"DB_Apps/pkg/storage"
 	"errors"
 	"fmt"
+	"iter"
+	"net/mail"
+	"regexp"
 	"strings"
-	"unicode"
 
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 )
 
-// UserNameErr возвращается, если имя пользователя имеет неправильный формат
-var UserNameLangErr = errors.New("В имени допускается только Кириллица")
-var UserNameEmptyErr = errors.New("Пустое поле имени")
+// UserNameLangErr возвращается политикой names.CyrillicStrict, если имя содержит не кириллические символы
+// Остальные ошибки проверки имени описаны в пакете names, проверить их можно через names.IsInvalid
+var UserNameLangErr = names.CyrillicOnlyErr
+var UserNameEmptyErr = names.EmptyErr
 
-// NewUser создает нового пользователя в таблице users
-// Проверяет корректность имени, форматирует его и возвращает ID созданного пользователя
+// Ошибки проверки логина и email
+var UserLoginErr = errors.New("Логин должен состоять из 3-32 латинских букв, цифр и символов . _ - и начинаться с буквы или цифры")
+var UserEmailErr = errors.New("Некорректный адрес электронной почты")
+
+// UserRoleErr возвращается при неизвестной роли пользователя
+var UserRoleErr = errors.New("Неизвестная роль пользователя")
+
+// Ошибки удаления пользователя
+var (
+	UserHasTasksErr   = errors.New("Пользователь является автором или исполнителем задач")
+	DeleteStrategyErr = errors.New("Некорректная стратегия удаления пользователя")
+	DefaultUserErr    = errors.New("Пользователя по умолчанию нельзя удалить")
+)
+
+// Ошибки уникальности логина и email
+var DuplicateLoginErr = errors.New("Пользователь с таким логином уже существует")
+var DuplicateEmailErr = errors.New("Пользователь с таким email уже существует")
+
+// Столбцы пользователя в порядке scanUser
+const userColumns = `id, name, COALESCE(login, ''), COALESCE(email, ''), display_name, active, role`
+
+var loginRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{2,31}$`)
+
+// NewUser создает нового активного пользователя в таблице users
+// Проверяет корректность имени политикой хранилища (Options.NameValidator), форматирует его
+// Проверяет логин и email, если они заданы, и возвращает ID созданного пользователя
+// Если роль не задана, то пользователь создается с ролью model.RoleMember
+// Если логин или email уже заняты, то возвращает DuplicateLoginErr или DuplicateEmailErr
 func (s *Storage) NewUser(user model.User) (int, error) {
 	var id int
-	if err := checkName(user.Name); err != nil {
+	var err error
+	if user.Name, err = s.names.Normalize(user.Name); err != nil {
 		return 0, err
 	}
-	nameConversion(&user.Name)
+	if err := checkProfile(&user); err != nil {
+		return 0, err
+	}
+	if user.Role == "" {
+		user.Role = model.RoleMember
+	}
+	if !user.Role.Valid() {
+		return 0, fmt.Errorf("%w: %q", UserRoleErr, user.Role)
+	}
 
-	err := s.db.QueryRow(context.Background(), "INSERT INTO users(name) VALUES ($1) RETURNING id;", user.Name).Scan(&id)
+	err = s.db.QueryRow(s.ctx, `INSERT INTO users(name, login, email, display_name, role)
+								VALUES ($1, $2, $3, $4, $5) RETURNING id;`,
+		user.Name, nullIfEmpty(user.Login), nullIfEmpty(user.Email), user.DisplayName, user.Role).Scan(&id)
 	if err != nil {
-		return 0, err
+		return 0, userConstraintErr(err)
 	}
 	return id, nil
 }
 
-// DeleteUser удаляет пользователя по ID
+// DeleteUser удаляет пользователя по ID, поступая с его задачами по стратегии opts.Strategy:
+// - storage.DeleteReject (по умолчанию) - если пользователь автор или исполнитель задач, то возвращает UserHasTasksErr
+// - storage.DeleteReassign - задачи передаются пользователю opts.ReassignTo, который должен состоять в их проектах
+// - storage.DeleteUnassign - задачи передаются пользователю по умолчанию (ID 0)
+// Возвращает количество найденных (для DeleteReject) или переданных задач
+// Шаблоны задач и повторяющихся задач передаются по той же стратегии, а при DeleteReject - пользователю по умолчанию
+// Задачи всех проектов обрабатываются в одной транзакции, строка пользователя блокируется,
+// чтобы на него не были назначены новые задачи
 // Если пользователь не найден, то возвращает ошибку
-func (s *Storage) DeleteUser(id int) error {
-	r, err := s.db.Exec(context.Background(), "DELETE FROM users WHERE id = $1;", id)
+func (s *Storage) DeleteUser(id int, opts storage.DeleteUserOptions) (storage.DeleteUserReport, error) {
+	var report storage.DeleteUserReport
+	target, err := deleteTarget(id, opts)
 	if err != nil {
+		return report, err
+	}
+
+	// Пользователь общий для всех проектов, поэтому запросы не ограничиваются проектом хранилища
+	c := *s
+	c.ctx = projectContext(s.ctx, allProjects)
+	err = c.withTxRetry(s.retry.MaxAttempts, func() error {
+		var err error
+		report, err = c.deleteUser(id, opts.Strategy, target)
 		return err
+	})
+	return report, err
+}
+
+// deleteTarget проверяет удаляемого пользователя и стратегию DeleteUser и возвращает ID пользователя,
+// которому передаются задачи (0 - пользователь по умолчанию)
+// Пользователя по умолчанию удалить нельзя (DefaultUserErr), неизвестная стратегия
+// и передача задач самому удаляемому пользователю - DeleteStrategyErr
+func deleteTarget(id int, opts storage.DeleteUserOptions) (int, error) {
+	if id == 0 {
+		return 0, DefaultUserErr
 	}
-	if r.RowsAffected() == 0 {
-		return fmt.Errorf("Пользователя с ID: %d не найден", id)
+	switch opts.Strategy {
+	case "", storage.DeleteReject, storage.DeleteUnassign:
+		return 0, nil
+	case storage.DeleteReassign:
+		if opts.ReassignTo == id {
+			return 0, fmt.Errorf("%w: задачи нельзя передать удаляемому пользователю", DeleteStrategyErr)
+		}
+		return opts.ReassignTo, nil
 	}
-	return nil
+	return 0, fmt.Errorf("%w: %q", DeleteStrategyErr, opts.Strategy)
+}
+
+// deleteUser выполняет транзакцию DeleteUser без повторов
+func (s *Storage) deleteUser(id int, strategy storage.DeleteStrategy, target int) (storage.DeleteUserReport, error) {
+	var report storage.DeleteUserReport
+	tx, err := s.db.Begin(s.ctx)
+	if err != nil {
+		return report, err
+	}
+	defer tx.Rollback(s.ctx)
+
+	var locked int
+	err = tx.QueryRow(s.ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE;`, id).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return report, myerrors.NotFound("Пользователь с ID %d не найден", id)
+		}
+		return report, err
+	}
+
+	if strategy == storage.DeleteReassign {
+		var exists bool
+		err := tx.QueryRow(s.ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1);`, target).Scan(&exists)
+		if err != nil {
+			return report, fmt.Errorf("Ошибка при проверке пользователя %d: %w", target, err)
+		}
+		if !exists {
+			return report, myerrors.NotFound("Пользователь с ID %d, которому передаются задачи, не найден", target)
+		}
+
+		// Новый автор и исполнитель должен состоять во всех проектах, задачи которых ему передаются
+		var projectID int
+		err = tx.QueryRow(s.ctx, `SELECT project_id FROM tasks
+			WHERE (author_id = $1 OR assigned_id = $1) AND project_id <> $3
+				AND project_id NOT IN (SELECT project_id FROM project_members WHERE user_id = $2)
+			LIMIT 1;`, id, target, DefaultProjectID).Scan(&projectID)
+		if err == nil && target != 0 {
+			return report, fmt.Errorf("%w: пользователь с ID %d, проект с ID %d", NotProjectMemberErr, target, projectID)
+		}
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return report, fmt.Errorf("Ошибка при проверке участников проектов: %w", err)
+		}
+	}
+
+	if strategy == "" || strategy == storage.DeleteReject {
+		err := tx.QueryRow(s.ctx, `SELECT count(*) FILTER (WHERE author_id = $1),
+				count(*) FILTER (WHERE assigned_id = $1)
+			FROM tasks WHERE author_id = $1 OR assigned_id = $1;`, id).Scan(&report.Authored, &report.Assigned)
+		if err != nil {
+			return report, fmt.Errorf("Ошибка при подсчете задач пользователя %d: %w", id, err)
+		}
+		if report.Authored > 0 || report.Assigned > 0 {
+			return report, fmt.Errorf("%w: автор %d, исполнитель %d", UserHasTasksErr, report.Authored, report.Assigned)
+		}
+	} else {
+		r, err := tx.Exec(s.ctx, `UPDATE tasks SET author_id = $2 WHERE author_id = $1;`, id, target)
+		if err != nil {
+			return report, fmt.Errorf("Ошибка при передаче задач пользователя %d: %w", id, err)
+		}
+		report.Authored = int(r.RowsAffected())
+		r, err = tx.Exec(s.ctx, `UPDATE tasks SET assigned_id = $2 WHERE assigned_id = $1;`, id, target)
+		if err != nil {
+			return report, fmt.Errorf("Ошибка при передаче задач пользователя %d: %w", id, err)
+		}
+		report.Assigned = int(r.RowsAffected())
+		// Шаблоны повторяющихся задач передаются тому же пользователю
+		_, err = tx.Exec(s.ctx, `UPDATE recurring_tasks
+			SET author_id = CASE WHEN author_id = $1 THEN $2 ELSE author_id END,
+				assigned_id = CASE WHEN assigned_id = $1 THEN $2 ELSE assigned_id END
+			WHERE author_id = $1 OR assigned_id = $1;`, id, target)
+		if err != nil {
+			return report, fmt.Errorf("Ошибка при передаче повторяющихся задач пользователя %d: %w", id, err)
+		}
+		_, err = tx.Exec(s.ctx, `UPDATE task_templates SET assigned_id = $2 WHERE assigned_id = $1;`, id, target)
+		if err != nil {
+			return report, fmt.Errorf("Ошибка при передаче шаблонов задач пользователя %d: %w", id, err)
+		}
+	}
+
+	if _, err := tx.Exec(s.ctx, "DELETE FROM users WHERE id = $1;", id); err != nil {
+		return report, fmt.Errorf("Ошибка при удалении пользователя %d: %w", id, err)
+	}
+	if err := tx.Commit(s.ctx); err != nil {
+		return report, fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+	}
+	return report, nil
 }
 
 // SelectUsers возвращает список всех пользователей, отсортированных по ID
 // Если пользователей нет, то возвращает пустой срез и ошибку
 func (s *Storage) SelectUsers() ([]model.User, error) {
+	return retryValue(s, s.selectUsers)
+}
+
+// selectUsers выполняет запрос SelectUsers без повторов
+func (s *Storage) selectUsers() ([]model.User, error) {
 	var users []model.User
-	rows, err := s.db.Query(context.Background(), "SELECT id, name FROM users ORDER BY id ASC;")
+	rows, err := s.db.Query(s.ctx, "SELECT "+userColumns+" FROM users ORDER BY id ASC;")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var user model.User
-		err := rows.Scan(&user.ID, &user.Name)
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -69,67 +236,196 @@ func (s *Storage) SelectUsers() ([]model.User, error) {
 
 }
 
+// IterUsers возвращает итератор по всем пользователям, отсортированным по ID
+// В отличие от SelectUsers не загружает всю таблицу в память
+func (s *Storage) IterUsers() iter.Seq2[model.User, error] {
+	return queryIter(s, func(rows pgx.Rows) (model.User, error) {
+		return scanUser(rows)
+	}, "SELECT "+userColumns+" FROM users ORDER BY id ASC;")
+}
+
 // SelectUserByID возвращает пользователя по ID
 // Если пользователь не найден, то возвращает ошибку
 func (s *Storage) SelectUserByID(id int) (model.User, error) {
-	var user model.User
-	err := s.db.QueryRow(context.Background(), "SELECT id, name FROM users WHERE id = $1;", id).Scan(&user.ID, &user.Name)
+	return retryValue(s, func() (model.User, error) {
+		return s.selectUserByID(id)
+	})
+}
+
+// selectUserByID выполняет запрос SelectUserByID без повторов
+func (s *Storage) selectUserByID(id int) (model.User, error) {
+	user, err := scanUser(s.db.QueryRow(s.ctx, "SELECT "+userColumns+" FROM users WHERE id = $1;", id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return user, fmt.Errorf("Пользователь с ID %d не найден", id)
+			return user, myerrors.NotFound("Пользователь с ID %d не найден", id)
 		}
 		return user, err
 	}
 	return user, nil
 }
 
+// SelectUserByLogin возвращает пользователя по логину без учета регистра
+// Если пользователь не найден, то возвращает ошибку
+func (s *Storage) SelectUserByLogin(login string) (model.User, error) {
+	login = strings.ToLower(strings.TrimSpace(login))
+	return retryValue(s, func() (model.User, error) {
+		user, err := scanUser(s.db.QueryRow(s.ctx,
+			"SELECT "+userColumns+" FROM users WHERE lower(login) = $1;", login))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, myerrors.NotFound("Пользователь с логином %q не найден", login)
+		}
+		return user, err
+	})
+}
+
+// SelectUserByEmail возвращает пользователя по email без учета регистра
+// Если пользователь не найден, то возвращает ошибку
+func (s *Storage) SelectUserByEmail(email string) (model.User, error) {
+	email = strings.TrimSpace(email)
+	return retryValue(s, func() (model.User, error) {
+		user, err := scanUser(s.db.QueryRow(s.ctx,
+			"SELECT "+userColumns+" FROM users WHERE lower(email) = lower($1);", email))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, myerrors.NotFound("Пользователь с email %q не найден", email)
+		}
+		return user, err
+	})
+}
+
+// SelectUsersByIDs возвращает пользователей с ID из ids, отсортированных по ID
+// Несуществующие ID пропускаются, поэтому пользователей может быть меньше, чем ids
+func (s *Storage) SelectUsersByIDs(ids []int) ([]model.User, error) {
+	return retryValue(s, func() ([]model.User, error) {
+		return collect(s, func(rows pgx.Rows) (model.User, error) {
+			return scanUser(rows)
+		}, "SELECT "+userColumns+" FROM users WHERE id = ANY($1) ORDER BY id ASC;", ids)
+	})
+}
+
 // UpdateUserName изменяет имя пользователя по ID
 // Проверяет корректность имени и форматирует его
 // Если пользователь не найден, то возвращает ошибку
 func (s *Storage) UpdateUserName(id int, newName string) error {
-	if err := checkName(newName); err != nil {
+	newName, err := s.names.Normalize(newName)
+	if err != nil {
+		return err
+	}
+	r, err := s.db.Exec(s.ctx, "UPDATE users SET name = $1 WHERE id = $2;", newName, id)
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Пользователь с ID %d не найден", id)
+	}
+	return nil
+}
+
+// UpdateUserProfile изменяет логин, email и отображаемое имя пользователя по user.ID
+// Имя, роль и признак активности пользователя не меняются, для этого используются UpdateUserName, SetUserRole
+// и SetUserActive
+// Если пользователь не найден, то возвращает ошибку
+func (s *Storage) UpdateUserProfile(user model.User) error {
+	if err := checkProfile(&user); err != nil {
 		return err
 	}
-	nameConversion(&newName)
-	r, err := s.db.Exec(context.Background(), "UPDATE users SET name = $1 WHERE id = $2;", newName, id)
+	r, err := s.db.Exec(s.ctx, `UPDATE users
+		SET login = $1,
+			email = $2,
+			display_name = $3
+		WHERE id = $4;`,
+		nullIfEmpty(user.Login), nullIfEmpty(user.Email), user.DisplayName, user.ID)
+	if err != nil {
+		return userConstraintErr(err)
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Пользователь с ID %d не найден", user.ID)
+	}
+	return nil
+}
+
+// SetUserRole изменяет роль пользователя по ID
+// Если роль неизвестна, то возвращает UserRoleErr, если пользователь не найден - ошибку NotFound
+func (s *Storage) SetUserRole(id int, role model.Role) error {
+	if !role.Valid() {
+		return fmt.Errorf("%w: %q", UserRoleErr, role)
+	}
+	r, err := s.db.Exec(s.ctx, "UPDATE users SET role = $1 WHERE id = $2;", role, id)
 	if err != nil {
 		return err
 	}
 	if r.RowsAffected() == 0 {
-		return fmt.Errorf("Пользователь с ID %d не найден", id)
+		return myerrors.NotFound("Пользователь с ID %d не найден", id)
 	}
 	return nil
 }
 
-// checkName проверяет корректность имени пользователя:
-// - Имя должно состоять только из кириллических символов и пробелов
-// - Возвращает false, если имя пустое или содержит недопустимые символы
-func checkName(name string) error {
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return UserNameEmptyErr
+// SetUserActive блокирует (active = false) или разблокирует пользователя по ID
+// Заблокированный пользователь не может войти, выданные ему токены перестают действовать
+// Если пользователь не найден, то возвращает ошибку NotFound
+func (s *Storage) SetUserActive(id int, active bool) error {
+	r, err := s.db.Exec(s.ctx, "UPDATE users SET active = $1 WHERE id = $2;", active, id)
+	if err != nil {
+		return err
 	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Пользователь с ID %d не найден", id)
+	}
+	return nil
+}
+
+// scanUser считывает строку со столбцами userColumns в пользователя
+func scanUser(row pgx.Row) (model.User, error) {
+	var user model.User
+	err := row.Scan(&user.ID, &user.Name, &user.Login, &user.Email, &user.DisplayName, &user.Active, &user.Role)
+	return user, err
+}
 
-	for _, w := range name {
-		if !(w >= 'а' && w <= 'я' || w >= 'А' && w <= 'Я' || w == ' ') {
-			return UserNameLangErr
+// checkProfile проверяет и нормализует логин, email и отображаемое имя:
+// - логин приводится к нижнему регистру и проверяется по loginRe
+// - email должен быть одиночным адресом без имени, домен приводится к нижнему регистру
+// - в отображаемом имени удаляются лишние пробелы
+// Пустые логин и email допускаются
+func checkProfile(user *model.User) error {
+	user.Login = strings.ToLower(strings.TrimSpace(user.Login))
+	if user.Login != "" && !loginRe.MatchString(user.Login) {
+		return UserLoginErr
+	}
+
+	user.Email = strings.TrimSpace(user.Email)
+	if user.Email != "" {
+		addr, err := mail.ParseAddress(user.Email)
+		if err != nil || addr.Name != "" || addr.Address != user.Email {
+			return fmt.Errorf("%w: %s", UserEmailErr, user.Email)
+		}
+		local, domain, _ := strings.Cut(addr.Address, "@")
+		if !strings.Contains(domain, ".") {
+			return fmt.Errorf("%w: %s", UserEmailErr, user.Email)
 		}
+		user.Email = local + "@" + strings.ToLower(domain)
 	}
 
+	user.DisplayName = strings.Join(strings.Fields(user.DisplayName), " ")
 	return nil
 }
 
-// nameConversion форматирует имя пользователя:
-// - Приводит его к виду, что каждое слово с заглавной буквы
-func nameConversion(name *string) {
-	*name = strings.ToLower(*name)
-	if len(*name) > 0 {
-		words := strings.Fields(*name)
-		for i := range words {
-			runnes := []rune(words[i])
-			runnes[0] = unicode.ToUpper(runnes[0])
-			words[i] = string(runnes)
+// userConstraintErr заменяет нарушение уникальности логина или email на DuplicateLoginErr и DuplicateEmailErr
+func userConstraintErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == UniqueViolation {
+		switch pgErr.ConstraintName {
+		case "users_login_key":
+			return DuplicateLoginErr
+		case "users_email_key":
+			return DuplicateEmailErr
 		}
-		*name = strings.Join(words, " ")
 	}
+	return err
+}
+
+// nullIfEmpty возвращает nil для пустой строки, чтобы в БД записался NULL
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
 }
@@ -0,0 +1,128 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage"
+	"errors"
+	"testing"
+)
+
+func TestDeleteTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      int
+		opts    storage.DeleteUserOptions
+		want    int
+		wantErr error
+	}{
+		{name: "стратегия по умолчанию", id: 5},
+		{name: "отказ", id: 5, opts: storage.DeleteUserOptions{Strategy: storage.DeleteReject}},
+		{name: "пользователю по умолчанию", id: 5, opts: storage.DeleteUserOptions{Strategy: storage.DeleteUnassign, ReassignTo: 7}},
+		{name: "другому пользователю", id: 5, opts: storage.DeleteUserOptions{Strategy: storage.DeleteReassign, ReassignTo: 7}, want: 7},
+		{name: "передача без получателя", id: 5, opts: storage.DeleteUserOptions{Strategy: storage.DeleteReassign}},
+		{
+			name:    "передача удаляемому пользователю",
+			id:      5,
+			opts:    storage.DeleteUserOptions{Strategy: storage.DeleteReassign, ReassignTo: 5},
+			wantErr: DeleteStrategyErr,
+		},
+		{name: "неизвестная стратегия", id: 5, opts: storage.DeleteUserOptions{Strategy: "archive"}, wantErr: DeleteStrategyErr},
+		{name: "стратегия в другом регистре", id: 5, opts: storage.DeleteUserOptions{Strategy: "Reject"}, wantErr: DeleteStrategyErr},
+		{name: "пользователь по умолчанию", id: 0, wantErr: DefaultUserErr},
+		{
+			name:    "пользователь по умолчанию с передачей",
+			id:      0,
+			opts:    storage.DeleteUserOptions{Strategy: storage.DeleteReassign, ReassignTo: 7},
+			wantErr: DefaultUserErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := deleteTarget(tt.id, tt.opts)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("deleteTarget() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("deleteTarget() = %d, want %d", got, tt.want)
+			}
+			// Некорректные параметры отклоняются до обращения к БД
+			if tt.wantErr != nil {
+				if _, err := (&Storage{}).DeleteUser(tt.id, tt.opts); !errors.Is(err, tt.wantErr) {
+					t.Errorf("DeleteUser() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteUserStrategies(t *testing.T) {
+	s, _ := testStorage(t)
+	newUser := func(name string) int {
+		t.Helper()
+		id, err := s.NewUser(model.User{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	author, assignee, heir := newUser("Иван"), newUser("Петр"), newUser("Мария")
+	taskID, err := s.NewTask(model.Task{Title: "Задача", AuthorID: author, AssignedID: assignee})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := s.DeleteUser(author, storage.DeleteUserOptions{})
+	if !errors.Is(err, UserHasTasksErr) || report.Authored != 1 || report.Assigned != 0 {
+		t.Errorf("DeleteUser(отказ) = %+v, %v, want 1 задачу автора и UserHasTasksErr", report, err)
+	}
+
+	report, err = s.DeleteUser(author, storage.DeleteUserOptions{Strategy: storage.DeleteReassign, ReassignTo: heir})
+	if err != nil || report.Authored != 1 {
+		t.Errorf("DeleteUser(передача) = %+v, %v, want 1 переданную задачу", report, err)
+	}
+	report, err = s.DeleteUser(assignee, storage.DeleteUserOptions{Strategy: storage.DeleteUnassign})
+	if err != nil || report.Assigned != 1 {
+		t.Errorf("DeleteUser(пользователю по умолчанию) = %+v, %v, want 1 переданную задачу", report, err)
+	}
+
+	task, err := s.SelectTaskByID(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.AuthorID != heir || task.AssignedID != 0 {
+		t.Errorf("автор %d, исполнитель %d, want %d, 0", task.AuthorID, task.AssignedID, heir)
+	}
+	if _, err := s.DeleteUser(author, storage.DeleteUserOptions{}); err == nil {
+		t.Error("повторный DeleteUser() error = nil")
+	}
+}
+
+// Изменение профиля не блокирует пользователя, даже если признак активности в нем не заполнен
+func TestUpdateUserProfileKeepsActive(t *testing.T) {
+	s, _ := testStorage(t)
+	id, err := s.NewUser(model.User{Name: "Иван"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.UpdateUserProfile(model.User{ID: id, Login: "ivan", DisplayName: "Ваня"}); err != nil {
+		t.Fatal(err)
+	}
+	user, err := s.SelectUserByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.Active || user.Login != "ivan" || user.DisplayName != "Ваня" {
+		t.Errorf("пользователь после UpdateUserProfile: %+v", user)
+	}
+
+	if err := s.SetUserActive(id, false); err != nil {
+		t.Fatal(err)
+	}
+	if user, err = s.SelectUserByID(id); err != nil || user.Active {
+		t.Errorf("пользователь после SetUserActive(false): %+v, %v", user, err)
+	}
+	if err := s.SetUserActive(id+1, false); err == nil {
+		t.Error("SetUserActive(несуществующий пользователь) error = nil")
+	}
+}
@@ -0,0 +1,256 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// Ошибки сохраненных представлений
+var (
+	ViewNameErr      = errors.New("Пустая строка не может быть названием представления")
+	ViewFilterErr    = errors.New("Некорректный фильтр задач")
+	DuplicateViewErr = errors.New("Представление с таким названием уже существует у пользователя")
+)
+
+// Столбцы представления в порядке scanView
+const viewColumns = `id, project_id, owner_id, name, filter, shared`
+
+// Выражения ORDER BY для порядков сортировки фильтра, при равенстве задачи упорядочиваются по ID
+var taskSortOrders = map[model.TaskSort]string{
+	"":                   "tasks.id ASC",
+	model.SortID:         "tasks.id ASC",
+	model.SortIDDesc:     "tasks.id DESC",
+	model.SortOpened:     "tasks.opened ASC, tasks.id ASC",
+	model.SortOpenedDesc: "tasks.opened DESC, tasks.id DESC",
+	model.SortClosed:     "tasks.closed ASC NULLS LAST, tasks.id ASC",
+	model.SortClosedDesc: "tasks.closed DESC NULLS LAST, tasks.id DESC",
+	model.SortTitle:      "lower(tasks.title) ASC, tasks.id ASC",
+	model.SortTitleDesc:  "lower(tasks.title) DESC, tasks.id DESC",
+}
+
+// NewSavedView создает представление пользователя v.OwnerID в проекте хранилища и возвращает его ID
+// Владелец должен состоять в проекте, фильтр проверяется так же, как в SelectTasksByFilter
+// Если у владельца уже есть представление с таким названием, то возвращает DuplicateViewErr
+func (s *Storage) NewSavedView(v model.SavedView) (int, error) {
+	filter, err := checkView(&v)
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	err = s.withTxRetry(s.retry.MaxAttempts, func() error {
+		tx, err := s.db.Begin(s.ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(s.ctx)
+
+		if err := s.checkMembers(tx, v.OwnerID); err != nil {
+			return err
+		}
+		err = tx.QueryRow(s.ctx, `INSERT INTO saved_views(project_id, owner_id, name, filter, shared)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id;`, s.project, v.OwnerID, v.Name, filter, v.Shared).Scan(&id)
+		if err != nil {
+			return viewError(err, v)
+		}
+
+		if err := tx.Commit(s.ctx); err != nil {
+			return fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+		}
+		return nil
+	})
+	return id, err
+}
+
+// UpdateSavedView изменяет название, фильтр и видимость представления по v.ID, владелец не меняется
+// Если представление не найдено, то возвращает ошибку
+func (s *Storage) UpdateSavedView(v model.SavedView) error {
+	filter, err := checkView(&v)
+	if err != nil {
+		return err
+	}
+	return s.withRetry(func() error {
+		r, err := s.db.Exec(s.ctx, `UPDATE saved_views SET name = $3, filter = $4, shared = $5
+			WHERE id = $1 AND project_id = $2;`, v.ID, s.project, v.Name, filter, v.Shared)
+		if err != nil {
+			return viewError(err, v)
+		}
+		if r.RowsAffected() == 0 {
+			return myerrors.NotFound("Представление с ID %d не найдено", v.ID)
+		}
+		return nil
+	})
+}
+
+// DeleteSavedView удаляет представление
+// Если представление не найдено, то возвращает ошибку
+func (s *Storage) DeleteSavedView(id int) error {
+	r, err := s.db.Exec(s.ctx, `DELETE FROM saved_views WHERE id = $1 AND project_id = $2;`, id, s.project)
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Представление с ID %d не найдено", id)
+	}
+	return nil
+}
+
+// SelectSavedViews возвращает представления проекта, доступные пользователю ownerID:
+// его собственные и общие представления других пользователей, отсортированные по названию
+func (s *Storage) SelectSavedViews(ownerID int) ([]model.SavedView, error) {
+	return retryValue(s, func() ([]model.SavedView, error) {
+		return collect(s, func(rows pgx.Rows) (model.SavedView, error) {
+			return scanView(rows)
+		}, "SELECT "+viewColumns+` FROM saved_views
+			WHERE project_id = $1 AND (owner_id = $2 OR shared)
+			ORDER BY owner_id <> $2, lower(name), id;`, s.project, ownerID)
+	})
+}
+
+// SelectSavedViewByID возвращает представление проекта по ID
+// Если представление не найдено, то возвращает ошибку
+func (s *Storage) SelectSavedViewByID(id int) (model.SavedView, error) {
+	return retryValue(s, func() (model.SavedView, error) {
+		v, err := scanView(s.db.QueryRow(s.ctx, "SELECT "+viewColumns+` FROM saved_views
+			WHERE id = $1 AND project_id = $2;`, id, s.project))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return v, myerrors.NotFound("Представление с ID %d не найдено", id)
+		}
+		return v, err
+	})
+}
+
+// ExecuteSavedView возвращает задачи проекта, подходящие под фильтр представления id
+// Если представление не найдено, то возвращает ошибку
+func (s *Storage) ExecuteSavedView(id int) ([]model.Task, error) {
+	v, err := s.SelectSavedViewByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.SelectTasksByFilter(v.Filter)
+}
+
+// SelectTasksByFilter возвращает задачи проекта, подходящие под фильтр f, в порядке f.Sort
+// Если в фильтре неизвестное состояние или порядок сортировки, то возвращает ViewFilterErr
+func (s *Storage) SelectTasksByFilter(f model.TaskFilter) ([]model.Task, error) {
+	sql, args, err := s.filterQuery(f)
+	if err != nil {
+		return nil, err
+	}
+	return retryValue(s, func() ([]model.Task, error) {
+		return s.queryTasks(sql, args...)
+	})
+}
+
+// filterQuery строит запрос задач проекта по фильтру f и его аргументы
+func (s *Storage) filterQuery(f model.TaskFilter) (string, []any, error) {
+	order, err := checkFilter(f)
+	if err != nil {
+		return "", nil, err
+	}
+	args := []any{s.project}
+	where := []string{"tasks.project_id = $1"}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if f.AuthorID != nil {
+		where = append(where, "tasks.author_id = "+arg(*f.AuthorID))
+	}
+	if f.AssignedID != nil {
+		where = append(where, "tasks.assigned_id = "+arg(*f.AssignedID))
+	}
+	if labels := uniqueIDs(f.LabelsID); len(labels) > 0 {
+		where = append(where, "tasks.id IN (SELECT task_id FROM tasks_labels WHERE label_id = ANY("+arg(labels)+
+			") GROUP BY task_id HAVING count(*) = "+arg(len(labels))+")")
+	}
+	switch f.State {
+	case model.StateOpen:
+		where = append(where, "tasks.closed IS NULL")
+	case model.StateClosed:
+		where = append(where, "tasks.closed IS NOT NULL")
+	}
+	if q := strings.TrimSpace(f.Query); q != "" {
+		pattern := arg("%" + likeEscaper.Replace(q) + "%")
+		where = append(where, "(tasks.title ILIKE "+pattern+" OR tasks.content ILIKE "+pattern+")")
+	}
+
+	return "SELECT " + taskColumns + " FROM tasks WHERE " + strings.Join(where, " AND ") +
+		" ORDER BY " + order + ";", args, nil
+}
+
+// Экранирование спецсимволов шаблона LIKE в тексте поиска
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// uniqueIDs возвращает ID из ids без повторов в исходном порядке
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	var res []int
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			res = append(res, id)
+		}
+	}
+	return res
+}
+
+// checkFilter проверяет состояние и порядок сортировки фильтра и возвращает выражение ORDER BY
+func checkFilter(f model.TaskFilter) (string, error) {
+	switch f.State {
+	case model.StateAll, model.StateOpen, model.StateClosed:
+	default:
+		return "", fmt.Errorf("%w: неизвестное состояние %q", ViewFilterErr, f.State)
+	}
+	order, ok := taskSortOrders[f.Sort]
+	if !ok {
+		return "", fmt.Errorf("%w: неизвестный порядок сортировки %q", ViewFilterErr, f.Sort)
+	}
+	return order, nil
+}
+
+// checkView очищает название представления от лишних пробелов, проверяет название и фильтр
+// и возвращает фильтр в формате JSON
+func checkView(v *model.SavedView) ([]byte, error) {
+	v.Name = strings.Join(strings.Fields(v.Name), " ")
+	v.Filter.Query = strings.TrimSpace(v.Filter.Query)
+	if v.Name == "" {
+		return nil, ViewNameErr
+	}
+	if _, err := checkFilter(v.Filter); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v.Filter)
+}
+
+// viewError преобразует ошибку сохранения представления v в ошибку хранилища
+func viewError(err error, v model.SavedView) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == UniqueViolation {
+		return fmt.Errorf("%w: %s", DuplicateViewErr, v.Name)
+	}
+	if errors.As(err, &pgErr) && pgErr.Code == ForeignKeyViolation {
+		return myerrors.NotFound("Владелец с ID %d не найден", v.OwnerID)
+	}
+	return err
+}
+
+// scanView считывает строку со столбцами viewColumns в представление
+func scanView(row pgx.Row) (model.SavedView, error) {
+	var v model.SavedView
+	var filter []byte
+	if err := row.Scan(&v.ID, &v.ProjectID, &v.OwnerID, &v.Name, &filter, &v.Shared); err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(filter, &v.Filter); err != nil {
+		return v, fmt.Errorf("Ошибка чтения фильтра представления %d: %w", v.ID, err)
+	}
+	return v, nil
+}
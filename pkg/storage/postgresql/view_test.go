@@ -0,0 +1,124 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFilterQuery(t *testing.T) {
+	author, assigned := 3, 0
+	const selectTasks = "SELECT " + taskColumns + " FROM tasks WHERE "
+	tests := []struct {
+		name     string
+		filter   model.TaskFilter
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "пустой фильтр",
+			wantSQL:  "tasks.project_id = $1 ORDER BY tasks.id ASC;",
+			wantArgs: []any{7},
+		},
+		{
+			name:     "автор и исполнитель по умолчанию",
+			filter:   model.TaskFilter{AuthorID: &author, AssignedID: &assigned},
+			wantSQL:  "tasks.project_id = $1 AND tasks.author_id = $2 AND tasks.assigned_id = $3 ORDER BY tasks.id ASC;",
+			wantArgs: []any{7, 3, 0},
+		},
+		{
+			name:   "все метки без повторов",
+			filter: model.TaskFilter{LabelsID: []int{4, 2, 4}},
+			wantSQL: "tasks.project_id = $1 AND tasks.id IN (SELECT task_id FROM tasks_labels WHERE label_id = ANY($2) " +
+				"GROUP BY task_id HAVING count(*) = $3) ORDER BY tasks.id ASC;",
+			wantArgs: []any{7, []int{4, 2}, 2},
+		},
+		{
+			name:     "открытые по убыванию даты",
+			filter:   model.TaskFilter{State: model.StateOpen, Sort: model.SortOpenedDesc},
+			wantSQL:  "tasks.project_id = $1 AND tasks.closed IS NULL ORDER BY tasks.opened DESC, tasks.id DESC;",
+			wantArgs: []any{7},
+		},
+		{
+			name:     "закрытые по дате закрытия",
+			filter:   model.TaskFilter{State: model.StateClosed, Sort: model.SortClosed},
+			wantSQL:  "tasks.project_id = $1 AND tasks.closed IS NOT NULL ORDER BY tasks.closed ASC NULLS LAST, tasks.id ASC;",
+			wantArgs: []any{7},
+		},
+		{
+			name:     "поиск",
+			filter:   model.TaskFilter{Query: "  отчет ", Sort: model.SortTitle},
+			wantSQL:  "tasks.project_id = $1 AND (tasks.title ILIKE $2 OR tasks.content ILIKE $2) ORDER BY lower(tasks.title) ASC, tasks.id ASC;",
+			wantArgs: []any{7, "%отчет%"},
+		},
+		{
+			name:     "экранирование LIKE",
+			filter:   model.TaskFilter{Query: `100% a_b c\d`},
+			wantSQL:  "tasks.project_id = $1 AND (tasks.title ILIKE $2 OR tasks.content ILIKE $2) ORDER BY tasks.id ASC;",
+			wantArgs: []any{7, `%100\% a\_b c\\d%`},
+		},
+		{
+			name:     "пустой поиск",
+			filter:   model.TaskFilter{Query: "   "},
+			wantSQL:  "tasks.project_id = $1 ORDER BY tasks.id ASC;",
+			wantArgs: []any{7},
+		},
+		{
+			name: "все условия",
+			filter: model.TaskFilter{AuthorID: &author, LabelsID: []int{1}, State: model.StateOpen, Query: "x",
+				Sort: model.SortIDDesc},
+			wantSQL: "tasks.project_id = $1 AND tasks.author_id = $2 AND tasks.id IN (SELECT task_id FROM tasks_labels " +
+				"WHERE label_id = ANY($3) GROUP BY task_id HAVING count(*) = $4) AND tasks.closed IS NULL " +
+				"AND (tasks.title ILIKE $5 OR tasks.content ILIKE $5) ORDER BY tasks.id DESC;",
+			wantArgs: []any{7, 3, []int{1}, 1, "%x%"},
+		},
+	}
+	s := &Storage{project: 7}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := s.filterQuery(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := selectTasks + tt.wantSQL; sql != want {
+				t.Errorf("SQL:\n%s\nwant:\n%s", sql, want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCheckFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  model.TaskFilter
+		wantErr bool
+	}{
+		{name: "пустой фильтр", filter: model.TaskFilter{}},
+		{name: "открытые по заголовку", filter: model.TaskFilter{State: model.StateOpen, Sort: model.SortTitleDesc}},
+		{name: "неизвестное состояние", filter: model.TaskFilter{State: "archived"}, wantErr: true},
+		{name: "неизвестный порядок", filter: model.TaskFilter{Sort: "priority"}, wantErr: true},
+		{name: "SQL в порядке сортировки", filter: model.TaskFilter{Sort: "id; DROP TABLE tasks"}, wantErr: true},
+		{name: "порядок в другом регистре", filter: model.TaskFilter{Sort: "ID"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := checkFilter(tt.filter)
+			if tt.wantErr {
+				if !errors.Is(err, ViewFilterErr) {
+					t.Errorf("checkFilter() error = %v, want ViewFilterErr", err)
+				}
+				if _, _, err := (&Storage{}).filterQuery(tt.filter); !errors.Is(err, ViewFilterErr) {
+					t.Errorf("filterQuery() error = %v, want ViewFilterErr", err)
+				}
+				return
+			}
+			if err != nil || order == "" {
+				t.Errorf("checkFilter() = %q, %v", order, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package postgresql
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Ошибки учета времени
+var (
+	TaskEstimateErr    = errors.New("Оценка задачи не может быть отрицательной")
+	WorklogDurationErr = errors.New("Затраченное время должно быть больше нуля и не больше суток")
+)
+
+// Наибольшая продолжительность одной записи о времени
+const MaxWorklogDuration = 24 * time.Hour
+
+// Столбцы записи о времени в порядке scanWorklog
+const worklogColumns = `worklogs.id, worklogs.task_id, worklogs.user_id, worklogs.started, worklogs.duration_s,
+	worklogs.comment`
+
+// NewWorklog добавляет запись о времени, затраченном пользователем w.UserID на задачу w.TaskID, и возвращает ее ID
+// Задача должна быть в проекте хранилища, пользователь - состоять в нем
+// Если начало не задано, то работа считается законченной в момент записи
+// Продолжительность округляется до секунд и должна быть в пределах (0, MaxWorklogDuration], иначе - WorklogDurationErr
+// Участники проекта, упомянутые в комментарии как @Имя, получают уведомление
+func (s *Storage) NewWorklog(w model.Worklog) (int, error) {
+	w.Duration = w.Duration.Truncate(time.Second)
+	w.Comment = strings.TrimSpace(w.Comment)
+	if w.Duration <= 0 || w.Duration > MaxWorklogDuration {
+		return 0, WorklogDurationErr
+	}
+	if w.Started.IsZero() {
+		w.Started = time.Now().Add(-w.Duration)
+	}
+
+	var id int
+	err := s.withTxRetry(s.retry.MaxAttempts, func() error {
+		tx, err := s.db.Begin(s.ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(s.ctx)
+
+		task := model.Task{ID: w.TaskID, ProjectID: s.project}
+		err = tx.QueryRow(s.ctx, `SELECT title FROM tasks WHERE id = $1 AND project_id = $2 FOR SHARE;`,
+			w.TaskID, s.project).Scan(&task.Title)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return myerrors.NotFound("Задача с ID %d не найдена", w.TaskID)
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.checkMembers(tx, w.UserID); err != nil {
+			return err
+		}
+		err = tx.QueryRow(s.ctx, `INSERT INTO worklogs(task_id, user_id, started, duration_s, comment)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id;`,
+			w.TaskID, w.UserID, w.Started, int64(w.Duration/time.Second), w.Comment).Scan(&id)
+		if err != nil {
+			return err
+		}
+		n := s.newNotifications(task, w.UserID)
+		if err := s.addMentions(tx, n, w.Comment, "", "Вас упомянули в комментарии к записи о времени"); err != nil {
+			return err
+		}
+		if err := s.send(tx, n); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(s.ctx); err != nil {
+			return fmt.Errorf("Ошибка при сохранении результатов транзакции: %w", err)
+		}
+		return nil
+	})
+	return id, err
+}
+
+// DeleteWorklog удаляет запись о времени по задаче проекта
+// Если запись не найдена, то возвращает ошибку
+func (s *Storage) DeleteWorklog(id int) error {
+	r, err := s.db.Exec(s.ctx, `DELETE FROM worklogs USING tasks
+		WHERE worklogs.id = $1 AND tasks.id = worklogs.task_id AND tasks.project_id = $2;`, id, s.project)
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Запись о времени с ID %d не найдена", id)
+	}
+	return nil
+}
+
+// SelectWorklogByID возвращает запись о времени по задаче проекта
+// Если запись не найдена, то возвращает ошибку
+func (s *Storage) SelectWorklogByID(id int) (model.Worklog, error) {
+	return retryValue(s, func() (model.Worklog, error) {
+		w, err := scanWorklog(s.db.QueryRow(s.ctx, "SELECT "+worklogColumns+` FROM worklogs
+			JOIN tasks ON tasks.id = worklogs.task_id
+			WHERE worklogs.id = $1 AND tasks.project_id = $2;`, id, s.project))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return w, myerrors.NotFound("Запись о времени с ID %d не найдена", id)
+		}
+		return w, err
+	})
+}
+
+// SelectWorklogs возвращает записи о времени по задаче проекта, отсортированные по началу работы
+func (s *Storage) SelectWorklogs(taskID int) ([]model.Worklog, error) {
+	return retryValue(s, func() ([]model.Worklog, error) {
+		return collect(s, func(rows pgx.Rows) (model.Worklog, error) {
+			return scanWorklog(rows)
+		}, "SELECT "+worklogColumns+` FROM worklogs
+			JOIN tasks ON tasks.id = worklogs.task_id
+			WHERE worklogs.task_id = $1 AND tasks.project_id = $2
+			ORDER BY worklogs.started, worklogs.id;`, taskID, s.project)
+	})
+}
+
+// SetTaskEstimate задает оценку задачи проекта, 0 - оценка не задана
+// Оценка округляется до секунд, отрицательная оценка - TaskEstimateErr
+// Если задача не найдена, то возвращает ошибку
+func (s *Storage) SetTaskEstimate(taskID int, estimate time.Duration) error {
+	if estimate < 0 {
+		return TaskEstimateErr
+	}
+	r, err := s.db.Exec(s.ctx, `UPDATE tasks SET estimate_s = $3 WHERE id = $1 AND project_id = $2;`,
+		taskID, s.project, int64(estimate/time.Second))
+	if err != nil {
+		return err
+	}
+	if r.RowsAffected() == 0 {
+		return myerrors.NotFound("Задача с ID %d не найдена", taskID)
+	}
+	return nil
+}
+
+// ReportTimeByTask возвращает оценку и затраченное время по задачам проекта, у которых есть оценка или записи о времени
+func (s *Storage) ReportTimeByTask() ([]model.TaskTimeStats, error) {
+	return retryValue(s, func() ([]model.TaskTimeStats, error) {
+		return collect(s, func(rows pgx.Rows) (model.TaskTimeStats, error) {
+			var r model.TaskTimeStats
+			var estimate, spent int64
+			err := rows.Scan(&r.TaskID, &r.Title, &r.AssignedID, &estimate, &spent)
+			r.Estimate = time.Duration(estimate) * time.Second
+			r.Spent = time.Duration(spent) * time.Second
+			return r, err
+		}, `SELECT tasks.id, tasks.title, tasks.assigned_id, tasks.estimate_s, COALESCE(sum(worklogs.duration_s), 0)::bigint
+			FROM tasks LEFT JOIN worklogs ON worklogs.task_id = tasks.id
+			WHERE tasks.project_id = $1
+			GROUP BY tasks.id
+			HAVING tasks.estimate_s > 0 OR count(worklogs.id) > 0
+			ORDER BY tasks.id ASC;`, s.project)
+	})
+}
+
+// ReportTimeByUser возвращает по пользователям проекта суммарную оценку задач, где они исполнители,
+// и время, затраченное ими на задачи проекта
+func (s *Storage) ReportTimeByUser() ([]model.UserTimeStats, error) {
+	return retryValue(s, func() ([]model.UserTimeStats, error) {
+		return collect(s, func(rows pgx.Rows) (model.UserTimeStats, error) {
+			var r model.UserTimeStats
+			var estimate, spent int64
+			err := rows.Scan(&r.UserID, &r.Name, &estimate, &spent)
+			r.Estimate = time.Duration(estimate) * time.Second
+			r.Spent = time.Duration(spent) * time.Second
+			return r, err
+		}, `WITH estimates AS (
+				SELECT assigned_id AS user_id, sum(estimate_s) AS estimate_s
+				FROM tasks WHERE project_id = $1
+				GROUP BY assigned_id
+			), spent AS (
+				SELECT worklogs.user_id, sum(worklogs.duration_s) AS duration_s
+				FROM worklogs JOIN tasks ON tasks.id = worklogs.task_id
+				WHERE tasks.project_id = $1
+				GROUP BY worklogs.user_id
+			)
+			SELECT users.id, users.name, COALESCE(estimates.estimate_s, 0)::bigint, COALESCE(spent.duration_s, 0)::bigint
+			FROM users
+				LEFT JOIN estimates ON estimates.user_id = users.id
+				LEFT JOIN spent ON spent.user_id = users.id
+			WHERE estimates.estimate_s > 0 OR spent.duration_s > 0
+			ORDER BY users.id ASC;`, s.project)
+	})
+}
+
+// ReportTimesheet возвращает записи о времени по задачам проекта, начатые в интервале [q.From, q.To),
+// с именами пользователей и заголовками задач, отсортированные по началу работы
+// Если q.UserID не равен 0, то возвращаются только записи этого пользователя
+func (s *Storage) ReportTimesheet(q model.TimesheetQuery) ([]model.TimesheetEntry, error) {
+	return retryValue(s, func() ([]model.TimesheetEntry, error) {
+		return collect(s, func(rows pgx.Rows) (model.TimesheetEntry, error) {
+			var e model.TimesheetEntry
+			var err error
+			e.Worklog, err = scanWorklog(rows, &e.UserName, &e.TaskTitle)
+			return e, err
+		}, "SELECT "+worklogColumns+`, users.name, tasks.title
+			FROM worklogs
+				JOIN tasks ON tasks.id = worklogs.task_id
+				JOIN users ON users.id = worklogs.user_id
+			WHERE tasks.project_id = $1 AND worklogs.started >= $2 AND worklogs.started < $3
+				AND ($4 = 0 OR worklogs.user_id = $4)
+			ORDER BY worklogs.started, worklogs.id;`, s.project, q.From, q.To, q.UserID)
+	})
+}
+
+// scanWorklog считывает строку со столбцами worklogColumns в запись о времени
+// Значения дополнительных столбцов после worklogColumns записываются в extra
+func scanWorklog(row pgx.Row, extra ...any) (model.Worklog, error) {
+	var w model.Worklog
+	var duration int64
+	err := row.Scan(append([]any{&w.ID, &w.TaskID, &w.UserID, &w.Started, &duration, &w.Comment}, extra...)...)
+	w.Duration = time.Duration(duration) * time.Second
+	return w, err
+}
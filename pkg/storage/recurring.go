@@ -0,0 +1,7 @@
+package storage
+
+import "errors"
+
+// RecurringLockedErr - шаблон повторяющейся задачи обрабатывается другим экземпляром планировщика
+// Возвращается из RunRecurringTask, если шаблон уже захвачен
+var RecurringLockedErr = errors.New("Шаблон повторяющейся задачи обрабатывается другим экземпляром планировщика")
@@ -0,0 +1,269 @@
+// Пакет storagetest содержит хранилище в памяти для тестов оберток и API поверх storage.Interface
+package storagetest
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/myerrors"
+	"DB_Apps/pkg/storage"
+	"bytes"
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Fake - хранилище в памяти, которое запоминает вызовы методов
+// Реализованы только методы, нужные тестам, остальные вызывают панику
+// Копии, полученные через WithContext и WithProject, работают с общими данными
+type Fake struct {
+	storage.Interface
+	*Data
+	ctx     context.Context
+	project int
+}
+
+// Data - общие данные хранилища Fake
+type Data struct {
+	mu     sync.Mutex
+	Users  map[int]model.User
+	Tasks  []model.Task
+	Tokens []model.Token
+	// Шаблоны повторяющихся задач всех проектов
+	Recurring []model.RecurringTask
+	// ID шаблонов, захваченных другим экземпляром планировщика
+	Locked map[int]bool
+	// Ошибка, которую возвращают методы задач; итераторы отдают ее после задач
+	Err error
+	// Вызовы методов по порядку
+	Calls []Call
+}
+
+// Call - вызов метода Fake
+type Call struct {
+	Method string
+	// Контекст и проект хранилища, через которое вызван метод
+	Ctx     context.Context
+	Project int
+}
+
+// New создает пустое хранилище
+func New() *Fake {
+	return &Fake{Data: &Data{Users: make(map[int]model.User), Locked: make(map[int]bool)}, ctx: context.Background()}
+}
+
+func (f *Fake) call(method string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, Call{Method: method, Ctx: f.ctx, Project: f.project})
+}
+
+// Methods возвращает имена вызванных методов по порядку
+func (d *Data) Methods() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	methods := make([]string, len(d.Calls))
+	for i, c := range d.Calls {
+		methods[i] = c.Method
+	}
+	return methods
+}
+
+func (f *Fake) WithContext(ctx context.Context) storage.Interface {
+	c := *f
+	c.ctx = ctx
+	return &c
+}
+
+func (f *Fake) WithProject(id int) storage.Interface {
+	c := *f
+	c.project = id
+	return &c
+}
+
+func (f *Fake) ProjectID() int {
+	return f.project
+}
+
+// WithTx выполняет fn с тем же хранилищем, транзакции не поддерживаются
+func (f *Fake) WithTx(fn func(storage.Interface) error) error {
+	f.call("WithTx")
+	return fn(f)
+}
+
+// IsProjectMember считает любого пользователя участником любого проекта
+func (f *Fake) IsProjectMember(projectID, userID int) (bool, error) {
+	f.call("IsProjectMember")
+	return true, nil
+}
+
+func (f *Fake) SelectUserByID(id int) (model.User, error) {
+	f.call("SelectUserByID")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.Users[id]
+	if !ok {
+		return model.User{}, myerrors.NotFound("Пользователь с ID %d не найден", id)
+	}
+	return user, nil
+}
+
+func (f *Fake) NewToken(t model.Token) (int, error) {
+	f.call("NewToken")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t.ID = len(f.Tokens) + 1
+	f.Tokens = append(f.Tokens, t)
+	return t.ID, nil
+}
+
+func (f *Fake) SelectTokenByHash(hash []byte) (model.Token, error) {
+	f.call("SelectTokenByHash")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, t := range f.Tokens {
+		if bytes.Equal(t.Hash, hash) {
+			return t, nil
+		}
+	}
+	return model.Token{}, myerrors.NotFound("Токен не найден")
+}
+
+func (f *Fake) NewTask(task model.Task) (int, error) {
+	f.call("NewTask")
+	if f.Err != nil {
+		return 0, f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	task.ID = len(f.Tasks) + 1
+	task.ProjectID = f.project
+	f.Tasks = append(f.Tasks, task)
+	return task.ID, nil
+}
+
+func (f *Fake) SelectTaskByID(id int) (model.Task, error) {
+	f.call("SelectTaskByID")
+	if f.Err != nil {
+		return model.Task{}, f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, t := range f.Tasks {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return model.Task{}, myerrors.NotFound("Задача с ID %d не найдена", id)
+}
+
+func (f *Fake) DeleteTask(id int) error {
+	f.call("DeleteTask")
+	if f.Err != nil {
+		return f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, t := range f.Tasks {
+		if t.ID == id {
+			f.Tasks = slices.Delete(f.Tasks, i, i+1)
+			return nil
+		}
+	}
+	return myerrors.NotFound("Задача с ID %d не найдена", id)
+}
+
+// iter возвращает итератор задач, для которых match возвращает true
+func (f *Fake) iter(method string, match func(model.Task) bool) iter.Seq2[model.Task, error] {
+	return func(yield func(model.Task, error) bool) {
+		f.call(method)
+		f.mu.Lock()
+		tasks := slices.Clone(f.Tasks)
+		f.mu.Unlock()
+		for _, t := range tasks {
+			if match(t) && !yield(t, nil) {
+				return
+			}
+		}
+		if f.Err != nil {
+			yield(model.Task{}, f.Err)
+		}
+	}
+}
+
+func (f *Fake) IterTasks() iter.Seq2[model.Task, error] {
+	return f.iter("IterTasks", func(model.Task) bool { return true })
+}
+
+func (f *Fake) IterTasksByAuthorID(authorID int) iter.Seq2[model.Task, error] {
+	return f.iter("IterTasksByAuthorID", func(t model.Task) bool { return t.AuthorID == authorID })
+}
+
+func (f *Fake) IterTasksByLabelID(labelID int) iter.Seq2[model.Task, error] {
+	return f.iter("IterTasksByLabelID", func(t model.Task) bool { return slices.Contains(t.LabelsID, labelID) })
+}
+
+func (f *Fake) NewRecurringTask(rt model.RecurringTask) (int, error) {
+	f.call("NewRecurringTask")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rt.ID = len(f.Recurring) + 1
+	rt.ProjectID = f.project
+	rt.Active = true
+	f.Recurring = append(f.Recurring, rt)
+	return rt.ID, nil
+}
+
+// SelectDueRecurringTasks возвращает активные шаблоны всех проектов с запуском не позже now
+func (f *Fake) SelectDueRecurringTasks(now time.Time, limit int) ([]model.RecurringTask, error) {
+	f.call("SelectDueRecurringTasks")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var due []model.RecurringTask
+	for _, rt := range f.Recurring {
+		if rt.Active && !rt.NextRun.After(now) {
+			due = append(due, rt)
+		}
+	}
+	slices.SortStableFunc(due, func(a, b model.RecurringTask) int { return a.NextRun.Compare(b.NextRun) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// RunRecurringTask создает задачу по шаблону так же, как PostgreSQL-хранилище:
+// захваченный шаблон - storage.RecurringLockedErr, уже выполненный запуск rt.NextRun,
+// удаленный или приостановленный шаблон - 0 без создания задачи
+func (f *Fake) RunRecurringTask(rt model.RecurringTask, next time.Time) (int, error) {
+	f.call("RunRecurringTask")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Locked[rt.ID] {
+		return 0, fmt.Errorf("%w: ID %d", storage.RecurringLockedErr, rt.ID)
+	}
+	i := slices.IndexFunc(f.Recurring, func(c model.RecurringTask) bool { return c.ID == rt.ID })
+	if i < 0 {
+		return 0, nil
+	}
+	current := &f.Recurring[i]
+	if !current.Active || !current.NextRun.Equal(rt.NextRun) {
+		return 0, nil
+	}
+	task := model.Task{
+		ID:         len(f.Tasks) + 1,
+		ProjectID:  current.ProjectID,
+		AuthorID:   current.AuthorID,
+		AssignedID: current.AssignedID,
+		Title:      current.Title,
+		Content:    current.Content,
+		LabelsID:   current.LabelsID,
+		Opened:     current.NextRun,
+	}
+	f.Tasks = append(f.Tasks, task)
+	lastRun := current.NextRun
+	current.LastRun = &lastRun
+	current.NextRun = next
+	return task.ID, nil
+}
@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer создает span для каждого SQL-запроса
+// Подключается к pgx через postgresql.Options.QueryHooks
+// pgx сообщает о запросе после его завершения, поэтому начало span вычисляется по времени выполнения
+// Родительский span берется из контекста запроса (см. storage.Interface.WithContext)
+type QueryTracer struct {
+	tracer trace.Tracer
+}
+
+// NewQueryTracer создает обработчик запросов, использующий tp
+func NewQueryTracer(tp trace.TracerProvider) *QueryTracer {
+	return &QueryTracer{tracer: tp.Tracer(instrumentationName)}
+}
+
+func (t *QueryTracer) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	sql, ok := data["sql"].(string)
+	if !ok {
+		return
+	}
+	end := time.Now()
+	start := end
+	if d, ok := data["time"].(time.Duration); ok {
+		start = end.Add(-d)
+	}
+
+	statement := SanitizeSQL(sql)
+	operation := strings.ToUpper(strings.SplitN(statement, " ", 2)[0])
+	_, span := t.tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", operation),
+			attribute.String("db.query.text", statement),
+		),
+	)
+	defer span.End(trace.WithTimestamp(end))
+
+	if n, ok := data["rowCount"].(int); ok {
+		span.SetAttributes(attribute.Int("db.response.returned_rows", n))
+	}
+	if tag, ok := data["commandTag"].(pgconn.CommandTag); ok {
+		span.SetAttributes(attribute.Int64("db.rows_affected", tag.RowsAffected()))
+	}
+	if err, ok := data["err"].(error); ok && err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			span.SetAttributes(attribute.String("db.response.status_code", pgErr.Code))
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+var (
+	spaceRe   = regexp.MustCompile(`\s+`)
+	stringRe  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numericRe = regexp.MustCompile(`([^\w$.])-?\d+(?:\.\d+)?\b`)
+)
+
+// SanitizeSQL приводит текст запроса к виду для span:
+// - сводит пробелы и переводы строк к одному пробелу
+// - заменяет строковые и числовые литералы на ?, чтобы в трассировку не попадали данные
+// Параметры запроса ($1, $2, ...) не изменяются, их значения в span не записываются
+func SanitizeSQL(sql string) string {
+	sql = stringRe.ReplaceAllString(sql, "?")
+	sql = numericRe.ReplaceAllString(sql, "$1?")
+	return strings.TrimSpace(spaceRe.ReplaceAllString(sql, " "))
+}
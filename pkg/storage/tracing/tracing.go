@@ -0,0 +1,757 @@
+// Пакет tracing содержит трассировку OpenTelemetry для хранилища:
+// обертку над storage.Interface (span на каждый метод) и обработчик pgx (span на каждый SQL-запрос)
+package tracing
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage"
+	"context"
+	"io"
+	"iter"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "DB_Apps/pkg/storage"
+
+// Storage - обертка над storage.Interface, которая создает span для каждого метода
+// Span дочерний к span из контекста, заданного через WithContext,
+// а запросы next выполняются с контекстом этого span
+type Storage struct {
+	next   storage.Interface
+	tracer trace.Tracer
+	ctx    context.Context
+}
+
+// New создает обертку над next, использующую tp
+func New(next storage.Interface, tp trace.TracerProvider) *Storage {
+	return &Storage{
+		next:   next,
+		tracer: tp.Tracer(instrumentationName),
+		ctx:    context.Background(),
+	}
+}
+
+// start открывает span метода method как дочерний к span из контекста хранилища
+// Запросы next нужно выполнять с возвращенным контекстом
+func (s *Storage) start(method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.Int("storage.project.id", s.next.ProjectID()))
+	return s.tracer.Start(s.ctx, "storage."+method, trace.WithAttributes(attrs...))
+}
+
+// finish записывает в span ошибку метода, если она есть, и закрывает его
+func finish(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
+// traceIter оборачивает итератор: span охватывает весь проход, включая досрочный выход,
+// и содержит количество полученных строк
+func traceIter[T any](s *Storage, method string, seq func(storage.Interface) iter.Seq2[T, error],
+	attrs ...attribute.KeyValue) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		ctx, span := s.start(method, attrs...)
+		var err error
+		rows := 0
+		defer finish(span, &err)
+		defer func() {
+			span.SetAttributes(attribute.Int("db.response.returned_rows", rows))
+		}()
+		for v, e := range seq(s.next.WithContext(ctx)) {
+			if e != nil {
+				err = e
+			} else {
+				rows++
+			}
+			if !yield(v, e) {
+				return
+			}
+		}
+	}
+}
+
+func userID(id int) attribute.KeyValue         { return attribute.Int("user.id", id) }
+func labelID(id int) attribute.KeyValue        { return attribute.Int("label.id", id) }
+func taskID(id int) attribute.KeyValue         { return attribute.Int("task.id", id) }
+func projectID(id int) attribute.KeyValue      { return attribute.Int("project.id", id) }
+func recurringID(id int) attribute.KeyValue    { return attribute.Int("recurring_task.id", id) }
+func templateID(id int) attribute.KeyValue     { return attribute.Int("task_template.id", id) }
+func viewID(id int) attribute.KeyValue         { return attribute.Int("saved_view.id", id) }
+func boardID(id int) attribute.KeyValue        { return attribute.Int("board.id", id) }
+func worklogID(id int) attribute.KeyValue      { return attribute.Int("worklog.id", id) }
+func attachmentID(id int) attribute.KeyValue   { return attribute.Int("attachment.id", id) }
+func notificationID(id int) attribute.KeyValue { return attribute.Int("notification.id", id) }
+
+func (s *Storage) NewUser(user model.User) (id int, err error) {
+	ctx, span := s.start("NewUser")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).NewUser(user)
+}
+
+func (s *Storage) DeleteUser(id int, opts storage.DeleteUserOptions) (r storage.DeleteUserReport, err error) {
+	ctx, span := s.start("DeleteUser", userID(id), attribute.String("user.delete.strategy", string(opts.Strategy)))
+	defer finish(span, &err)
+	defer func() {
+		span.SetAttributes(attribute.Int("user.delete.authored_tasks", r.Authored),
+			attribute.Int("user.delete.assigned_tasks", r.Assigned))
+	}()
+	return s.next.WithContext(ctx).DeleteUser(id, opts)
+}
+
+func (s *Storage) UpdateUserName(id int, name string) (err error) {
+	ctx, span := s.start("UpdateUserName", userID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).UpdateUserName(id, name)
+}
+
+func (s *Storage) UpdateUserProfile(user model.User) (err error) {
+	ctx, span := s.start("UpdateUserProfile", userID(user.ID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).UpdateUserProfile(user)
+}
+
+func (s *Storage) SetUserRole(id int, role model.Role) (err error) {
+	ctx, span := s.start("SetUserRole", userID(id), attribute.String("user.role", string(role)))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SetUserRole(id, role)
+}
+
+func (s *Storage) SetUserActive(id int, active bool) (err error) {
+	ctx, span := s.start("SetUserActive", userID(id), attribute.Bool("user.active", active))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SetUserActive(id, active)
+}
+
+func (s *Storage) SelectUsers() (users []model.User, err error) {
+	ctx, span := s.start("SelectUsers")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectUsers()
+}
+
+func (s *Storage) SelectUserByID(id int) (user model.User, err error) {
+	ctx, span := s.start("SelectUserByID", userID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectUserByID(id)
+}
+
+func (s *Storage) SelectUserByLogin(login string) (user model.User, err error) {
+	ctx, span := s.start("SelectUserByLogin")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectUserByLogin(login)
+}
+
+func (s *Storage) SelectUserByEmail(email string) (user model.User, err error) {
+	ctx, span := s.start("SelectUserByEmail")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectUserByEmail(email)
+}
+
+func (s *Storage) SelectUsersByIDs(ids []int) (users []model.User, err error) {
+	ctx, span := s.start("SelectUsersByIDs", attribute.IntSlice("user.ids", ids))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectUsersByIDs(ids)
+}
+
+func (s *Storage) IterUsers() iter.Seq2[model.User, error] {
+	return traceIter(s, "IterUsers", storage.Interface.IterUsers)
+}
+
+func (s *Storage) SetUserPassword(id int, hash string) (err error) {
+	ctx, span := s.start("SetUserPassword", userID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SetUserPassword(id, hash)
+}
+
+func (s *Storage) SelectUserPassword(id int) (hash string, err error) {
+	ctx, span := s.start("SelectUserPassword", userID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectUserPassword(id)
+}
+
+func (s *Storage) NewToken(t model.Token) (id int, err error) {
+	ctx, span := s.start("NewToken", userID(t.UserID), attribute.String("token.kind", t.Kind))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).NewToken(t)
+}
+
+func (s *Storage) SelectTokenByHash(hash []byte) (t model.Token, err error) {
+	ctx, span := s.start("SelectTokenByHash")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectTokenByHash(hash)
+}
+
+func (s *Storage) RevokeToken(id int) (err error) {
+	ctx, span := s.start("RevokeToken", attribute.Int("token.id", id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).RevokeToken(id)
+}
+
+func (s *Storage) RevokeUserTokens(id int) (n int, err error) {
+	ctx, span := s.start("RevokeUserTokens", userID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).RevokeUserTokens(id)
+}
+
+func (s *Storage) NewProject(p model.Project) (id int, err error) {
+	ctx, span := s.start("NewProject")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).NewProject(p)
+}
+
+func (s *Storage) DeleteProject(id int) (err error) {
+	ctx, span := s.start("DeleteProject", projectID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).DeleteProject(id)
+}
+
+func (s *Storage) SelectProjects() (projects []model.Project, err error) {
+	ctx, span := s.start("SelectProjects")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectProjects()
+}
+
+func (s *Storage) SelectProjectByID(id int) (p model.Project, err error) {
+	ctx, span := s.start("SelectProjectByID", projectID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectProjectByID(id)
+}
+
+func (s *Storage) SelectUserProjects(id int) (projects []model.Project, err error) {
+	ctx, span := s.start("SelectUserProjects", userID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectUserProjects(id)
+}
+
+func (s *Storage) AddProjectMember(project, user int) (err error) {
+	ctx, span := s.start("AddProjectMember", projectID(project), userID(user))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).AddProjectMember(project, user)
+}
+
+func (s *Storage) DeleteProjectMember(project, user int) (err error) {
+	ctx, span := s.start("DeleteProjectMember", projectID(project), userID(user))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).DeleteProjectMember(project, user)
+}
+
+func (s *Storage) SelectProjectMembers(project int) (users []model.User, err error) {
+	ctx, span := s.start("SelectProjectMembers", projectID(project))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectProjectMembers(project)
+}
+
+func (s *Storage) IsProjectMember(project, user int) (ok bool, err error) {
+	ctx, span := s.start("IsProjectMember", projectID(project), userID(user))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).IsProjectMember(project, user)
+}
+
+func (s *Storage) NewLabel(label model.Label) (id int, err error) {
+	ctx, span := s.start("NewLabel")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).NewLabel(label)
+}
+
+func (s *Storage) DeleteLabel(id int) (err error) {
+	ctx, span := s.start("DeleteLabel", labelID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).DeleteLabel(id)
+}
+
+func (s *Storage) UpdateLabelName(id int, name string) (err error) {
+	ctx, span := s.start("UpdateLabelName", labelID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).UpdateLabelName(id, name)
+}
+
+func (s *Storage) SelectLabels() (labels []model.Label, err error) {
+	ctx, span := s.start("SelectLabels")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectLabels()
+}
+
+func (s *Storage) SelectLabelByID(id int) (label model.Label, err error) {
+	ctx, span := s.start("SelectLabelByID", labelID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectLabelByID(id)
+}
+
+func (s *Storage) SelectLabelsByIDs(ids []int) (labels []model.Label, err error) {
+	ctx, span := s.start("SelectLabelsByIDs", attribute.IntSlice("label.ids", ids))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectLabelsByIDs(ids)
+}
+
+func (s *Storage) IterLabels() iter.Seq2[model.Label, error] {
+	return traceIter(s, "IterLabels", storage.Interface.IterLabels)
+}
+
+func (s *Storage) NewTask(task model.Task) (id int, err error) {
+	ctx, span := s.start("NewTask", attribute.IntSlice("label.ids", task.LabelsID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).NewTask(task)
+}
+
+func (s *Storage) SelectTasks() (tasks []model.Task, err error) {
+	ctx, span := s.start("SelectTasks")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectTasks()
+}
+
+func (s *Storage) SelectTaskByID(id int) (task model.Task, err error) {
+	ctx, span := s.start("SelectTaskByID", taskID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectTaskByID(id)
+}
+
+func (s *Storage) SelectTasksByAuthorID(authorID int) (tasks []model.Task, err error) {
+	ctx, span := s.start("SelectTasksByAuthorID", userID(authorID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectTasksByAuthorID(authorID)
+}
+
+func (s *Storage) SelectTasksByLabelID(id int) (tasks []model.Task, err error) {
+	ctx, span := s.start("SelectTasksByLabelID", labelID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectTasksByLabelID(id)
+}
+
+func (s *Storage) SelectTasksPage(q model.TaskPageQuery) (tasks []model.Task, err error) {
+	ctx, span := s.start("SelectTasksPage", attribute.Int("task.page.after_id", q.AfterID),
+		attribute.Int("task.page.limit", q.Limit))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectTasksPage(q)
+}
+
+func (s *Storage) SelectTaskLabelIDs(ids []int) (labels map[int][]int, err error) {
+	ctx, span := s.start("SelectTaskLabelIDs", attribute.IntSlice("task.ids", ids))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectTaskLabelIDs(ids)
+}
+
+func (s *Storage) IterTasks() iter.Seq2[model.Task, error] {
+	return traceIter(s, "IterTasks", storage.Interface.IterTasks)
+}
+
+func (s *Storage) IterTasksByAuthorID(authorID int) iter.Seq2[model.Task, error] {
+	return traceIter(s, "IterTasksByAuthorID", func(next storage.Interface) iter.Seq2[model.Task, error] {
+		return next.IterTasksByAuthorID(authorID)
+	}, userID(authorID))
+}
+
+func (s *Storage) IterTasksByLabelID(id int) iter.Seq2[model.Task, error] {
+	return traceIter(s, "IterTasksByLabelID", func(next storage.Interface) iter.Seq2[model.Task, error] {
+		return next.IterTasksByLabelID(id)
+	}, labelID(id))
+}
+
+func (s *Storage) DeleteTask(id int) (err error) {
+	ctx, span := s.start("DeleteTask", taskID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).DeleteTask(id)
+}
+
+func (s *Storage) UpdateTaskByID(task model.Task) (err error) {
+	ctx, span := s.start("UpdateTaskByID", taskID(task.ID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).UpdateTaskByID(task)
+}
+
+func (s *Storage) CloseTask(id int) (err error) {
+	ctx, span := s.start("CloseTask", taskID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).CloseTask(id)
+}
+
+func (s *Storage) AddLabelToTask(lID, tID int) (err error) {
+	ctx, span := s.start("AddLabelToTask", labelID(lID), taskID(tID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).AddLabelToTask(lID, tID)
+}
+
+func (s *Storage) DeleteLabelToTask(lID, tID int) (err error) {
+	ctx, span := s.start("DeleteLabelToTask", labelID(lID), taskID(tID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).DeleteLabelToTask(lID, tID)
+}
+
+func (s *Storage) NewSavedView(v model.SavedView) (id int, err error) {
+	ctx, span := s.start("NewSavedView", userID(v.OwnerID), attribute.Bool("saved_view.shared", v.Shared))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).NewSavedView(v)
+}
+
+func (s *Storage) UpdateSavedView(v model.SavedView) (err error) {
+	ctx, span := s.start("UpdateSavedView", viewID(v.ID), attribute.Bool("saved_view.shared", v.Shared))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).UpdateSavedView(v)
+}
+
+func (s *Storage) DeleteSavedView(id int) (err error) {
+	ctx, span := s.start("DeleteSavedView", viewID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).DeleteSavedView(id)
+}
+
+func (s *Storage) SelectSavedViews(ownerID int) (views []model.SavedView, err error) {
+	ctx, span := s.start("SelectSavedViews", userID(ownerID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectSavedViews(ownerID)
+}
+
+func (s *Storage) SelectSavedViewByID(id int) (v model.SavedView, err error) {
+	ctx, span := s.start("SelectSavedViewByID", viewID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectSavedViewByID(id)
+}
+
+func (s *Storage) SelectTasksByFilter(f model.TaskFilter) (tasks []model.Task, err error) {
+	ctx, span := s.start("SelectTasksByFilter", attribute.String("task.filter.state", string(f.State)),
+		attribute.String("task.filter.sort", string(f.Sort)))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectTasksByFilter(f)
+}
+
+func (s *Storage) ExecuteSavedView(id int) (tasks []model.Task, err error) {
+	ctx, span := s.start("ExecuteSavedView", viewID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).ExecuteSavedView(id)
+}
+
+func (s *Storage) NewBoard(b model.Board) (id int, err error) {
+	ctx, span := s.start("NewBoard", attribute.String("board.kind", string(b.Kind)))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).NewBoard(b)
+}
+
+func (s *Storage) UpdateBoard(b model.Board) (err error) {
+	ctx, span := s.start("UpdateBoard", boardID(b.ID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).UpdateBoard(b)
+}
+
+func (s *Storage) DeleteBoard(id int) (err error) {
+	ctx, span := s.start("DeleteBoard", boardID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).DeleteBoard(id)
+}
+
+func (s *Storage) SelectBoards() (boards []model.Board, err error) {
+	ctx, span := s.start("SelectBoards")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectBoards()
+}
+
+func (s *Storage) SelectBoardByID(id int) (b model.Board, err error) {
+	ctx, span := s.start("SelectBoardByID", boardID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectBoardByID(id)
+}
+
+func (s *Storage) SelectBoardCards(bID int) (cards []model.BoardCard, err error) {
+	ctx, span := s.start("SelectBoardCards", boardID(bID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectBoardCards(bID)
+}
+
+func (s *Storage) MoveBoardTask(m model.BoardMove) (err error) {
+	ctx, span := s.start("MoveBoardTask", boardID(m.BoardID), taskID(m.TaskID),
+		attribute.Int("board.column.id", m.ColumnID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).MoveBoardTask(m)
+}
+
+func (s *Storage) NewWorklog(w model.Worklog) (id int, err error) {
+	ctx, span := s.start("NewWorklog", taskID(w.TaskID), userID(w.UserID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).NewWorklog(w)
+}
+
+func (s *Storage) DeleteWorklog(id int) (err error) {
+	ctx, span := s.start("DeleteWorklog", worklogID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).DeleteWorklog(id)
+}
+
+func (s *Storage) SelectWorklogByID(id int) (w model.Worklog, err error) {
+	ctx, span := s.start("SelectWorklogByID", worklogID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectWorklogByID(id)
+}
+
+func (s *Storage) SelectWorklogs(tID int) (worklogs []model.Worklog, err error) {
+	ctx, span := s.start("SelectWorklogs", taskID(tID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectWorklogs(tID)
+}
+
+func (s *Storage) SetTaskEstimate(tID int, estimate time.Duration) (err error) {
+	ctx, span := s.start("SetTaskEstimate", taskID(tID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SetTaskEstimate(tID, estimate)
+}
+
+func (s *Storage) NewAttachment(a model.Attachment, content io.Reader) (id int, err error) {
+	ctx, span := s.start("NewAttachment", taskID(a.TaskID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).NewAttachment(a, content)
+}
+
+func (s *Storage) DeleteAttachment(id int) (err error) {
+	ctx, span := s.start("DeleteAttachment", attachmentID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).DeleteAttachment(id)
+}
+
+func (s *Storage) SelectAttachmentByID(id int) (a model.Attachment, err error) {
+	ctx, span := s.start("SelectAttachmentByID", attachmentID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectAttachmentByID(id)
+}
+
+func (s *Storage) SelectAttachments(id int) (a []model.Attachment, err error) {
+	ctx, span := s.start("SelectAttachments", taskID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectAttachments(id)
+}
+
+// OpenAttachment завершает span после открытия, чтение содержимого в него не входит
+func (s *Storage) OpenAttachment(id int) (a model.Attachment, content io.ReadCloser, err error) {
+	ctx, span := s.start("OpenAttachment", attachmentID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).OpenAttachment(id)
+}
+
+func (s *Storage) WatchTask(id, user int) (err error) {
+	ctx, span := s.start("WatchTask", taskID(id), userID(user))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).WatchTask(id, user)
+}
+
+func (s *Storage) UnwatchTask(id, user int) (err error) {
+	ctx, span := s.start("UnwatchTask", taskID(id), userID(user))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).UnwatchTask(id, user)
+}
+
+func (s *Storage) SelectTaskWatchers(id int) (users []model.User, err error) {
+	ctx, span := s.start("SelectTaskWatchers", taskID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectTaskWatchers(id)
+}
+
+func (s *Storage) SelectNotifications(q model.NotificationQuery) (n []model.Notification, err error) {
+	ctx, span := s.start("SelectNotifications", userID(q.UserID), attribute.Bool("notification.unread_only", q.UnreadOnly))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectNotifications(q)
+}
+
+func (s *Storage) MarkNotificationRead(user, id int) (err error) {
+	ctx, span := s.start("MarkNotificationRead", userID(user), notificationID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).MarkNotificationRead(user, id)
+}
+
+func (s *Storage) MarkAllNotificationsRead(user int) (n int, err error) {
+	ctx, span := s.start("MarkAllNotificationsRead", userID(user))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).MarkAllNotificationsRead(user)
+}
+
+func (s *Storage) NewTaskTemplate(t model.TaskTemplate) (id int, err error) {
+	ctx, span := s.start("NewTaskTemplate", attribute.IntSlice("label.ids", t.LabelsID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).NewTaskTemplate(t)
+}
+
+func (s *Storage) UpdateTaskTemplate(t model.TaskTemplate) (err error) {
+	ctx, span := s.start("UpdateTaskTemplate", templateID(t.ID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).UpdateTaskTemplate(t)
+}
+
+func (s *Storage) DeleteTaskTemplate(id int) (err error) {
+	ctx, span := s.start("DeleteTaskTemplate", templateID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).DeleteTaskTemplate(id)
+}
+
+func (s *Storage) SelectTaskTemplates() (templates []model.TaskTemplate, err error) {
+	ctx, span := s.start("SelectTaskTemplates")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectTaskTemplates()
+}
+
+func (s *Storage) SelectTaskTemplateByID(id int) (t model.TaskTemplate, err error) {
+	ctx, span := s.start("SelectTaskTemplateByID", templateID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectTaskTemplateByID(id)
+}
+
+func (s *Storage) NewTaskFromTemplate(tID, authorID int, vars map[string]string) (id int, err error) {
+	ctx, span := s.start("NewTaskFromTemplate", templateID(tID), userID(authorID))
+	defer finish(span, &err)
+	id, err = s.next.WithContext(ctx).NewTaskFromTemplate(tID, authorID, vars)
+	if err == nil {
+		span.SetAttributes(taskID(id))
+	}
+	return id, err
+}
+
+func (s *Storage) NewRecurringTask(rt model.RecurringTask) (id int, err error) {
+	ctx, span := s.start("NewRecurringTask", attribute.String("recurring_task.schedule", rt.Schedule))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).NewRecurringTask(rt)
+}
+
+func (s *Storage) SelectRecurringTasks() (rts []model.RecurringTask, err error) {
+	ctx, span := s.start("SelectRecurringTasks")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectRecurringTasks()
+}
+
+func (s *Storage) SelectRecurringTaskByID(id int) (rt model.RecurringTask, err error) {
+	ctx, span := s.start("SelectRecurringTaskByID", recurringID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectRecurringTaskByID(id)
+}
+
+func (s *Storage) SetRecurringTaskActive(id int, active bool) (err error) {
+	ctx, span := s.start("SetRecurringTaskActive", recurringID(id), attribute.Bool("recurring_task.active", active))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SetRecurringTaskActive(id, active)
+}
+
+func (s *Storage) DeleteRecurringTask(id int) (err error) {
+	ctx, span := s.start("DeleteRecurringTask", recurringID(id))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).DeleteRecurringTask(id)
+}
+
+func (s *Storage) SelectDueRecurringTasks(now time.Time, limit int) (rts []model.RecurringTask, err error) {
+	ctx, span := s.start("SelectDueRecurringTasks", attribute.Int("recurring_task.limit", limit))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).SelectDueRecurringTasks(now, limit)
+}
+
+func (s *Storage) RunRecurringTask(rt model.RecurringTask, next time.Time) (id int, err error) {
+	ctx, span := s.start("RunRecurringTask", recurringID(rt.ID), projectID(rt.ProjectID))
+	defer finish(span, &err)
+	id, err = s.next.WithContext(ctx).RunRecurringTask(rt, next)
+	if err == nil {
+		span.SetAttributes(taskID(id))
+	}
+	return id, err
+}
+
+func (s *Storage) ReportByAssignee() (rows []model.AssigneeStats, err error) {
+	ctx, span := s.start("ReportByAssignee")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).ReportByAssignee()
+}
+
+func (s *Storage) ReportByLabel() (rows []model.LabelStats, err error) {
+	ctx, span := s.start("ReportByLabel")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).ReportByLabel()
+}
+
+func (s *Storage) ReportTimeToClose() (stats model.CloseTimeStats, err error) {
+	ctx, span := s.start("ReportTimeToClose")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).ReportTimeToClose()
+}
+
+func (s *Storage) ReportFlow(q model.FlowQuery) (points []model.FlowPoint, err error) {
+	ctx, span := s.start("ReportFlow", attribute.String("report.period", string(q.Period)))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).ReportFlow(q)
+}
+
+func (s *Storage) ReportTimeByTask() (stats []model.TaskTimeStats, err error) {
+	ctx, span := s.start("ReportTimeByTask")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).ReportTimeByTask()
+}
+
+func (s *Storage) ReportTimeByUser() (stats []model.UserTimeStats, err error) {
+	ctx, span := s.start("ReportTimeByUser")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).ReportTimeByUser()
+}
+
+func (s *Storage) ReportTimesheet(q model.TimesheetQuery) (entries []model.TimesheetEntry, err error) {
+	ctx, span := s.start("ReportTimesheet", userID(q.UserID))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).ReportTimesheet(q)
+}
+
+func (s *Storage) ReportOldestOpen(limit int) (tasks []model.Task, err error) {
+	ctx, span := s.start("ReportOldestOpen", attribute.Int("report.limit", limit))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).ReportOldestOpen(limit)
+}
+
+// WithTx создает span на всю транзакцию
+// Операции внутри fn создают дочерние span
+func (s *Storage) WithTx(fn func(storage.Interface) error) (err error) {
+	ctx, span := s.start("WithTx")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).WithTx(s.wrapTx(ctx, fn))
+}
+
+func (s *Storage) WithTxOptions(opts storage.TxOptions, fn func(storage.Interface) error) (err error) {
+	ctx, span := s.start("WithTx", attribute.String("db.isolation_level", string(opts.IsoLevel)))
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).WithTxOptions(opts, s.wrapTx(ctx, fn))
+}
+
+// wrapTx подменяет хранилище транзакции, переданное в fn, на обертку
+// Контекст берется из span транзакции, чтобы операции fn стали его дочерними span
+func (s *Storage) wrapTx(ctx context.Context, fn func(storage.Interface) error) func(storage.Interface) error {
+	return func(tx storage.Interface) error {
+		return fn(&Storage{next: tx.WithContext(ctx), tracer: s.tracer, ctx: ctx})
+	}
+}
+
+func (s *Storage) Ping() (err error) {
+	ctx, span := s.start("Ping")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).Ping()
+}
+
+func (s *Storage) Diagnostics() (d storage.Diagnostics, err error) {
+	ctx, span := s.start("Diagnostics")
+	defer finish(span, &err)
+	return s.next.WithContext(ctx).Diagnostics()
+}
+
+func (s *Storage) WithProject(id int) storage.Interface {
+	c := *s
+	c.next = s.next.WithProject(id)
+	return &c
+}
+
+func (s *Storage) ProjectID() int {
+	return s.next.ProjectID()
+}
+
+func (s *Storage) WithContext(ctx context.Context) storage.Interface {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c := *s
+	c.ctx = ctx
+	c.next = s.next.WithContext(ctx)
+	return &c
+}
+
+func (s *Storage) Close() {
+	s.next.Close()
+}
@@ -0,0 +1,189 @@
+package tracing
+
+import (
+	"DB_Apps/pkg/model"
+	"DB_Apps/pkg/storage"
+	"DB_Apps/pkg/storage/storagetest"
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestStorage(t *testing.T, next storage.Interface) (*Storage, *tracetest.SpanRecorder) {
+	t.Helper()
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	t.Cleanup(func() { tp.Shutdown(context.Background()) })
+	return New(next, tp), sr
+}
+
+// attrs возвращает атрибуты span в виде словаря
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestSpanNameAndAttributes(t *testing.T) {
+	next := storagetest.New()
+	next.Users[42] = model.User{ID: 42}
+	s, sr := newTestStorage(t, next)
+
+	if _, err := s.WithProject(7).SelectUserByID(42); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("span: %d, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "storage.SelectUserByID" {
+		t.Errorf("Name() = %q, want storage.SelectUserByID", span.Name())
+	}
+	a := attrs(span)
+	if got := a["user.id"].AsInt64(); got != 42 {
+		t.Errorf("user.id = %d, want 42", got)
+	}
+	if got := a["storage.project.id"].AsInt64(); got != 7 {
+		t.Errorf("storage.project.id = %d, want 7", got)
+	}
+	if span.Status().Code != codes.Unset {
+		t.Errorf("Status() = %v, want Unset", span.Status())
+	}
+	if span.InstrumentationScope().Name != instrumentationName {
+		t.Errorf("InstrumentationScope().Name = %q, want %q", span.InstrumentationScope().Name, instrumentationName)
+	}
+}
+
+func TestSpanError(t *testing.T) {
+	next := storagetest.New()
+	next.Err = errors.New("Задача не найдена")
+	s, sr := newTestStorage(t, next)
+
+	if err := s.DeleteTask(42); !errors.Is(err, next.Err) {
+		t.Fatalf("DeleteTask() error = %v, want %v", err, next.Err)
+	}
+
+	span := sr.Ended()[0]
+	if span.Status().Code != codes.Error {
+		t.Errorf("Status().Code = %v, want Error", span.Status().Code)
+	}
+	if span.Status().Description != next.Err.Error() {
+		t.Errorf("Status().Description = %q, want %q", span.Status().Description, next.Err.Error())
+	}
+	events := span.Events()
+	if len(events) != 1 || events[0].Name != "exception" {
+		t.Errorf("Events() = %v, want событие exception", events)
+	}
+}
+
+func TestContextPropagation(t *testing.T) {
+	next := storagetest.New()
+	next.Users[1] = model.User{ID: 1}
+	s, sr := newTestStorage(t, next)
+
+	tp := sdktrace.NewTracerProvider()
+	defer tp.Shutdown(context.Background())
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	defer parent.End()
+
+	if _, err := s.WithContext(ctx).SelectUserByID(1); err != nil {
+		t.Fatal(err)
+	}
+
+	span := sr.Ended()[0]
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("Parent().SpanID() = %v, want %v", span.Parent().SpanID(), parent.SpanContext().SpanID())
+	}
+	if span.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("TraceID() = %v, want %v", span.SpanContext().TraceID(), parent.SpanContext().TraceID())
+	}
+	// Запрос к next выполняется с контекстом span метода
+	got := trace.SpanContextFromContext(next.Calls[0].Ctx)
+	if got.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("span в контексте next = %v, want %v", got.SpanID(), span.SpanContext().SpanID())
+	}
+}
+
+func TestWithTxChildSpans(t *testing.T) {
+	next := storagetest.New()
+	next.Users[1] = model.User{ID: 1}
+	s, sr := newTestStorage(t, next)
+
+	err := s.WithTx(func(tx storage.Interface) error {
+		_, err := tx.SelectUserByID(1)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("span: %d, want 2", len(spans))
+	}
+	child, tx := spans[0], spans[1]
+	if tx.Name() != "storage.WithTx" || child.Name() != "storage.SelectUserByID" {
+		t.Fatalf("span: %q, %q, want storage.SelectUserByID, storage.WithTx", child.Name(), tx.Name())
+	}
+	if child.Parent().SpanID() != tx.SpanContext().SpanID() {
+		t.Errorf("операция транзакции не дочерняя к span WithTx")
+	}
+}
+
+func TestIterSpan(t *testing.T) {
+	next := storagetest.New()
+	next.Tasks = []model.Task{{ID: 1}, {ID: 2}, {ID: 3}}
+	s, sr := newTestStorage(t, next)
+
+	for task, err := range s.IterTasks() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.ID == 2 {
+			break
+		}
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("span: %d, want 1 после досрочного выхода", len(spans))
+	}
+	if got := attrs(spans[0])["db.response.returned_rows"].AsInt64(); got != 2 {
+		t.Errorf("db.response.returned_rows = %d, want 2", got)
+	}
+}
+
+func TestIterSpanError(t *testing.T) {
+	next := storagetest.New()
+	next.Tasks = []model.Task{{ID: 1}}
+	next.Err = errors.New("обрыв соединения")
+	s, sr := newTestStorage(t, next)
+
+	var iterErr error
+	for _, err := range s.IterTasks() {
+		if err != nil {
+			iterErr = err
+		}
+	}
+	if iterErr == nil {
+		t.Fatal("IterTasks() не вернул ошибку")
+	}
+
+	span := sr.Ended()[0]
+	if span.Status().Code != codes.Error {
+		t.Errorf("Status().Code = %v, want Error", span.Status().Code)
+	}
+	if got := attrs(span)["db.response.returned_rows"].AsInt64(); got != 1 {
+		t.Errorf("db.response.returned_rows = %d, want 1", got)
+	}
+}
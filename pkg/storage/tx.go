@@ -0,0 +1,19 @@
+package storage
+
+// Уровень изоляции транзакции
+type IsolationLevel string
+
+const (
+	ReadCommitted  IsolationLevel = "read committed"
+	RepeatableRead IsolationLevel = "repeatable read"
+	Serializable   IsolationLevel = "serializable"
+)
+
+// TxOptions задает параметры транзакции для WithTxOptions
+type TxOptions struct {
+	// Уровень изоляции. Пустое значение - уровень по умолчанию сервера (read committed)
+	IsoLevel IsolationLevel
+	// Максимальное число попыток при ошибках сериализации и взаимоблокировках.
+	// 0 - значение из политики повторов хранилища
+	MaxAttempts int
+}
@@ -0,0 +1,31 @@
+package storage
+
+// DeleteStrategy определяет, что происходит с задачами удаляемого пользователя
+type DeleteStrategy string
+
+const (
+	// DeleteReject - удаление отклоняется, если пользователь автор или исполнитель хотя бы одной задачи
+	DeleteReject DeleteStrategy = "reject"
+	// DeleteReassign - задачи пользователя передаются пользователю DeleteUserOptions.ReassignTo
+	DeleteReassign DeleteStrategy = "reassign"
+	// DeleteUnassign - задачи пользователя передаются пользователю по умолчанию (ID 0)
+	DeleteUnassign DeleteStrategy = "unassign"
+)
+
+// DeleteUserOptions задает параметры DeleteUser
+type DeleteUserOptions struct {
+	// Стратегия для задач пользователя. Пустое значение - DeleteReject
+	Strategy DeleteStrategy
+	// Новый автор и исполнитель задач для DeleteReassign
+	ReassignTo int
+}
+
+// DeleteUserReport - количество задач удаляемого пользователя
+// Для DeleteReject - найденные задачи, из-за которых удаление отклонено,
+// для остальных стратегий - переданные другому пользователю
+type DeleteUserReport struct {
+	// Задачи, автором которых был пользователь
+	Authored int
+	// Задачи, исполнителем которых был пользователь
+	Assigned int
+}
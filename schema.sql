@@ -1,19 +1,57 @@
-DROP TABLE IF EXISTS tasks_labels,tasks,labels, users;
+DROP TABLE IF EXISTS notifications, task_watchers, attachment_blobs, blob_purge_queue, attachments, worklogs, board_cards, board_columns, boards, saved_views, task_templates, recurring_runs,
+	recurring_tasks, auth_tokens, tasks_labels,tasks,labels, project_members, projects, users, schema_migrations;
 
 CREATE TABLE users (
 id SERIAL NOT NULL UNIQUE,
 name TEXT NOT NULL,
+login TEXT,
+email TEXT,
+display_name TEXT NOT NULL DEFAULT '',
+active BOOLEAN NOT NULL DEFAULT TRUE,
+password_hash TEXT NOT NULL DEFAULT '',
+role TEXT NOT NULL DEFAULT 'member'
+	CONSTRAINT users_role_check CHECK (role IN ('admin', 'member', 'viewer')),
 PRIMARY KEY(id)
 );
 
+CREATE UNIQUE INDEX users_login_key ON users (lower(login));
+CREATE UNIQUE INDEX users_email_key ON users (lower(email));
+
+CREATE TABLE projects(
+id SERIAL NOT NULL UNIQUE,
+name TEXT NOT NULL,
+description TEXT NOT NULL DEFAULT '',
+
+PRIMARY KEY(id)
+);
+
+CREATE UNIQUE INDEX projects_name_key ON projects (lower(name));
+
+CREATE TABLE project_members(
+project_id INT NOT NULL,
+user_id INT NOT NULL,
+
+PRIMARY KEY(project_id, user_id),
+FOREIGN KEY(project_id)
+	REFERENCES projects(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(user_id)
+	REFERENCES users(id)
+	ON DELETE CASCADE
+);
+
+CREATE INDEX project_members_user_id_idx ON project_members (user_id);
+
 CREATE TABLE tasks(
 id SERIAL NOT NULL UNIQUE,
-opened BIGINT DEFAULT EXTRACT(EPOCH FROM NOW())::BIGINT,
-closed BIGINT DEFAULT 0,
+project_id INT NOT NULL DEFAULT 0 REFERENCES projects(id),
+opened TIMESTAMPTZ NOT NULL DEFAULT now(),
+closed TIMESTAMPTZ,
 author_id INT NOT NULL DEFAULT 0,
 assigned_id INT NOT NULL DEFAULT 0,
 title TEXT NOT NULL DEFAULT '',
 content TEXT NOT NULL DEFAULT '',
+estimate_s INT NOT NULL DEFAULT 0 CHECK (estimate_s >= 0),
 
 PRIMARY KEY(id),
 FOREIGN KEY(author_id)
@@ -26,11 +64,22 @@ FOREIGN KEY(assigned_id)
 
 CREATE TABLE labels(
 id SERIAL NOT NULL UNIQUE,
+project_id INT NOT NULL DEFAULT 0 REFERENCES projects(id),
 name TEXT NOT NULL,
 
 PRIMARY KEY(id)
 );
 
+CREATE INDEX tasks_project_id_idx ON tasks (project_id);
+CREATE INDEX labels_project_id_idx ON labels (project_id);
+
+CREATE POLICY tasks_project ON tasks
+	USING (COALESCE(current_setting('app.project_id', true), '') = ''
+		OR project_id = NULLIF(current_setting('app.project_id', true), '')::int);
+CREATE POLICY labels_project ON labels
+	USING (COALESCE(current_setting('app.project_id', true), '') = ''
+		OR project_id = NULLIF(current_setting('app.project_id', true), '')::int);
+
 CREATE TABLE tasks_labels(
 task_id INT NOT NULL,
 label_id INT NOT NULL,
@@ -43,5 +92,288 @@ FOREIGN KEY(label_id)
 	REFERENCES labels(id)
 );
 
+CREATE TABLE auth_tokens(
+id SERIAL NOT NULL UNIQUE,
+user_id INT NOT NULL,
+kind TEXT NOT NULL,
+name TEXT NOT NULL DEFAULT '',
+token_hash BYTEA NOT NULL UNIQUE,
+created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+expires_at TIMESTAMPTZ,
+revoked_at TIMESTAMPTZ,
+
+PRIMARY KEY(id),
+FOREIGN KEY(user_id)
+	REFERENCES users(id)
+	ON DELETE CASCADE
+);
+
+CREATE INDEX auth_tokens_user_id_idx ON auth_tokens (user_id);
+
+CREATE TABLE recurring_tasks(
+id SERIAL NOT NULL UNIQUE,
+project_id INT NOT NULL DEFAULT 0,
+schedule TEXT NOT NULL,
+author_id INT NOT NULL DEFAULT 0,
+assigned_id INT NOT NULL DEFAULT 0,
+title TEXT NOT NULL,
+content TEXT NOT NULL DEFAULT '',
+label_ids INT[] NOT NULL DEFAULT '{}',
+next_run TIMESTAMPTZ NOT NULL,
+last_run TIMESTAMPTZ,
+active BOOLEAN NOT NULL DEFAULT TRUE,
+
+PRIMARY KEY(id),
+FOREIGN KEY(project_id)
+	REFERENCES projects(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(author_id)
+	REFERENCES users(id)
+	ON DELETE SET DEFAULT,
+FOREIGN KEY(assigned_id)
+	REFERENCES users(id)
+	ON DELETE SET DEFAULT
+);
+
+CREATE INDEX recurring_tasks_next_run_idx ON recurring_tasks (next_run) WHERE active;
+
+CREATE TABLE recurring_runs(
+recurring_id INT NOT NULL,
+run_at TIMESTAMPTZ NOT NULL,
+task_id INT,
+created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+PRIMARY KEY(recurring_id, run_at),
+FOREIGN KEY(recurring_id)
+	REFERENCES recurring_tasks(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(task_id)
+	REFERENCES tasks(id)
+	ON DELETE SET NULL
+);
+
+CREATE TABLE task_templates(
+id SERIAL NOT NULL UNIQUE,
+project_id INT NOT NULL DEFAULT 0,
+name TEXT NOT NULL,
+title_pattern TEXT NOT NULL,
+content TEXT NOT NULL DEFAULT '',
+assigned_id INT NOT NULL DEFAULT 0,
+label_ids INT[] NOT NULL DEFAULT '{}',
+
+PRIMARY KEY(id),
+FOREIGN KEY(project_id)
+	REFERENCES projects(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(assigned_id)
+	REFERENCES users(id)
+	ON DELETE SET DEFAULT
+);
+
+CREATE UNIQUE INDEX task_templates_name_key ON task_templates (project_id, lower(name));
+
+CREATE TABLE saved_views(
+id SERIAL NOT NULL UNIQUE,
+project_id INT NOT NULL DEFAULT 0,
+owner_id INT NOT NULL,
+name TEXT NOT NULL,
+filter JSONB NOT NULL DEFAULT '{}',
+shared BOOLEAN NOT NULL DEFAULT FALSE,
+
+PRIMARY KEY(id),
+FOREIGN KEY(project_id)
+	REFERENCES projects(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(owner_id)
+	REFERENCES users(id)
+	ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX saved_views_name_key ON saved_views (project_id, owner_id, lower(name));
+CREATE INDEX saved_views_shared_idx ON saved_views (project_id) WHERE shared;
+
+CREATE TABLE boards(
+id SERIAL NOT NULL UNIQUE,
+project_id INT NOT NULL DEFAULT 0,
+name TEXT NOT NULL,
+kind TEXT NOT NULL CHECK (kind IN ('status', 'label')),
+
+PRIMARY KEY(id),
+FOREIGN KEY(project_id)
+	REFERENCES projects(id)
+	ON DELETE CASCADE
+);
+
+CREATE TABLE board_columns(
+id SERIAL NOT NULL UNIQUE,
+board_id INT NOT NULL,
+name TEXT NOT NULL,
+position INT NOT NULL,
+status TEXT CHECK (status IN ('open', 'closed')),
+label_id INT,
+
+PRIMARY KEY(id),
+CHECK ((status IS NULL) <> (label_id IS NULL)),
+FOREIGN KEY(board_id)
+	REFERENCES boards(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(label_id)
+	REFERENCES labels(id)
+	ON DELETE CASCADE
+);
+
+CREATE INDEX board_columns_board_idx ON board_columns (board_id, position);
+
+CREATE TABLE board_cards(
+board_id INT NOT NULL,
+task_id INT NOT NULL,
+column_id INT NOT NULL,
+rank TEXT COLLATE "C" NOT NULL,
+
+PRIMARY KEY(board_id, task_id),
+FOREIGN KEY(board_id)
+	REFERENCES boards(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(task_id)
+	REFERENCES tasks(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(column_id)
+	REFERENCES board_columns(id)
+	ON DELETE CASCADE
+);
+
+CREATE INDEX board_cards_column_idx ON board_cards (column_id, rank);
+
+CREATE TABLE worklogs(
+id SERIAL NOT NULL UNIQUE,
+task_id INT NOT NULL,
+user_id INT NOT NULL DEFAULT 0,
+started TIMESTAMPTZ NOT NULL,
+duration_s INT NOT NULL CHECK (duration_s > 0),
+comment TEXT NOT NULL DEFAULT '',
+
+PRIMARY KEY(id),
+FOREIGN KEY(task_id)
+	REFERENCES tasks(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(user_id)
+	REFERENCES users(id)
+	ON DELETE SET DEFAULT
+);
+
+CREATE INDEX worklogs_task_id_idx ON worklogs (task_id);
+CREATE INDEX worklogs_started_idx ON worklogs (started);
+
+CREATE TABLE attachments(
+id SERIAL NOT NULL UNIQUE,
+task_id INT NOT NULL,
+uploader_id INT NOT NULL DEFAULT 0,
+name TEXT NOT NULL CHECK (name <> ''),
+size BIGINT NOT NULL CHECK (size >= 0),
+mime_type TEXT NOT NULL,
+sha256 BYTEA NOT NULL CHECK (length(sha256) = 32),
+blob_key TEXT NOT NULL UNIQUE,
+created TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+PRIMARY KEY(id),
+FOREIGN KEY(task_id)
+	REFERENCES tasks(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(uploader_id)
+	REFERENCES users(id)
+	ON DELETE SET DEFAULT
+);
+
+CREATE INDEX attachments_task_id_idx ON attachments (task_id);
+
+CREATE TABLE blob_purge_queue(
+blob_key TEXT NOT NULL,
+purge_after TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+PRIMARY KEY(blob_key)
+);
+
+CREATE INDEX blob_purge_queue_purge_after_idx ON blob_purge_queue (purge_after);
+
+CREATE OR REPLACE FUNCTION attachments_enqueue_blob() RETURNS trigger AS $$
+BEGIN
+	INSERT INTO blob_purge_queue(blob_key) VALUES (OLD.blob_key)
+	ON CONFLICT (blob_key) DO UPDATE SET purge_after = now();
+	RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attachments_enqueue_blob AFTER DELETE ON attachments
+FOR EACH ROW EXECUTE FUNCTION attachments_enqueue_blob();
+
+CREATE TABLE attachment_blobs(
+blob_key TEXT NOT NULL,
+seq INT NOT NULL CHECK (seq >= 0),
+data BYTEA NOT NULL,
+
+PRIMARY KEY(blob_key, seq)
+);
+
+CREATE TABLE task_watchers(
+task_id INT NOT NULL,
+user_id INT NOT NULL,
+
+PRIMARY KEY(task_id, user_id),
+FOREIGN KEY(task_id)
+	REFERENCES tasks(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(user_id)
+	REFERENCES users(id)
+	ON DELETE CASCADE
+);
+
+CREATE INDEX task_watchers_user_id_idx ON task_watchers (user_id);
+
+CREATE TABLE notifications(
+id SERIAL NOT NULL UNIQUE,
+user_id INT NOT NULL,
+project_id INT NOT NULL,
+task_id INT NOT NULL,
+actor_id INT NOT NULL DEFAULT 0,
+kind TEXT NOT NULL CHECK (kind IN ('assigned', 'mentioned', 'task_changed')),
+task_title TEXT NOT NULL,
+text TEXT NOT NULL,
+created TIMESTAMPTZ NOT NULL DEFAULT now(),
+read_at TIMESTAMPTZ,
+
+PRIMARY KEY(id),
+FOREIGN KEY(user_id)
+	REFERENCES users(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(project_id)
+	REFERENCES projects(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(task_id)
+	REFERENCES tasks(id)
+	ON DELETE CASCADE,
+FOREIGN KEY(actor_id)
+	REFERENCES users(id)
+	ON DELETE SET DEFAULT
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id, id);
+CREATE INDEX notifications_unread_idx ON notifications (user_id, id) WHERE read_at IS NULL;
+CREATE INDEX notifications_task_id_idx ON notifications (task_id);
+
 INSERT INTO users(id, name)
-VALUES (0, 'default');
\ No newline at end of file
+VALUES (0, 'default');
+
+INSERT INTO projects(id, name)
+VALUES (0, 'default');
+
+-- Схема соответствует последней миграции, поэтому Migrate() не применяет к этой БД уже учтенные миграции
+CREATE TABLE schema_migrations(
+version INT NOT NULL PRIMARY KEY,
+name TEXT NOT NULL,
+applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO schema_migrations(version, name)
+VALUES (1, 'init'), (2, 'user_profile'), (3, 'auth'), (4, 'roles'), (5, 'projects'),
+	(6, 'task_timestamps'), (7, 'recurring_tasks'), (8, 'task_templates'), (9, 'saved_views'),
+	(10, 'boards'), (11, 'worklogs'), (12, 'attachments'), (13, 'notifications');